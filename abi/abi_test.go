@@ -70,6 +70,10 @@ func (h *hexCodec) PrivateKeyToAddress([]byte) (string, []byte, error) {
 	return "", nil, errors.New("not implemented")
 }
 
+func (h *hexCodec) PublicKeyToAddress([]byte) (string, []byte, error) {
+	return "", nil, errors.New("not implemented")
+}
+
 func (h *hexCodec) IsValid(s string) bool {
 	_, err := h.DecodeAddressToBytes(s)
 	return err == nil
@@ -346,6 +350,117 @@ func TestErc20CallGetBalance_NilAbi(t *testing.T) {
 	}
 }
 
+func TestErc20CallTransfer_RoundTrip(t *testing.T) {
+	m := newTestManager(t)
+	to := "0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef"
+	amount := new(big.Int).Exp(big.NewInt(10), big.NewInt(20), nil)
+	data, err := m.Erc20CallTransfer(to, amount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 4-byte selector + address slot + amount slot
+	if len(data) != 4+32+32 {
+		t.Fatalf("len=%d want 68", len(data))
+	}
+	// transfer(address,uint256) selector = 0xa9059cbb
+	if hex.EncodeToString(data[:4]) != "a9059cbb" {
+		t.Fatalf("wrong selector: %x", data[:4])
+	}
+	if !m.Erc20IsTransfer(data) {
+		t.Fatal("encoded call not recognized as transfer")
+	}
+	gotTo, gotAmount, err := m.Erc20DecodeIfTransfer(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotTo != to || gotAmount.Cmp(amount) != 0 {
+		t.Fatalf("round trip mismatch: %s %s", gotTo, gotAmount)
+	}
+}
+
+func TestErc20CallTransfer_NegativeAmount(t *testing.T) {
+	m := newTestManager(t)
+	if _, err := m.Erc20CallTransfer("0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef", big.NewInt(-1)); err == nil {
+		t.Fatal("expected error for negative amount")
+	}
+}
+
+func TestErc20CallTransfer_NilAbi(t *testing.T) {
+	m := NewManager(WithStorage(&memStorage{}), WithAddressCodec(&hexCodec{}))
+	if _, err := m.Erc20CallTransfer("0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef", big.NewInt(1)); err == nil {
+		t.Fatal("expected error when erc20abi is nil")
+	}
+}
+
+func TestErc20TransferTopic(t *testing.T) {
+	m := newTestManager(t)
+	topic, err := m.Erc20TransferTopic()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// keccak256("Transfer(address,address,uint256)")
+	want := "ddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	if hex.EncodeToString(topic) != want {
+		t.Fatalf("topic=%x want %s", topic, want)
+	}
+}
+
+func TestErc20DecodeTransferLog_Indexed(t *testing.T) {
+	m := newTestManager(t)
+	topic, _ := m.Erc20TransferTopic()
+	from, _ := hex.DecodeString("1111111111111111111111111111111111111111")
+	to, _ := hex.DecodeString("2222222222222222222222222222222222222222")
+	topics := [][]byte{topic, bytePad(from, 32, 0), bytePad(to, 32, 0)}
+	data := bytePad(big.NewInt(1500).Bytes(), 32, 0)
+	gotFrom, gotTo, amount, err := m.Erc20DecodeTransferLog(topics, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotFrom != "0x"+hex.EncodeToString(from) || gotTo != "0x"+hex.EncodeToString(to) {
+		t.Fatalf("addresses mismatch: %s %s", gotFrom, gotTo)
+	}
+	if amount.Cmp(big.NewInt(1500)) != 0 {
+		t.Fatalf("amount mismatch: %s", amount)
+	}
+}
+
+func TestErc20DecodeTransferLog_NotIndexed(t *testing.T) {
+	m := newTestManager(t)
+	topic, _ := m.Erc20TransferTopic()
+	from, _ := hex.DecodeString("1111111111111111111111111111111111111111")
+	to, _ := hex.DecodeString("2222222222222222222222222222222222222222")
+	var data []byte
+	data = append(data, bytePad(from, 32, 0)...)
+	data = append(data, bytePad(to, 32, 0)...)
+	data = append(data, bytePad(big.NewInt(7).Bytes(), 32, 0)...)
+	_, gotTo, amount, err := m.Erc20DecodeTransferLog([][]byte{topic}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotTo != "0x"+hex.EncodeToString(to) || amount.Cmp(big.NewInt(7)) != 0 {
+		t.Fatalf("decoded mismatch: %s %s", gotTo, amount)
+	}
+}
+
+func TestErc20DecodeTransferLog_Erc721Rejected(t *testing.T) {
+	m := newTestManager(t)
+	topic, _ := m.Erc20TransferTopic()
+	slot := make([]byte, 32)
+	_, _, _, err := m.Erc20DecodeTransferLog([][]byte{topic, slot, slot, slot}, nil)
+	if !errors.Is(err, ErrNotTransferEvent) {
+		t.Fatalf("expected ErrNotTransferEvent, got %v", err)
+	}
+}
+
+func TestErc20DecodeTransferLog_OtherEvent(t *testing.T) {
+	m := newTestManager(t)
+	slot := make([]byte, 32)
+	_, _, _, err := m.Erc20DecodeTransferLog([][]byte{slot, slot, slot}, slot)
+	if !errors.Is(err, ErrNotTransferEvent) {
+		t.Fatalf("expected ErrNotTransferEvent, got %v", err)
+	}
+}
+
 func TestErc20DecodeAmount(t *testing.T) {
 	m := newTestManager(t)
 	// 32-byte big-endian representation of 0x1234.
@@ -23,6 +23,28 @@ func (m *SmartContractsManager) Erc20CallGetBalance(address string) (callData st
 	return method.encodeInputs(addressBytes)
 }
 
+// Erc20CallTransfer encodes a transfer call of amount tokens to the given address.
+func (m *SmartContractsManager) Erc20CallTransfer(to string, amount *big.Int) (callData []byte, err error) {
+	if m.erc20abi == nil {
+		return nil, ErrSmartContractUnknownMethod
+	}
+	if m.addressCodec == nil {
+		return nil, ErrInvalidParamsData
+	}
+	if amount == nil || amount.Sign() < 0 {
+		return nil, ErrInvalidParamsData
+	}
+	method, err := m.erc20abi.GetMethodByName("transfer")
+	if err != nil {
+		return nil, err
+	}
+	addressBytes, err := m.addressCodec.DecodeAddressToBytes(to)
+	if err != nil {
+		return nil, err
+	}
+	return method.encodeInputsBytes(addressBytes, bytePad(amount.Bytes(), 32, 0))
+}
+
 // Erc20IsTransfer checks if the call data is an ERC-20 transfer method.
 func (m *SmartContractsManager) Erc20IsTransfer(callData []byte) bool {
 	if m.erc20abi == nil {
@@ -85,6 +107,63 @@ func (m *SmartContractsManager) Erc20DecodeIfTransfer(callData []byte) (address
 	return address, amount, nil
 }
 
+// Erc20TransferTopic returns the Transfer(address,address,uint256) event topic.
+func (m *SmartContractsManager) Erc20TransferTopic() (topic []byte, err error) {
+	if m.erc20abi == nil {
+		return nil, ErrSmartContractUnknownMethod
+	}
+	event, err := m.erc20abi.GetMethodByName("Transfer")
+	if err != nil {
+		return nil, err
+	}
+	return event.EventTopic(), nil
+}
+
+// Erc20DecodeTransferLog decodes sender, recipient and amount of a Transfer event log.
+// Supports the standard layout (from and to indexed) and the legacy layout with
+// all params in data. Returns ErrNotTransferEvent for other events, including
+// ERC-721 Transfer events with indexed token id.
+func (m *SmartContractsManager) Erc20DecodeTransferLog(topics [][]byte, data []byte) (from, to string, amount *big.Int, err error) {
+	if m.addressCodec == nil {
+		return "", "", nil, ErrInvalidParamsData
+	}
+	transferTopic, err := m.Erc20TransferTopic()
+	if err != nil {
+		return "", "", nil, err
+	}
+	if len(topics) == 0 || string(topics[0]) != string(transferTopic) {
+		return "", "", nil, ErrNotTransferEvent
+	}
+	var params [3][]byte
+	switch {
+	case len(topics) == 3 && len(data) == 32:
+		params = [3][]byte{topics[1], topics[2], data}
+	case len(topics) == 1 && len(data) == 96:
+		params = [3][]byte{data[0:32], data[32:64], data[64:96]}
+	default:
+		return "", "", nil, ErrNotTransferEvent
+	}
+	fromParam, toParam, amountParam := new(paramInput), new(paramInput), new(paramInput)
+	if _, err = _parseParam(fromParam, "address", params[0]); err != nil {
+		return "", "", nil, err
+	}
+	if _, err = _parseParam(toParam, "address", params[1]); err != nil {
+		return "", "", nil, err
+	}
+	if _, err = _parseParam(amountParam, "uint256", params[2]); err != nil {
+		return "", "", nil, err
+	}
+	from, err = m.addressCodec.EncodeBytesToAddress(fromParam.GetAddressBytes())
+	if err != nil {
+		return "", "", nil, err
+	}
+	to, err = m.addressCodec.EncodeBytesToAddress(toParam.GetAddressBytes())
+	if err != nil {
+		return "", "", nil, err
+	}
+	return from, to, amountParam.GetBigInt(), nil
+}
+
 /*
 
 function name() public view returns (string)
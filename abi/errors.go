@@ -16,4 +16,6 @@ var (
 	ErrUnknownContract = errors.New("unknown contract")
 	// ErrNotTransferMethod is returned when call data is not a transfer method.
 	ErrNotTransferMethod = errors.New("not transfer method")
+	// ErrNotTransferEvent is returned when a log is not an ERC-20 Transfer event.
+	ErrNotTransferEvent = errors.New("not transfer event")
 )
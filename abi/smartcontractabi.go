@@ -143,6 +143,16 @@ func (e *SmartContractAbiEntry) GetSignature() [4]byte {
 	return e.Signature
 }
 
+// EventTopic returns the full Keccak-256 hash of the entry signature,
+// used as the first topic of event logs.
+func (e *SmartContractAbiEntry) EventTopic() []byte {
+	var params = make([]string, len(e.Inputs))
+	for i, in := range e.Inputs {
+		params[i] = in.Type
+	}
+	return crypto.Keccak256([]byte(e.Name + "(" + strings.Join(params, ",") + ")"))
+}
+
 func (e *SmartContractAbiEntry) checkSignature(signature [4]byte) bool {
 	for i, b := range e.Signature {
 		if signature[i] != b {
@@ -7,6 +7,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/rand"
 	"encoding/gob"
+	"fmt"
 	"github.com/ITProLabDev/ethbacknode/crypto"
 	"github.com/ITProLabDev/ethbacknode/crypto/secp256k1"
 )
@@ -38,6 +39,24 @@ type Address struct {
 	Bip39Support bool `json:"bip39Support,omitempty"`
 	// Bip39Mnemonic stores the BIP-39 mnemonic words.
 	Bip39Mnemonic []string `json:"bip39Mnemonic,omitempty"`
+
+	// Bip32Derived indicates the private key is derived from the master seed at Bip32Index.
+	Bip32Derived bool `json:"bip32Derived,omitempty"`
+	// Bip32Public indicates the address is derived from the xpub, the private key is
+	// not on the server.
+	Bip32Public bool `json:"bip32Public,omitempty"`
+	// Bip32Index is the derivation index of the address in the single seed or xpub mode.
+	Bip32Index uint32 `json:"bip32Index,omitempty"`
+
+	// ExternalSigner indicates the private key is held by the external signer,
+	// e.g. a remote signer, and never enters the process.
+	ExternalSigner bool `json:"externalSigner,omitempty"`
+
+	// SealedKey is the encrypted private key and mnemonic, set when the manager has
+	// a keyring. The record keeps no plaintext key material then, in storage and in memory.
+	SealedKey []byte `json:"-"`
+	// sealedPrivateKey indicates SealedKey holds a private key, not only a mnemonic.
+	sealedPrivateKey bool
 }
 
 // AddressCodec defines the interface for address encoding/decoding.
@@ -49,10 +68,24 @@ type AddressCodec interface {
 	DecodeAddressToBytes(address string) ([]byte, error)
 	// PrivateKeyToAddress derives address from private key.
 	PrivateKeyToAddress(privateKey []byte) (string, []byte, error)
+	// PublicKeyToAddress derives address from compressed or uncompressed public key.
+	PublicKeyToAddress(publicKey []byte) (string, []byte, error)
 	// IsValid checks if an address string is valid.
 	IsValid(address string) bool
 }
 
+// HasPrivateKey reports whether the address can sign, with a stored key, plaintext
+// or sealed, or a key derived from the master seed.
+func (a *Address) HasPrivateKey() bool {
+	return len(a.PrivateKey) > 0 || a.sealedPrivateKey || (a.Bip32Derived && !a.Bip32Public)
+}
+
+// CanSign reports whether transactions of the address can be signed, by the
+// private key or by the external signer.
+func (a *Address) CanSign() bool {
+	return a.HasPrivateKey() || a.ExternalSigner
+}
+
 // String returns the address string representation.
 func (a *Address) String() string {
 	return a.Address
@@ -124,20 +157,40 @@ func (p *Manager) isRecordValid(address *Address) (err error) {
 	if len(address.AddressBytes) == 0 {
 		return ErrAddressBytesEmpty
 	}
-	if !address.WatchOnly && len(address.PrivateKey) == 0 {
+	if address.ExternalSigner && p.externalSigner == nil {
+		return ErrExternalSignerUnavailable
+	}
+	if !address.WatchOnly && !address.CanSign() {
 		return ErrPrivateKeyEmpty
 	}
 	return nil
 }
 // preLoadAddresses loads all addresses from storage into memory.
 // Separates addresses into all addresses and free (unsubscribed) addresses.
+// Plaintext records are encrypted in place when the manager has a keyring, the
+// records are kept sealed in memory.
 func (p *Manager) preLoadAddresses() (err error) {
+	var plaintext []*Address
 	err = p.db.ReadAll(func(raw []byte) (err error) {
 		address := &Address{}
 		err = address.Decode(raw)
 		if err != nil {
 			return err
 		}
+		if len(address.SealedKey) > 0 {
+			// the keys are checked once and stay sealed in memory
+			keys, err := p.openAddress(address)
+			if err != nil {
+				return fmt.Errorf("address %s: %w", address.Address, err)
+			}
+			address.sealedPrivateKey = len(keys.PrivateKey) > 0
+			wipeBytes(keys.PrivateKey)
+		} else if p.keyring != nil && address.hasKeyMaterial() {
+			plaintext = append(plaintext, address)
+		}
+		if address.Bip32Derived && p.hd != nil && address.Bip32Public == p.hd.public && address.Bip32Index >= p.hd.nextIndex {
+			p.hd.nextIndex = address.Bip32Index + 1
+		}
 		p.allAddresses[address.Address] = address
 		if !address.Subscribed {
 			p.freeAddresses[address.Address] = address
@@ -147,6 +200,10 @@ func (p *Manager) preLoadAddresses() (err error) {
 	if err != nil {
 		return err
 	}
+	err = p.sealPlaintextAddresses(plaintext)
+	if err != nil {
+		return err
+	}
 	p.updatePool()
 	return nil
 }
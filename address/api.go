@@ -44,7 +44,7 @@ func (p *Manager) GetFreeAddressAndSubscribe(serviceId int, userId, invoiceId in
 		address.ServiceId = serviceId
 		address.UserId = userId
 		address.InvoiceId = invoiceId
-		address.WatchOnly = watchOnly
+		address.WatchOnly = watchOnly || !address.CanSign()
 		address.Subscribed = true
 		return nil
 	})
@@ -198,6 +198,9 @@ func (p *Manager) WalkAllAddresses(walker func(address *Address)) {
 // Manages free address pool membership based on subscription status.
 // UNSAFE: Caller must hold the mutex lock.
 func (p *Manager) updateAddressUnsafe(addressStr string, updater func(address *Address) error) (err error) {
+	if p.stopped {
+		return ErrManagerStopped
+	}
 	addressRecord, found := p.fastPool.LookupString(addressStr)
 	if !found {
 		return ErrAddressUnknown
@@ -211,7 +214,7 @@ func (p *Manager) updateAddressUnsafe(addressStr string, updater func(address *A
 	} else {
 		delete(p.freeAddresses, addressRecord.Address)
 	}
-	err = p.db.Save(addressRecord)
+	err = p.saveAddress(addressRecord)
 	if err != nil {
 		return err
 	}
@@ -222,12 +225,15 @@ func (p *Manager) updateAddressUnsafe(addressStr string, updater func(address *A
 // Adds to free addresses if not subscribed, triggers pool update.
 // UNSAFE: Caller must hold the mutex lock.
 func (p *Manager) addAddressUnsafe(address *Address) (err error) {
+	if p.stopped {
+		return ErrManagerStopped
+	}
 	p.allAddresses[address.Address] = address
 	if !address.Subscribed {
 		p.freeAddresses[address.Address] = address
 	}
 	go p.updatePool()
-	return p.db.Save(address)
+	return p.saveAddress(address)
 }
 
 // getFreeAddressUnsafe returns any available free address from the pool.
@@ -1,12 +1,14 @@
 package address
 
 import (
+	"crypto/ecdsa"
 	"fmt"
 	"github.com/ITProLabDev/ethbacknode/common/bip32"
 	"github.com/ITProLabDev/ethbacknode/common/bip39"
 	"github.com/ITProLabDev/ethbacknode/common/bip44"
 	"github.com/ITProLabDev/ethbacknode/common/hexnum"
 	"github.com/ITProLabDev/ethbacknode/crypto"
+	"github.com/ITProLabDev/ethbacknode/crypto/secp256k1"
 	"github.com/ITProLabDev/ethbacknode/tools/log"
 	"strings"
 	"testing"
@@ -40,6 +42,16 @@ func (a *MockAddressCodec) PrivateKeyToAddress(privateKey []byte) (address strin
 	return address, addressBytes, nil
 }
 
+func (a *MockAddressCodec) PublicKeyToAddress(publicKey []byte) (address string, addressBytes []byte, err error) {
+	x, y := secp256k1.UnmarshalCompressed(secp256k1.P256k1(), publicKey)
+	if x == nil {
+		return "", nil, ErrInvalidPublicKey
+	}
+	addressBytes = crypto.PubKeyToAddressBytes(ecdsa.PublicKey{Curve: secp256k1.P256k1(), X: x, Y: y})
+	address, _ = a.EncodeBytesToAddress(addressBytes)
+	return address, addressBytes, nil
+}
+
 func (a *MockAddressCodec) IsValid(address string) bool {
 	_, err := a.DecodeAddressToBytes(address)
 	return err == nil
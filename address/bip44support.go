@@ -81,6 +81,15 @@ func bip44EntropyToAddressRecord(entropy []byte, coinType uint32, addressCodec A
 // createNewBIP44Address generates a new BIP-44 address with random entropy.
 // Supports 12-word (128-bit) or 24-word (256-bit) mnemonics.
 func createNewBIP44Address(mnemonicLen int, coinType uint32, addressCodec AddressCodec) (addressRecord *Address, err error) {
+	entropy, err := mnemonicEntropy(mnemonicLen)
+	if err != nil {
+		return nil, err
+	}
+	return bip44EntropyToAddressRecord(entropy, coinType, addressCodec)
+}
+
+// mnemonicEntropy generates random entropy for a 12-word (128-bit) or 24-word (256-bit) mnemonic.
+func mnemonicEntropy(mnemonicLen int) (entropy []byte, err error) {
 	bitLen := DefaultEntropyBitLen
 	if mnemonicLen == 12 {
 		bitLen = 128
@@ -89,11 +98,7 @@ func createNewBIP44Address(mnemonicLen int, coinType uint32, addressCodec Addres
 	} else {
 		return nil, ErrInvalidMnemonicLen
 	}
-	entropy, err := bip39.NewEntropy(bitLen)
-	if err != nil {
-		return nil, err
-	}
-	return bip44EntropyToAddressRecord(entropy, coinType, addressCodec)
+	return bip39.NewEntropy(bitLen)
 }
 
 // recoverBIP44AddressFromMnemonic recovers a BIP-44 address from a mnemonic word list.
@@ -21,6 +21,13 @@ type Config struct {
 	Bip36MnemonicLen        int    `json:"bip36MnemonicLen"`
 	Bip44CoinType           string `json:"bip44CoinType"`
 	Bip32DerivationPath     string `json:"bip32DerivationPath"`
+	// SingleSeed derives the pool addresses from one master seed at increasing
+	// indexes of Bip32DerivationPath instead of a mnemonic per address.
+	SingleSeed bool `json:"singleSeed"`
+	// Xpub is the extended public key the watch-only pool addresses are derived from,
+	// at increasing indexes of XpubDerivationPath relative to it (default m/0/0).
+	Xpub               string `json:"xpub,omitempty"`
+	XpubDerivationPath string `json:"xpubDerivationPath,omitempty"`
 }
 
 // _configDefaultStorage returns the default file-based storage for configuration.
@@ -77,7 +84,7 @@ func (c *Config) coldStart() (err error) {
 		c.Bip36MnemonicLen = c.defaultBip36MnemonicLen
 	}
 	c.Bip39Support = c.defaultBip44Support
-	c.Bip32DerivationPath = "m/44'/60'/0'/0/0"
+	c.Bip32DerivationPath = defaultDerivationPath
 	return c.Save()
 }
 
@@ -102,7 +109,7 @@ func (c *Config) checkDefaults() error {
 		changed = true
 	}
 	if c.Bip32DerivationPath == "" {
-		c.Bip32DerivationPath = "m/44'/60'/0'/0/0"
+		c.Bip32DerivationPath = defaultDerivationPath
 		changed = true
 	}
 	if changed {
@@ -22,8 +22,28 @@ var (
 	ErrAddressStringEmpty = errors.New("address string empty")
 	// ErrPrivateKeyEmpty is returned when private key is required but empty.
 	ErrPrivateKeyEmpty = errors.New("private key empty")
+	// ErrExternalSignerUnavailable is returned for an external signer address without the external signer.
+	ErrExternalSignerUnavailable = errors.New("external signer not configured")
 	// ErrAddressPrivateKeyMismatch is returned when address doesn't match private key.
 	ErrAddressPrivateKeyMismatch = errors.New("address and private key mismatch")
 	// ErrInvalidMnemonicLen is returned for invalid BIP-39 mnemonic length.
 	ErrInvalidMnemonicLen = errors.New("invalid mnemonic length")
+	// ErrManagerStopped is returned for write operations after the manager is stopped.
+	ErrManagerStopped = errors.New("address manager stopped")
+	// ErrKeyringLocked is returned when encrypted addresses are loaded without a keyring.
+	ErrKeyringLocked = errors.New("address keys are encrypted, keyring not unlocked")
+	// ErrInvalidPublicKey is returned for a malformed public key.
+	ErrInvalidPublicKey = errors.New("invalid public key")
+	// ErrXpubNotPublic is returned when the configured extended key is a private one.
+	ErrXpubNotPublic = errors.New("extended key is not public")
+	// ErrXpubWithSingleSeed is returned when both the xpub and the single seed mode are configured.
+	ErrXpubWithSingleSeed = errors.New("xpub and single seed mode are mutually exclusive")
+	// ErrSeedStorageEmpty is returned when the single seed mode is enabled without seed storage.
+	ErrSeedStorageEmpty = errors.New("seed storage not set")
+	// ErrMasterSeedUnavailable is returned when a key is derived without the master seed.
+	ErrMasterSeedUnavailable = errors.New("master seed unavailable")
+	// ErrInvalidDerivationPath is returned for a malformed BIP-32 derivation path.
+	ErrInvalidDerivationPath = errors.New("invalid derivation path")
+	// ErrDerivationIndexExhausted is returned when all the address indexes of the path are used.
+	ErrDerivationIndexExhausted = errors.New("derivation index exhausted")
 )
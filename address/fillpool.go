@@ -29,15 +29,21 @@ func (p *Manager) checkFreeAddressPool() {
 }
 
 // refillFreeAddressPool generates new addresses to replenish the free pool.
-// Uses BIP-39/44 if enabled, otherwise generates random addresses.
+// Derives from the master seed in the single seed mode or from the xpub, uses BIP-39/44 if enabled,
+// otherwise generates random addresses.
 // Thread-safe operation.
 func (p *Manager) refillFreeAddressPool(refillAmount int) {
 	p.mux.Lock()
 	defer p.mux.Unlock()
+	if p.stopped {
+		return
+	}
 	for i := 0; i < refillAmount; i++ {
 		var err error
 		var newAddressRecord *Address
-		if p.config.Bip39Support {
+		if p.config.SingleSeed || p.config.Xpub != "" {
+			newAddressRecord, err = p.createHDAddress()
+		} else if p.config.Bip39Support {
 			newAddressRecord, err = p.GenerateBit44Address()
 		} else {
 			newAddressRecord, err = p.createNewAddress()
@@ -48,7 +54,7 @@ func (p *Manager) refillFreeAddressPool(refillAmount int) {
 		}
 		p.allAddresses[newAddressRecord.Address] = newAddressRecord
 		p.freeAddresses[newAddressRecord.Address] = newAddressRecord
-		err = p.db.Save(newAddressRecord)
+		err = p.saveAddress(newAddressRecord)
 		if err != nil {
 			log.Error("Can not save new address to pool:", err)
 			return
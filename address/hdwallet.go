@@ -0,0 +1,363 @@
+package address
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ITProLabDev/ethbacknode/common/bip32"
+	"github.com/ITProLabDev/ethbacknode/common/bip39"
+	"github.com/ITProLabDev/ethbacknode/crypto/keyring"
+	"github.com/ITProLabDev/ethbacknode/storage"
+	"github.com/ITProLabDev/ethbacknode/tools/log"
+)
+
+// defaultDerivationPath is the derivation path of the first pool address.
+const defaultDerivationPath = "m/44'/60'/0'/0/0"
+
+// defaultXpubDerivationPath is the path of the first pool address relative to the
+// configured xpub, the receiving chain of a BIP-44 account key.
+const defaultXpubDerivationPath = "m/0/0"
+
+// seedAdditionalData binds the sealed master mnemonic to its purpose.
+var seedAdditionalData = []byte("master seed")
+
+// seedRecord is the persisted master seed of the single seed mode.
+// DerivationPath is the path of the first address fixed when the seed is created,
+// the mnemonic is stored sealed when the manager has a keyring.
+type seedRecord struct {
+	DerivationPath string   `json:"derivationPath"`
+	Mnemonic       []string `json:"mnemonic,omitempty"`
+	SealedMnemonic []byte   `json:"sealedMnemonic,omitempty"`
+	CreatedAt      int64    `json:"createdAt"`
+}
+
+// hdWallet derives the pool addresses from the master seed or from an extended
+// public key. The account key is the key at the derivation path without the last
+// element, the addresses are its children at increasing indexes starting from the
+// last path element. A public account key derives watch-only addresses.
+type hdWallet struct {
+	account   *bip32.Key
+	hardened  bool
+	public    bool
+	nextIndex uint32
+}
+
+// newHDWallet derives the account key of the derivation path from the mnemonic.
+func newHDWallet(mnemonic []string, derivationPath string) (wallet *hdWallet, err error) {
+	seed, err := bip39.NewSeedWithErrorChecking(strings.Join(mnemonic, " "), "")
+	if err != nil {
+		return nil, err
+	}
+	masterKey, err := bip32.NewMasterKey(seed)
+	if err != nil {
+		return nil, err
+	}
+	return newHDWalletFromKey(masterKey, derivationPath)
+}
+
+// newXpubWallet derives the account key of the derivation path from the base58
+// encoded extended public key. The path must not contain hardened elements.
+func newXpubWallet(xpub string, derivationPath string) (wallet *hdWallet, err error) {
+	key, err := bip32.B58Deserialize(strings.TrimSpace(xpub))
+	if err != nil {
+		return nil, err
+	}
+	if key.IsPrivate {
+		return nil, ErrXpubNotPublic
+	}
+	path, err := parseDerivationPath(derivationPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range path {
+		if child >= bip32.FirstHardenedChild {
+			return nil, ErrInvalidDerivationPath
+		}
+	}
+	return newHDWalletFromKey(key, derivationPath)
+}
+
+// newHDWalletFromKey derives the account key of the derivation path from the root key.
+func newHDWalletFromKey(root *bip32.Key, derivationPath string) (wallet *hdWallet, err error) {
+	path, err := parseDerivationPath(derivationPath)
+	if err != nil {
+		return nil, err
+	}
+	account := root
+	for _, child := range path[:len(path)-1] {
+		account, err = account.NewChildKey(child)
+		if err != nil {
+			return nil, err
+		}
+	}
+	first := path[len(path)-1]
+	return &hdWallet{
+		account:   account,
+		hardened:  first >= bip32.FirstHardenedChild,
+		public:    !account.IsPrivate,
+		nextIndex: first &^ bip32.FirstHardenedChild,
+	}, nil
+}
+
+// deriveKey returns the key of the address at the index.
+func (w *hdWallet) deriveKey(index uint32) (key *bip32.Key, err error) {
+	if index >= bip32.FirstHardenedChild {
+		return nil, ErrDerivationIndexExhausted
+	}
+	if w.hardened {
+		index += bip32.FirstHardenedChild
+	}
+	return w.account.NewChildKey(index)
+}
+
+// parseDerivationPath parses a BIP-32 path like m/44'/60'/0'/0/0.
+// Hardened elements are marked with ' or h.
+func parseDerivationPath(derivationPath string) (path []uint32, err error) {
+	elements := strings.Split(strings.TrimSpace(derivationPath), "/")
+	if len(elements) < 2 || elements[0] != "m" {
+		return nil, ErrInvalidDerivationPath
+	}
+	for _, element := range elements[1:] {
+		var hardened uint32
+		if strings.HasSuffix(element, "'") || strings.HasSuffix(element, "h") {
+			hardened = bip32.FirstHardenedChild
+			element = element[:len(element)-1]
+		}
+		index, err := strconv.ParseUint(element, 10, 31)
+		if err != nil {
+			return nil, ErrInvalidDerivationPath
+		}
+		path = append(path, uint32(index)+hardened)
+	}
+	return path, nil
+}
+
+// initHDWallet loads the master seed, a new one is created when the single seed
+// mode is enabled and there is no seed yet. A plaintext seed is sealed in place
+// when the manager has a keyring. Without the seed and the single seed mode the
+// wallet stays disabled. With the xpub configured the watch-only wallet of the
+// xpub is used instead and no seed is loaded.
+func (p *Manager) initHDWallet() (err error) {
+	if p.config.Xpub != "" {
+		if p.config.SingleSeed {
+			return ErrXpubWithSingleSeed
+		}
+		derivationPath := p.config.XpubDerivationPath
+		if derivationPath == "" {
+			derivationPath = defaultXpubDerivationPath
+		}
+		p.hd, err = newXpubWallet(p.config.Xpub, derivationPath)
+		return err
+	}
+	if p.seedStorage == nil {
+		if p.config.SingleSeed {
+			return ErrSeedStorageEmpty
+		}
+		return nil
+	}
+	record, err := loadSeedRecord(p.seedStorage)
+	if err != nil {
+		return err
+	}
+	if record == nil {
+		if !p.config.SingleSeed {
+			return nil
+		}
+		record, err = p.createSeedRecord()
+		if err != nil {
+			return err
+		}
+	}
+	mnemonic, err := openSeedRecord(record, p.keyring)
+	if err != nil {
+		return err
+	}
+	if p.keyring != nil && len(record.Mnemonic) > 0 {
+		log.Warning("Encrypting master seed")
+		err = saveSeedRecord(p.seedStorage, record, p.keyring)
+		if err != nil {
+			return err
+		}
+	}
+	if p.config.SingleSeed && record.DerivationPath != p.config.Bip32DerivationPath {
+		log.Warning("Master seed derivation path", record.DerivationPath, "differs from configured", p.config.Bip32DerivationPath, ", the seed path is used")
+	}
+	p.hd, err = newHDWallet(mnemonic, record.DerivationPath)
+	return err
+}
+
+// createSeedRecord generates and persists a new master seed.
+func (p *Manager) createSeedRecord() (record *seedRecord, err error) {
+	entropy, err := mnemonicEntropy(p.config.Bip36MnemonicLen)
+	if err != nil {
+		return nil, err
+	}
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return nil, err
+	}
+	_, err = parseDerivationPath(p.config.Bip32DerivationPath)
+	if err != nil {
+		return nil, err
+	}
+	record = &seedRecord{
+		DerivationPath: p.config.Bip32DerivationPath,
+		Mnemonic:       strings.Split(mnemonic, " "),
+		CreatedAt:      time.Now().Unix(),
+	}
+	log.Warning("New master seed created, back it up with the -show-seed command")
+	return record, saveSeedRecord(p.seedStorage, record, p.keyring)
+}
+
+// createHDAddress derives the address at the next index of the master seed or
+// of the xpub, the xpub addresses are watch-only. Indexes which do not produce
+// a valid key are skipped.
+// UNSAFE: Caller must hold the mutex lock.
+func (p *Manager) createHDAddress() (addressRecord *Address, err error) {
+	for {
+		index := p.hd.nextIndex
+		key, err := p.hd.deriveKey(index)
+		if errors.Is(err, bip32.ErrInvalidPrivateKey) || errors.Is(err, bip32.ErrInvalidPublicKey) {
+			p.hd.nextIndex++
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		var addressStr string
+		var addressBytes []byte
+		if p.hd.public {
+			addressStr, addressBytes, err = p.addressCodec.PublicKeyToAddress(key.Key)
+		} else {
+			addressStr, addressBytes, err = p.addressCodec.PrivateKeyToAddress(key.Key)
+		}
+		if err != nil {
+			return nil, err
+		}
+		p.hd.nextIndex++
+		return &Address{
+			Address:      addressStr,
+			AddressBytes: addressBytes,
+			WatchOnly:    p.hd.public,
+			Bip32Derived: true,
+			Bip32Public:  p.hd.public,
+			Bip32Index:   index,
+		}, nil
+	}
+}
+
+// AddressPrivateKey returns the private key of the address. A sealed key is opened
+// and the key of an address derived from the master seed is derived on demand and
+// checked against the address, both are copies the caller should wipe.
+// Returns ErrPrivateKeyEmpty for a watch-only address.
+func (p *Manager) AddressPrivateKey(addressRecord *Address) (privateKey []byte, err error) {
+	if len(addressRecord.PrivateKey) > 0 {
+		return addressRecord.PrivateKey, nil
+	}
+	if addressRecord.sealedPrivateKey {
+		keys, err := p.openAddress(addressRecord)
+		if err != nil {
+			return nil, err
+		}
+		return keys.PrivateKey, nil
+	}
+	if !addressRecord.HasPrivateKey() {
+		return nil, ErrPrivateKeyEmpty
+	}
+	if p.hd == nil || p.hd.public {
+		return nil, ErrMasterSeedUnavailable
+	}
+	key, err := p.hd.deriveKey(addressRecord.Bip32Index)
+	if err != nil {
+		return nil, err
+	}
+	_, addressBytes, err := p.addressCodec.PrivateKeyToAddress(key.Key)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(addressBytes, addressRecord.AddressBytes) {
+		return nil, ErrAddressPrivateKeyMismatch
+	}
+	return key.Key, nil
+}
+
+// AddressMnemonic returns the BIP-39 mnemonic of the address, opening it when the
+// record is sealed. Returns nil for an address without the mnemonic.
+func (p *Manager) AddressMnemonic(addressRecord *Address) (mnemonic []string, err error) {
+	if len(addressRecord.SealedKey) == 0 {
+		return addressRecord.Bip39Mnemonic, nil
+	}
+	keys, err := p.openAddress(addressRecord)
+	if err != nil {
+		return nil, err
+	}
+	wipeBytes(keys.PrivateKey)
+	return keys.Bip39Mnemonic, nil
+}
+
+// ReadMasterMnemonic returns the master seed mnemonic of the single seed mode,
+// k is required if the seed is encrypted.
+func ReadMasterMnemonic(store storage.BinStorage, k *keyring.Keyring) (mnemonic []string, err error) {
+	record, err := loadSeedRecord(store)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, ErrMasterSeedUnavailable
+	}
+	return openSeedRecord(record, k)
+}
+
+// loadSeedRecord reads the seed record, nil if there is no seed.
+func loadSeedRecord(store storage.BinStorage) (record *seedRecord, err error) {
+	if !store.IsExists() {
+		return nil, nil
+	}
+	data, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	record = new(seedRecord)
+	err = json.Unmarshal(data, record)
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// openSeedRecord returns the mnemonic of the seed record.
+func openSeedRecord(record *seedRecord, k *keyring.Keyring) (mnemonic []string, err error) {
+	if len(record.SealedMnemonic) == 0 {
+		if len(record.Mnemonic) == 0 {
+			return nil, ErrMasterSeedUnavailable
+		}
+		return record.Mnemonic, nil
+	}
+	if k == nil {
+		return nil, ErrKeyringLocked
+	}
+	raw, err := k.Open(record.SealedMnemonic, seedAdditionalData)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(string(raw), " "), nil
+}
+
+// saveSeedRecord persists the seed record, the mnemonic is sealed if k is set.
+func saveSeedRecord(store storage.BinStorage, record *seedRecord, k *keyring.Keyring) (err error) {
+	if k != nil && len(record.Mnemonic) > 0 {
+		record.SealedMnemonic, err = k.Seal([]byte(strings.Join(record.Mnemonic, " ")), seedAdditionalData)
+		if err != nil {
+			return err
+		}
+		record.Mnemonic = nil
+	}
+	data, err := json.MarshalIndent(record, "", " ")
+	if err != nil {
+		return err
+	}
+	return store.Save(data)
+}
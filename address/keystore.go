@@ -0,0 +1,43 @@
+package address
+
+import (
+	"github.com/ITProLabDev/ethbacknode/crypto"
+)
+
+// ImportKeystore decrypts the keystore V3 file and adds its address with the private key.
+// The fill function sets the subscription fields of the new record.
+// Returns ErrAddressExists if the address is already managed.
+func (p *Manager) ImportKeystore(keystore, passphrase []byte, fill func(a *Address)) (addressRecord *Address, err error) {
+	privateKey, err := crypto.DecryptKeystore(keystore, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	addressString, _, err := p.addressCodec.PrivateKeyToAddress(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return p.AddAddressFill(addressString, func(a *Address) {
+		fill(a)
+		a.PrivateKey = privateKey
+		a.WatchOnly = false
+		a.ExternalSigner = false
+	})
+}
+
+// ExportKeystore encrypts the private key of the managed address with the passphrase
+// into a keystore V3 file. Returns ErrPrivateKeyEmpty for an address without the key on the server.
+func (p *Manager) ExportKeystore(addressString string, passphrase []byte) (keystore []byte, err error) {
+	addressRecord, err := p.GetAddress(addressString)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := p.AddressPrivateKey(addressRecord)
+	if err != nil {
+		return nil, err
+	}
+	if len(addressRecord.PrivateKey) == 0 {
+		// the opened or derived key is a copy
+		defer wipeBytes(privateKey)
+	}
+	return crypto.EncryptKeystore(privateKey, passphrase, crypto.StandardScryptN, crypto.StandardScryptP)
+}
@@ -1,6 +1,8 @@
 package address
 
 import (
+	"context"
+	"github.com/ITProLabDev/ethbacknode/crypto/keyring"
 	"github.com/ITProLabDev/ethbacknode/storage"
 	"github.com/ITProLabDev/ethbacknode/tools/log"
 	"sync"
@@ -30,6 +32,10 @@ func NewManager(options ...MemPoolOption) (pool *Manager, err error) {
 	if err != nil {
 		return nil, err
 	}
+	err = pool.initHDWallet()
+	if err != nil {
+		return nil, err
+	}
 	err = pool.preLoadAddresses()
 	if err != nil {
 		return nil, err
@@ -62,6 +68,32 @@ func WithAddressCodec(codec AddressCodec) MemPoolOption {
 	}
 }
 
+// WithKeyring sets the keyring the private keys and mnemonics are encrypted with at rest.
+// Addresses stored in plaintext are encrypted on load.
+func WithKeyring(k *keyring.Keyring) MemPoolOption {
+	return func(pool *Manager) error {
+		pool.keyring = k
+		return nil
+	}
+}
+
+// WithSeedStorage sets the storage of the master seed of the single seed mode.
+func WithSeedStorage(store storage.BinStorage) MemPoolOption {
+	return func(pool *Manager) error {
+		pool.seedStorage = store
+		return nil
+	}
+}
+
+// WithExternalSigner sets the source of the signers of the addresses with the key held
+// outside the process, see Address.ExternalSigner.
+func WithExternalSigner(source SignerSource) MemPoolOption {
+	return func(pool *Manager) error {
+		pool.externalSigner = source
+		return nil
+	}
+}
+
 // rawPool is a map type for address storage.
 type rawPool map[string]*Address
 
@@ -69,13 +101,37 @@ type rawPool map[string]*Address
 // It maintains separate pools for all addresses and free (unsubscribed) addresses.
 // Thread-safe for concurrent access.
 type Manager struct {
-	db            storage.SimpleStorage  // Persistent storage for addresses
-	config        *Config                // Manager configuration
-	mux           sync.RWMutex           // Mutex for thread-safe access
-	fastPool      fastStore              // Fast in-memory address lookup
-	allAddresses  map[string]*Address    // All managed addresses
-	freeAddresses map[string]*Address    // Unsubscribed addresses available for use
-	addressCodec  AddressCodec           // Address encoder/decoder
+	db             storage.SimpleStorage // Persistent storage for addresses
+	config         *Config               // Manager configuration
+	mux            sync.RWMutex          // Mutex for thread-safe access
+	fastPool       fastStore             // Fast in-memory address lookup
+	allAddresses   map[string]*Address   // All managed addresses
+	freeAddresses  map[string]*Address   // Unsubscribed addresses available for use
+	addressCodec   AddressCodec          // Address encoder/decoder
+	stopped        bool                  // Set by Stop, write operations are rejected
+	keyring        *keyring.Keyring      // Encrypts key material at rest, nil stores plaintext
+	seedStorage    storage.BinStorage    // Master seed of the single seed mode
+	hd             *hdWallet             // Derives addresses from the master seed, nil if disabled
+	externalSigner SignerSource          // Signers of the external signer addresses, nil if disabled
+}
+
+// Stop waits for the running write operations and rejects new ones with ErrManagerStopped.
+// Lookups keep working. The address storage is closed by its owner.
+// Returns ctx.Err() if the running operations do not finish before the context is done.
+func (p *Manager) Stop(ctx context.Context) (err error) {
+	done := make(chan struct{})
+	go func() {
+		p.mux.Lock()
+		p.stopped = true
+		p.mux.Unlock()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 func (s rawPool) AppendKeys(store []string) []string {
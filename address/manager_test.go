@@ -1,12 +1,22 @@
 package address
 
 import (
+	"bytes"
+	"context"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io/fs"
+	"math/big"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 
+	"github.com/ITProLabDev/ethbacknode/common/bip32"
+	"github.com/ITProLabDev/ethbacknode/common/bip39"
+	"github.com/ITProLabDev/ethbacknode/crypto"
+	"github.com/ITProLabDev/ethbacknode/crypto/keyring"
 	"github.com/ITProLabDev/ethbacknode/storage"
 )
 
@@ -460,3 +470,443 @@ func TestRecoverBit44Address_ValidRoundTrip(t *testing.T) {
 			recovered.Address, original.Address)
 	}
 }
+
+// After Stop, writes are rejected and nothing reaches the store, while
+// lookups of already known addresses keep working.
+func TestStop_RejectsWrites(t *testing.T) {
+	m, store := newTestManager(t)
+	known := makeAddr(1)
+	if err := m.AddAddressRecordsBulk([]*Address{known}); err != nil {
+		t.Fatalf("bulk add: %v", err)
+	}
+	if err := m.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if err := m.AddAddressRecordsBulk([]*Address{makeAddr(2)}); !errors.Is(err, ErrManagerStopped) {
+		t.Fatalf("bulk add after stop: got %v, want ErrManagerStopped", err)
+	}
+	if got := store.count(); got != 1 {
+		t.Fatalf("store count = %d, want 1", got)
+	}
+	if findInPool(m, known.Address) == nil {
+		t.Fatalf("known address must stay in pool")
+	}
+}
+
+// storedRecord decodes the record as it is persisted in the store.
+func storedRecord(t *testing.T, store *memSimpleStorage, a *Address) *Address {
+	t.Helper()
+	stored := &Address{}
+	if err := store.Read(a, stored); err != nil {
+		t.Fatalf("read stored record: %v", err)
+	}
+	return stored
+}
+
+// assertSealedInPool checks the in-memory record of a holds no plaintext key material
+// and its private key is opened with the keyring.
+func assertSealedInPool(t *testing.T, m *Manager, a *Address) {
+	t.Helper()
+	got := findInPool(m, a.Address)
+	if got == nil {
+		t.Fatalf("address %s not in pool", a.Address)
+	}
+	if len(got.PrivateKey) != 0 || len(got.Bip39Mnemonic) != 0 || len(got.SealedKey) == 0 {
+		t.Fatalf("in-memory record must hold the sealed key only")
+	}
+	if !got.HasPrivateKey() {
+		t.Fatalf("sealed record must report the private key")
+	}
+	privateKey, err := m.AddressPrivateKey(got)
+	if err != nil || !bytes.Equal(privateKey, a.PrivateKey) {
+		t.Fatalf("AddressPrivateKey: %v, key differs from the stored one", err)
+	}
+}
+
+// Plaintext records are encrypted in place when the manager is started with a
+// keyring, the records stay sealed in memory, the key is opened on demand and a
+// sealed pool can not be loaded without the keyring.
+func TestKeyring_SealsAndMigratesRecords(t *testing.T) {
+	m, store := newTestManager(t)
+	a := makeAddr(1)
+	if err := m.AddAddressRecordsBulk([]*Address{a}); err != nil {
+		t.Fatalf("bulk add: %v", err)
+	}
+	if stored := storedRecord(t, store, a); len(stored.PrivateKey) == 0 || len(stored.SealedKey) != 0 {
+		t.Fatalf("record without keyring must be stored in plaintext")
+	}
+	k, err := keyring.New()
+	if err != nil {
+		t.Fatalf("keyring.New: %v", err)
+	}
+	cfgStore := &memBinStorage{}
+	if err := cfgStore.Save([]byte(noGenConfig)); err != nil {
+		t.Fatal(err)
+	}
+	open := func(options ...MemPoolOption) (*Manager, error) {
+		return NewManager(append([]MemPoolOption{
+			WithAddressStorage(store),
+			WithConfigStorage(cfgStore),
+			WithAddressCodec(&MockAddressCodec{}),
+		}, options...)...)
+	}
+	m, err = open(WithKeyring(k))
+	if err != nil {
+		t.Fatalf("NewManager with keyring: %v", err)
+	}
+	stored := storedRecord(t, store, a)
+	if len(stored.PrivateKey) != 0 || len(stored.SealedKey) == 0 {
+		t.Fatalf("record must be sealed after migration")
+	}
+	assertSealedInPool(t, m, a)
+	m, err = open(WithKeyring(k))
+	if err != nil {
+		t.Fatalf("NewManager reload: %v", err)
+	}
+	assertSealedInPool(t, m, a)
+	if _, err = open(); !errors.Is(err, ErrKeyringLocked) {
+		t.Fatalf("load without keyring: got %v, want ErrKeyringLocked", err)
+	}
+	other, _ := keyring.New()
+	if _, err = open(WithKeyring(other)); !errors.Is(err, keyring.ErrSealedDataInvalid) {
+		t.Fatalf("load with another keyring: got %v, want ErrSealedDataInvalid", err)
+	}
+}
+
+// The plaintext versions of the sealed records are dropped from the Badger files,
+// the sealed records are loaded from the rebuilt storage.
+func TestKeyring_SealRebuildsStorage(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewBadgerStorage("Address", dir, "address", "addresses.db")
+	if err != nil {
+		t.Fatalf("NewBadgerStorage: %v", err)
+	}
+	defer func() { store.Close() }()
+	cfgStore := &memBinStorage{}
+	if err := cfgStore.Save([]byte(noGenConfig)); err != nil {
+		t.Fatal(err)
+	}
+	open := func(options ...MemPoolOption) (*Manager, error) {
+		return NewManager(append([]MemPoolOption{
+			WithAddressStorage(store),
+			WithConfigStorage(cfgStore),
+			WithAddressCodec(&MockAddressCodec{}),
+		}, options...)...)
+	}
+	m, err := open()
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	a := makeAddr(1)
+	if err := m.AddAddressRecordsBulk([]*Address{a}); err != nil {
+		t.Fatalf("bulk add: %v", err)
+	}
+	k, err := keyring.New()
+	if err != nil {
+		t.Fatalf("keyring.New: %v", err)
+	}
+	if _, err = open(WithKeyring(k)); err != nil {
+		t.Fatalf("NewManager with keyring: %v", err)
+	}
+	if err = store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if bytes.Contains(data, a.PrivateKey) {
+			t.Errorf("plaintext private key found in %s", path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walk storage files: %v", err)
+	}
+	store, err = storage.NewBadgerStorage("Address", dir, "address", "addresses.db")
+	if err != nil {
+		t.Fatalf("reopen storage: %v", err)
+	}
+	m, err = open(WithKeyring(k))
+	if err != nil {
+		t.Fatalf("NewManager reload: %v", err)
+	}
+	assertSealedInPool(t, m, a)
+}
+
+// singleSeedConfig enables the single seed mode generating two addresses.
+const singleSeedConfig = `{
+  "enableAddressGenerate": true,
+  "minFreePoolSize": 1,
+  "generatePoolUpTo": 2,
+  "bip36MnemonicLen": 12,
+  "bip44CoinType": "Ether",
+  "bip32DerivationPath": "m/44'/60'/0'/0/0",
+  "singleSeed": true
+}`
+
+// In the single seed mode the pool addresses are derived at increasing indexes
+// of the master seed, the records hold the index only and the private key is
+// derived on demand. Indexes continue after restart.
+func TestSingleSeed_DerivesIndexedAddresses(t *testing.T) {
+	store := newMemSimpleStorage()
+	cfgStore := &memBinStorage{}
+	if err := cfgStore.Save([]byte(singleSeedConfig)); err != nil {
+		t.Fatal(err)
+	}
+	seedStore := &memBinStorage{}
+	seed := `{"derivationPath":"m/44'/60'/0'/0/0","mnemonic":["abandon","abandon","abandon","abandon","abandon","abandon","abandon","abandon","abandon","abandon","abandon","about"]}`
+	if err := seedStore.Save([]byte(seed)); err != nil {
+		t.Fatal(err)
+	}
+	open := func() *Manager {
+		m, err := NewManager(
+			WithAddressStorage(store),
+			WithConfigStorage(cfgStore),
+			WithSeedStorage(seedStore),
+			WithAddressCodec(&MockAddressCodec{}),
+		)
+		if err != nil {
+			t.Fatalf("NewManager: %v", err)
+		}
+		return m
+	}
+	m := open()
+	want := map[uint32]string{
+		0: "0x9858EfFD232B4033E47d90003D41EC34EcaEda94",
+		1: "0x6Fac4D18c912343BF86fa7049364Dd4E424Ab9C0",
+	}
+	if got := walkCount(m); got != len(want) {
+		t.Fatalf("walk count = %d, want %d", got, len(want))
+	}
+	for index, addr := range want {
+		a := findInPool(m, addr)
+		if a == nil {
+			t.Fatalf("address %s at index %d not derived", addr, index)
+		}
+		if !a.Bip32Derived || a.Bip32Index != index || len(a.PrivateKey) != 0 {
+			t.Fatalf("address %s: derived=%v index=%d, want index %d without stored key", addr, a.Bip32Derived, a.Bip32Index, index)
+		}
+		privateKey, err := m.AddressPrivateKey(a)
+		if err != nil {
+			t.Fatalf("AddressPrivateKey: %v", err)
+		}
+		if derived, _, _ := (&MockAddressCodec{}).PrivateKeyToAddress(privateKey); derived != addr {
+			t.Fatalf("private key of %s derives %s", addr, derived)
+		}
+	}
+	m = open()
+	m.mux.Lock()
+	next := m.hd.nextIndex
+	m.mux.Unlock()
+	if next != 2 {
+		t.Fatalf("next index after restart = %d, want 2", next)
+	}
+}
+
+// With the xpub configured the pool addresses are derived from it as watch-only
+// addresses without any private key and stay watch-only when handed out.
+func TestXpub_DerivesWatchOnlyAddresses(t *testing.T) {
+	seed := bip39.NewSeed("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "")
+	account, err := bip32.NewMasterKey(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, child := range []uint32{44 + bip32.FirstHardenedChild, 60 + bip32.FirstHardenedChild, bip32.FirstHardenedChild} {
+		if account, err = account.NewChildKey(child); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := fmt.Sprintf(`{
+  "enableAddressGenerate": true,
+  "minFreePoolSize": 1,
+  "generatePoolUpTo": 2,
+  "bip36MnemonicLen": 12,
+  "bip44CoinType": "Ether",
+  "bip32DerivationPath": "m/44'/60'/0'/0/0",
+  "xpub": %q
+}`, account.PublicKey().B58Serialize())
+	cfgStore := &memBinStorage{}
+	if err := cfgStore.Save([]byte(cfg)); err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewManager(
+		WithAddressStorage(newMemSimpleStorage()),
+		WithConfigStorage(cfgStore),
+		WithAddressCodec(&MockAddressCodec{}),
+	)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	for index, addr := range []string{
+		"0x9858EfFD232B4033E47d90003D41EC34EcaEda94",
+		"0x6Fac4D18c912343BF86fa7049364Dd4E424Ab9C0",
+	} {
+		a := findInPool(m, addr)
+		if a == nil {
+			t.Fatalf("address %s at index %d not derived", addr, index)
+		}
+		if !a.WatchOnly || !a.Bip32Public || a.HasPrivateKey() || a.Bip32Index != uint32(index) {
+			t.Fatalf("address %s must be watch-only at index %d", addr, index)
+		}
+		if _, err := m.AddressPrivateKey(a); !errors.Is(err, ErrPrivateKeyEmpty) {
+			t.Fatalf("AddressPrivateKey: got %v, want ErrPrivateKeyEmpty", err)
+		}
+	}
+	a, err := m.GetFreeAddressAndSubscribe(1, 1, 1, false)
+	if err != nil {
+		t.Fatalf("GetFreeAddressAndSubscribe: %v", err)
+	}
+	if !a.WatchOnly {
+		t.Fatalf("handed out xpub address must stay watch-only")
+	}
+}
+
+// Addresses with the key held by the external signer can sign only through the
+// configured external signer source, watch-only addresses have no signer.
+func TestAddressSigner_ExternalSigner(t *testing.T) {
+	m, _ := newTestManager(t)
+	external := makeAddr(1)
+	_, err := m.AddAddressFill(external.Address, func(a *Address) {
+		a.ExternalSigner = true
+	})
+	if !errors.Is(err, ErrExternalSignerUnavailable) {
+		t.Fatalf("AddAddressFill without external signer: got %v, want ErrExternalSignerUnavailable", err)
+	}
+
+	remote, err := crypto.NewLocalSigner(bytes.Repeat([]byte{0x46}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var requested []byte
+	m.externalSigner = func(addressBytes []byte) (crypto.Signer, error) {
+		requested = addressBytes
+		return remote, nil
+	}
+	a, err := m.AddAddressFill(external.Address, func(a *Address) {
+		a.ExternalSigner = true
+	})
+	if err != nil {
+		t.Fatalf("AddAddressFill: %v", err)
+	}
+	if !a.CanSign() || a.HasPrivateKey() {
+		t.Fatalf("external signer address must sign without a private key")
+	}
+	signer, err := m.AddressSigner(a)
+	if err != nil || signer != remote || !bytes.Equal(requested, external.AddressBytes) {
+		t.Fatalf("AddressSigner: got %v, %v for %x", signer, err, requested)
+	}
+
+	// the configured external signer is used even if the private key is known
+	withKey := makeAddr(3)
+	withKey.ExternalSigner = true
+	if err = m.AddAddressRecordsBulk([]*Address{withKey}); err != nil {
+		t.Fatalf("bulk add: %v", err)
+	}
+	if signer, err = m.AddressSigner(withKey); err != nil || signer != remote {
+		t.Fatalf("AddressSigner with private key: got %v, %v, want the external signer", signer, err)
+	}
+
+	watchOnly, err := m.AddAddressFill(makeAddr(2).Address, func(a *Address) {
+		a.WatchOnly = true
+	})
+	if err != nil {
+		t.Fatalf("AddAddressFill watch-only: %v", err)
+	}
+	if _, err = m.AddressSigner(watchOnly); !errors.Is(err, ErrPrivateKeyEmpty) {
+		t.Fatalf("AddressSigner watch-only: got %v, want ErrPrivateKeyEmpty", err)
+	}
+}
+
+// With the keys encrypted the record keeps no plaintext key in memory and the
+// address is signed by opening the sealed record inside SignTx.
+func TestAddressSigner_Keyring(t *testing.T) {
+	k, err := keyring.New()
+	if err != nil {
+		t.Fatalf("keyring.New: %v", err)
+	}
+	cfgStore := &memBinStorage{}
+	if err := cfgStore.Save([]byte(noGenConfig)); err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewManager(
+		WithAddressStorage(newMemSimpleStorage()),
+		WithConfigStorage(cfgStore),
+		WithAddressCodec(&MockAddressCodec{}),
+		WithKeyring(k),
+	)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	privateKey := bytes.Repeat([]byte{0x46}, 32)
+	local, err := crypto.NewLocalSigner(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &Address{Address: "0x" + hex.EncodeToString(local.Address()), AddressBytes: local.Address(), PrivateKey: privateKey}
+	if err = m.AddAddressRecordsBulk([]*Address{a}); err != nil {
+		t.Fatalf("bulk add: %v", err)
+	}
+	signer, err := m.AddressSigner(a)
+	if err != nil {
+		t.Fatalf("AddressSigner: %v", err)
+	}
+	if _, sealed := signer.(*sealedSigner); !sealed {
+		t.Fatalf("AddressSigner: got %T, want *sealedSigner", signer)
+	}
+	if len(a.PrivateKey) != 0 {
+		t.Fatalf("in-memory record must not keep the plaintext private key")
+	}
+	tx := &crypto.Tx{ChainId: big.NewInt(1), Nonce: 1, To: local.Address(), Value: big.NewInt(1), Gas: 21000, GasPrice: big.NewInt(1)}
+	want, err := local.SignTx(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := signer.SignTx(tx); err != nil || !bytes.Equal(got, want) {
+		t.Fatalf("SignTx: %v, signature differs from the local one", err)
+	}
+	if !bytes.Equal(privateKey, bytes.Repeat([]byte{0x46}, 32)) {
+		t.Fatalf("the private key of the caller must stay intact")
+	}
+}
+
+// A keystore file is imported with its private key and the address is exported
+// back as a keystore file of the same key.
+func TestKeystore_ImportExport(t *testing.T) {
+	m, _ := newTestManager(t)
+	privateKey := bytes.Repeat([]byte{0x46}, 32)
+	keystore, err := crypto.EncryptKeystore(privateKey, []byte("import"), crypto.LightScryptN, crypto.LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.ImportKeystore(keystore, []byte("wrong"), func(a *Address) {}); !errors.Is(err, crypto.ErrKeystoreWrongPassphrase) {
+		t.Fatalf("ImportKeystore wrong passphrase: got %v", err)
+	}
+	a, err := m.ImportKeystore(keystore, []byte("import"), func(a *Address) {
+		a.ServiceId = 1
+		a.Subscribed = true
+		a.WatchOnly = true
+	})
+	if err != nil {
+		t.Fatalf("ImportKeystore: %v", err)
+	}
+	if a.Address != "0x9d8A62f656a8d1615C1294fd71e9CFb3E4855A4F" || a.WatchOnly || !bytes.Equal(a.PrivateKey, privateKey) || a.ServiceId != 1 {
+		t.Fatalf("imported record: %+v", a)
+	}
+	if _, err = m.ImportKeystore(keystore, []byte("import"), func(a *Address) {}); !errors.Is(err, ErrAddressExists) {
+		t.Fatalf("second import: got %v, want ErrAddressExists", err)
+	}
+	m.updatePool()
+	exported, err := m.ExportKeystore(a.Address, []byte("export"))
+	if err != nil {
+		t.Fatalf("ExportKeystore: %v", err)
+	}
+	decrypted, err := crypto.DecryptKeystore(exported, []byte("export"))
+	if err != nil || !bytes.Equal(decrypted, privateKey) {
+		t.Fatalf("exported keystore: %x, %v", decrypted, err)
+	}
+}
@@ -0,0 +1,114 @@
+package address
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/ITProLabDev/ethbacknode/crypto/keyring"
+	"github.com/ITProLabDev/ethbacknode/storage"
+	"github.com/ITProLabDev/ethbacknode/tools/log"
+)
+
+// sealedKeys is the key material of an address encrypted at rest.
+type sealedKeys struct {
+	PrivateKey    []byte
+	Bip39Mnemonic []string
+}
+
+// hasKeyMaterial reports whether the address holds a private key or mnemonic in plaintext.
+func (a *Address) hasKeyMaterial() bool {
+	return len(a.PrivateKey) > 0 || len(a.Bip39Mnemonic) > 0
+}
+
+// saveAddress persists the address record. With a keyring set, the private key and
+// the mnemonic are sealed with the master key, bound to the address bytes, and the
+// in-memory record keeps them sealed as well, see openAddress.
+func (p *Manager) saveAddress(address *Address) (err error) {
+	if p.keyring != nil && address.hasKeyMaterial() {
+		err = p.sealAddress(address)
+		if err != nil {
+			return err
+		}
+	}
+	return p.db.Save(address)
+}
+
+// sealAddress replaces the plaintext key material of the record with the sealed one.
+func (p *Manager) sealAddress(address *Address) (err error) {
+	var b bytes.Buffer
+	err = gob.NewEncoder(&b).Encode(&sealedKeys{
+		PrivateKey:    address.PrivateKey,
+		Bip39Mnemonic: address.Bip39Mnemonic,
+	})
+	if err != nil {
+		return err
+	}
+	address.SealedKey, err = p.keyring.Seal(b.Bytes(), address.AddressBytes)
+	wipeBytes(b.Bytes())
+	if err != nil {
+		return err
+	}
+	address.sealedPrivateKey = len(address.PrivateKey) > 0
+	address.PrivateKey = nil
+	address.Bip39Mnemonic = nil
+	return nil
+}
+
+// openAddress opens the key material of a sealed record. The returned keys are a
+// copy the caller should wipe once used, the record stays sealed.
+// Returns ErrKeyringLocked when no keyring is set.
+func (p *Manager) openAddress(address *Address) (keys *sealedKeys, err error) {
+	if p.keyring == nil {
+		return nil, ErrKeyringLocked
+	}
+	return openSealedKeys(p.keyring, address.SealedKey, address.AddressBytes)
+}
+
+// openSealedKeys decrypts the key material sealed with the keyring for the address.
+func openSealedKeys(k *keyring.Keyring, sealedKey, addressBytes []byte) (keys *sealedKeys, err error) {
+	raw, err := k.Open(sealedKey, addressBytes)
+	if err != nil {
+		return nil, err
+	}
+	defer wipeBytes(raw)
+	keys = new(sealedKeys)
+	err = gob.NewDecoder(bytes.NewReader(raw)).Decode(keys)
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// wipeBytes overwrites the key material with zeros.
+func wipeBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// sealPlaintextAddresses encrypts the records stored before the keyring was set.
+// The storage keeps the replaced plaintext versions of the records, so it is rebuilt
+// without them afterwards.
+func (p *Manager) sealPlaintextAddresses(addresses []*Address) (err error) {
+	if len(addresses) == 0 {
+		return nil
+	}
+	log.Warning("Encrypting key material of", len(addresses), "stored addresses")
+	for _, address := range addresses {
+		err = p.saveAddress(address)
+		if err != nil {
+			return err
+		}
+	}
+	store, ok := p.db.(storage.RebuildableStorage)
+	if !ok {
+		log.Warning("Address storage can not be rebuilt, old plaintext records may remain on disk")
+		return nil
+	}
+	err = store.Rebuild()
+	if err != nil {
+		return err
+	}
+	log.Info("Stored addresses encrypted")
+	return nil
+}
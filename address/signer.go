@@ -0,0 +1,77 @@
+package address
+
+import (
+	"bytes"
+
+	"github.com/ITProLabDev/ethbacknode/crypto"
+	"github.com/ITProLabDev/ethbacknode/crypto/keyring"
+)
+
+// SignerSource returns the signer of the address, the key of which is held outside the process.
+type SignerSource func(addressBytes []byte) (signer crypto.Signer, err error)
+
+// AddressSigner returns the signer of the address. An address with ExternalSigner set
+// is signed by the external signer when one is configured. Otherwise an address with
+// the private key, stored or derived from the master seed, is signed locally. When the
+// keys are encrypted, a stored key stays sealed in the record and is opened only inside
+// SignTx, a derived key is sealed with the keyring between signatures.
+// Returns ErrPrivateKeyEmpty for a watch-only address.
+func (p *Manager) AddressSigner(addressRecord *Address) (signer crypto.Signer, err error) {
+	if addressRecord.ExternalSigner && p.externalSigner != nil {
+		return p.externalSigner(addressRecord.AddressBytes)
+	}
+	if !addressRecord.HasPrivateKey() {
+		if addressRecord.ExternalSigner {
+			return nil, ErrExternalSignerUnavailable
+		}
+		return nil, ErrPrivateKeyEmpty
+	}
+	if addressRecord.sealedPrivateKey && p.keyring != nil {
+		return &sealedSigner{
+			keyring:   p.keyring,
+			sealedKey: addressRecord.SealedKey,
+			address:   addressRecord.AddressBytes,
+		}, nil
+	}
+	privateKey, err := p.AddressPrivateKey(addressRecord)
+	if err != nil {
+		return nil, err
+	}
+	if p.keyring == nil {
+		return crypto.NewLocalSigner(privateKey)
+	}
+	if len(addressRecord.PrivateKey) == 0 {
+		// the derived key is a copy, it is not kept once sealed
+		defer wipeBytes(privateKey)
+	}
+	return crypto.NewKeyringSigner(p.keyring, privateKey)
+}
+
+// sealedSigner signs with the private key sealed in the address record.
+type sealedSigner struct {
+	keyring   *keyring.Keyring
+	sealedKey []byte
+	address   []byte
+}
+
+// Address returns the address of the record.
+func (s *sealedSigner) Address() []byte {
+	return s.address
+}
+
+// SignTx opens the record, signs the transaction and wipes the opened key.
+func (s *sealedSigner) SignTx(tx *crypto.Tx) (signedTx []byte, err error) {
+	keys, err := openSealedKeys(s.keyring, s.sealedKey, s.address)
+	if err != nil {
+		return nil, err
+	}
+	defer wipeBytes(keys.PrivateKey)
+	local, err := crypto.NewLocalSigner(keys.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(local.Address(), s.address) {
+		return nil, ErrAddressPrivateKeyMismatch
+	}
+	return local.SignTx(tx)
+}
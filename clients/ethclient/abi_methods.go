@@ -20,3 +20,20 @@ func (c *Client) ContractGetBalanceOf(contractAddress, address string) (balance
 	}
 	return c.abi.Erc20DecodeAmount(b), nil
 }
+
+// ContractGetBalanceOfByBlockNumber returns the ERC-20 token balance of an address at the given block.
+func (c *Client) ContractGetBalanceOfByBlockNumber(contractAddress, address string, blockNumber int64) (balance *big.Int, err error) {
+	callTx, err := c.abi.Erc20CallGetBalance(address)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.CallByBlockNumber(contractAddress, callTx, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	b, err := hexnum.ParseHexBytes(result)
+	if err != nil {
+		return nil, err
+	}
+	return c.abi.Erc20DecodeAmount(b), nil
+}
@@ -1,9 +1,12 @@
 package ethclient
 
 import (
+	"crypto/ecdsa"
 	"github.com/ITProLabDev/ethbacknode/address"
 	"github.com/ITProLabDev/ethbacknode/common/hexnum"
 	"github.com/ITProLabDev/ethbacknode/crypto"
+	"github.com/ITProLabDev/ethbacknode/crypto/secp256k1"
+	"math/big"
 	"strings"
 )
 
@@ -46,6 +49,18 @@ func (a *AddressCodec) PrivateKeyToAddress(privateKey []byte) (address string, a
 	return address, addressBytes, nil
 }
 
+// PublicKeyToAddress derives an Ethereum address from a compressed (33 bytes)
+// or uncompressed (65 bytes) secp256k1 public key.
+func (a *AddressCodec) PublicKeyToAddress(publicKey []byte) (address string, addressBytes []byte, err error) {
+	pub, err := _parsePublicKey(publicKey)
+	if err != nil {
+		return "", nil, err
+	}
+	addressBytes = crypto.PubKeyToAddressBytes(*pub)
+	address, _ = a.EncodeBytesToAddress(addressBytes)
+	return address, addressBytes, nil
+}
+
 // IsValid checks if an address string is a valid Ethereum address.
 func (a *AddressCodec) IsValid(address string) bool {
 	_, err := a.DecodeAddressToBytes(address)
@@ -68,3 +83,20 @@ func _addressCheckSum(address string) string {
 	}
 	return ret
 }
+
+// _parsePublicKey decodes a compressed or uncompressed secp256k1 public key.
+func _parsePublicKey(publicKey []byte) (*ecdsa.PublicKey, error) {
+	curve := secp256k1.P256k1()
+	pub := &ecdsa.PublicKey{Curve: curve}
+	switch {
+	case len(publicKey) == 33:
+		pub.X, pub.Y = secp256k1.UnmarshalCompressed(curve, publicKey)
+	case len(publicKey) == 65 && publicKey[0] == 4:
+		pub.X = new(big.Int).SetBytes(publicKey[1:33])
+		pub.Y = new(big.Int).SetBytes(publicKey[33:])
+	}
+	if pub.X == nil || !curve.IsOnCurve(pub.X, pub.Y) {
+		return nil, address.ErrInvalidPublicKey
+	}
+	return pub, nil
+}
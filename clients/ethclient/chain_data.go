@@ -0,0 +1,67 @@
+package ethclient
+
+import (
+	"encoding/json"
+	"math/big"
+)
+
+// TxChainData is the Ethereum specific part of types.TransferInfo, stored
+// JSON encoded in ChainSpecificData. Receipt fields are set for mined transactions only.
+type TxChainData struct {
+	TxHash               string   `json:"txHash,omitempty"`    // set for token transfers detected from logs
+	LogIndex             int64    `json:"logIndex,omitempty"`  // set together with TxHash
+	CallIndex            int64    `json:"callIndex,omitempty"` // set for internal transfers detected by tracing
+	Type                 int64    `json:"type"`
+	Nonce                int64    `json:"nonce"`
+	Gas                  int64    `json:"gas"`
+	GasPrice             *big.Int `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *big.Int `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *big.Int `json:"maxPriorityFeePerGas,omitempty"`
+	MaxFeePerBlobGas     *big.Int `json:"maxFeePerBlobGas,omitempty"`
+	BlobVersionedHashes  []string `json:"blobVersionedHashes,omitempty"`
+	EffectiveGasPrice    *big.Int `json:"effectiveGasPrice,omitempty"`
+	GasUsed              int64    `json:"gasUsed,omitempty"`
+	BlobGasUsed          int64    `json:"blobGasUsed,omitempty"`
+	BlobGasPrice         *big.Int `json:"blobGasPrice,omitempty"`
+}
+
+// Decode implements types.DataDecoder:
+//
+//	chainData := new(ethclient.TxChainData)
+//	err := transferInfo.DecodeChainSpecificData(chainData.Decode)
+func (d *TxChainData) Decode(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, d)
+}
+
+// newTxChainData collects the type and fee parameters of a transaction.
+func newTxChainData(tx *Transaction) *TxChainData {
+	return &TxChainData{
+		Type:                 tx.Type,
+		Nonce:                tx.Nonce,
+		Gas:                  tx.Gas,
+		GasPrice:             tx.GasPrice,
+		MaxFeePerGas:         tx.MaxFeePerGas,
+		MaxPriorityFeePerGas: tx.MaxPriorityFeePerGas,
+		MaxFeePerBlobGas:     tx.MaxFeePerBlobGas,
+		BlobVersionedHashes:  tx.BlobVersionedHashes,
+	}
+}
+
+// applyReceipt sets the gas actually used and paid from the transaction receipt.
+// gasPrice is used if the node does not return effectiveGasPrice.
+func (d *TxChainData) applyReceipt(receipt *Receipt, gasPrice *big.Int) *TxChainData {
+	d.EffectiveGasPrice = receipt.GetEffectiveGasPrice(gasPrice)
+	d.GasUsed = receipt.GasUsed
+	d.BlobGasUsed = receipt.BlobGasUsed
+	d.BlobGasPrice = receipt.BlobGasPrice
+	return d
+}
+
+// encode returns the JSON encoded chain data for types.TransferInfo.
+func (d *TxChainData) encode() []byte {
+	data, _ := json.Marshal(d)
+	return data
+}
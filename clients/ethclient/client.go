@@ -4,15 +4,18 @@
 package ethclient
 
 import (
+	"context"
 	"errors"
 	"github.com/ITProLabDev/ethbacknode/abi"
 	"github.com/ITProLabDev/ethbacknode/address"
 	"github.com/ITProLabDev/ethbacknode/clients/urpc"
 	"github.com/ITProLabDev/ethbacknode/common/hexnum"
+	"github.com/ITProLabDev/ethbacknode/crypto"
 	"github.com/ITProLabDev/ethbacknode/tools/log"
 	"github.com/ITProLabDev/ethbacknode/types"
 	"math/big"
-	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 // Option is a function that configures a Client.
@@ -36,6 +39,7 @@ func NewClient(options ...Option) *Client {
 		decimals:         18,
 		addressCodec:     GetAddressCodec(),
 		minConfirmations: DEFAULT_CONFIRMATIONS,
+		nonces:           newNonceManager(),
 	}
 	for _, option := range options {
 		option(client)
@@ -56,6 +60,11 @@ type Client struct {
 	addressCodec     address.AddressCodec       // Address encoder/decoder
 	tokens           []*types.TokenInfo         // Supported tokens list
 	minConfirmations int                        // Required confirmations
+	noBlockReceipts  atomic.Bool                // Node does not support eth_getBlockReceipts
+	nonces           *nonceManager              // Outgoing transactions nonce allocator
+	signerSource     SignerSource               // Sending address signers for the automatic speed up
+	onReplace        ReplacementListener        // Receives the hashes of replaced outgoing transactions
+	speedUpMux       sync.Mutex                 // Serializes the stuck transactions processing
 }
 
 // BalanceOf returns the native coin balance of an address in wei.
@@ -66,16 +75,28 @@ func (c *Client) BalanceOf(address string) (balance *big.Int, err error) {
 // TokensBalanceOf returns the token balance of an address.
 // Accepts token symbol or contract address.
 func (c *Client) TokensBalanceOf(address string, token string) (balance *big.Int, err error) {
-	tokenInfo, ok := c.tokenGetIfExistBySymbol(token)
-	if !ok {
-		tokenInfo, ok = c.tokenGetIfExistByAddress(token)
-		if !ok {
-			return nil, ErrUnknownToken
-		}
+	tokenInfo, err := c.tokenGet(token)
+	if err != nil {
+		return nil, err
 	}
 	return c.ContractGetBalanceOf(tokenInfo.ContractAddress, address)
 }
 
+// BalanceAt returns the native coin balance of an address at the given block.
+func (c *Client) BalanceAt(address string, blockNum int64) (balance *big.Int, err error) {
+	return c.GetBalanceByBlockNumber(address, blockNum)
+}
+
+// TokensBalanceAt returns the token balance of an address at the given block.
+// Accepts token symbol or contract address.
+func (c *Client) TokensBalanceAt(address string, token string, blockNum int64) (balance *big.Int, err error) {
+	tokenInfo, err := c.tokenGet(token)
+	if err != nil {
+		return nil, err
+	}
+	return c.ContractGetBalanceOfByBlockNumber(tokenInfo.ContractAddress, address, blockNum)
+}
+
 // Init loads configuration and initializes the client.
 // Must be called before using the client.
 func (c *Client) Init() error {
@@ -99,8 +120,19 @@ func (c *Client) Init() error {
 		c.minConfirmations = c.config.Confirmations
 	}
 	c.tokens = c.config.Tokens
+	err = c.nonceReconcileAll()
+	if err != nil {
+		log.Error("Can not reconcile nonces with chain state:", err)
+	}
 	return nil
 }
+
+// Stop waits for the sends and the speed up in progress, new transfers fail with
+// ErrClientStopped, so the nonce storage can be closed. Returns ctx.Err() if the
+// client does not stop before the context is done.
+func (c *Client) Stop(ctx context.Context) error {
+	return c.nonces.stop(ctx)
+}
 func (c *Client) SetConfirmations(confirmations int) {
 	c.minConfirmations = confirmations
 }
@@ -153,7 +185,7 @@ func (c *Client) MemPoolContent() (poolContent []*types.TransferInfo, err error)
 	}
 	for _, txBlocks := range pending {
 		for _, tx := range txBlocks {
-			t, err := c.transactionDecode(tx)
+			t, err := c.transactionDecode(tx, nil)
 			if err != nil &&
 				!errors.Is(err, ErrTransactionNotTransfer) &&
 				!errors.Is(err, ErrUnsupportedTransactionType) &&
@@ -171,7 +203,7 @@ func (c *Client) MemPoolContent() (poolContent []*types.TransferInfo, err error)
 	}
 	for _, txBlocks := range queued {
 		for _, tx := range txBlocks {
-			t, err := c.transactionDecode(tx)
+			t, err := c.transactionDecode(tx, nil)
 			if err != nil &&
 				!errors.Is(err, ErrTransactionNotTransfer) &&
 				!errors.Is(err, ErrUnsupportedTransactionType) &&
@@ -200,16 +232,22 @@ func (c *Client) BlockByNum(blockNum int64, fullInfo bool) (block *types.BlockIn
 		return nil, err
 	}
 	block, err = c.blockDecode(blockInternal)
+	if err != nil {
+		return nil, err
+	}
 	return block, nil
 }
 
 func (c *Client) BlockByHash(blockHash string, fullInfo bool) (block *types.BlockInfo, err error) {
-	//blockInternal
-	_, err = c.GetBlockByHash(blockHash, fullInfo)
+	blockInternal, err := c.GetBlockByHash(blockHash, fullInfo)
 	if err != nil {
 		return nil, err
 	}
-	panic("implement me")
+	block, err = c.blockDecode(blockInternal)
+	if err != nil {
+		return nil, err
+	}
+	return block, nil
 }
 
 func (c *Client) TransferInfoByHash(txHash string) (tx *types.TransferInfo, err error) {
@@ -217,10 +255,21 @@ func (c *Client) TransferInfoByHash(txHash string) (tx *types.TransferInfo, err
 	if err != nil {
 		return nil, err
 	}
-	tx, err = c.transactionDecode(txInternal)
+	tx, err = c.transactionDecode(txInternal, nil)
 	if err != nil {
 		return nil, err
 	}
+	if txInternal.BlockNumber != 0 {
+		receipt, err := c.GetTransactionReceipt(txInternal.Hash)
+		if err != nil {
+			return nil, err
+		}
+		baseFee, err := c.receiptBaseFee(txInternal, receipt)
+		if err != nil {
+			return nil, err
+		}
+		transactionApplyReceipt(tx, txInternal, receipt, baseFee)
+	}
 	currentBlock, _ := c.GetBlockNumber()
 	if txInternal.BlockNumber == 0 {
 		tx.InPool = true
@@ -241,10 +290,21 @@ func (c *Client) TransferInfoByNum(blockNum int64, txIndex int) (tx *types.Trans
 	if err != nil {
 		return nil, err
 	}
-	tx, err = c.transactionDecode(txInternal)
+	tx, err = c.transactionDecode(txInternal, nil)
 	if err != nil {
 		return nil, err
 	}
+	if txInternal.BlockNumber != 0 {
+		receipt, err := c.GetTransactionReceipt(txInternal.Hash)
+		if err != nil {
+			return nil, err
+		}
+		baseFee, err := c.receiptBaseFee(txInternal, receipt)
+		if err != nil {
+			return nil, err
+		}
+		transactionApplyReceipt(tx, txInternal, receipt, baseFee)
+	}
 	currentBlock, _ := c.GetBlockNumber()
 	if txInternal.BlockNumber == 0 {
 		tx.InPool = true
@@ -256,17 +316,44 @@ func (c *Client) TransferInfoByNum(blockNum int64, txIndex int) (tx *types.Trans
 	return tx, nil
 }
 
+// TransactionBlockNum returns the number of the block including the transaction,
+// 0 if it is in the mempool. Transfer IDs of log and internal transfers are accepted.
+func (c *Client) TransactionBlockNum(txHash string) (blockNum int64, err error) {
+	txInternal, err := c.GetTransactionByHash(types.TransferTxHash(txHash))
+	if err != nil {
+		return 0, err
+	}
+	return txInternal.BlockNumber, nil
+}
+
 func (c *Client) TransactionSendRaw(rawTx []byte) (txHash string, err error) {
 	return c.SendRawTransaction(hexnum.BytesToHex(rawTx))
 }
 
+// TransferByPrivateKey sends amount of native coin from the address owning the private key.
 func (c *Client) TransferByPrivateKey(fromPrivateKey []byte, from, to string, amount *big.Int) (txHash string, err error) {
-	fromAddress, _, err := c.addressCodec.PrivateKeyToAddress(fromPrivateKey)
+	signer, err := c.privateKeySigner(fromPrivateKey, from)
 	if err != nil {
 		return "", err
 	}
-	if strings.ToUpper(from) != strings.ToUpper(fromAddress) {
-		return "", address.ErrAddressPrivateKeyMismatch
+	return c.TransferBySigner(signer, to, amount)
+}
+
+// TransferAllByPrivateKey sends the whole native coin balance less the fee
+// from the address owning the private key.
+func (c *Client) TransferAllByPrivateKey(fromPrivateKey []byte, from, to string) (txHash string, err error) {
+	signer, err := c.privateKeySigner(fromPrivateKey, from)
+	if err != nil {
+		return "", err
+	}
+	return c.TransferAllBySigner(signer, to)
+}
+
+// TransferBySigner sends amount of native coin from the address of the signer.
+func (c *Client) TransferBySigner(signer crypto.Signer, to string, amount *big.Int) (txHash string, err error) {
+	from, err := c.signerAddress(signer)
+	if err != nil {
+		return "", err
 	}
 	_, err = c.addressCodec.DecodeAddressToBytes(to)
 	if err != nil {
@@ -277,27 +364,29 @@ func (c *Client) TransferByPrivateKey(fromPrivateKey []byte, from, to string, am
 		return "", err
 	}
 	log.Warning("Current Balance:", currentBalance)
-	fee, gasPrice, gas, err := c.GetEstimatedFee(fromAddress, to, "", currentBalance)
+	txFee, err := c.GetEstimatedTxFee(from, to, "", currentBalance)
 	if err != nil {
 		return "", err
 	}
+	fee := txFee.MaxFee()
 	amountWithFee := new(big.Int).Add(amount, fee)
 	log.Warning("Estimated Fee:", fee)
 	log.Warning("Amount with Fee:", amountWithFee)
 	if amountWithFee.Cmp(currentBalance) > 0 {
 		return "", ErrInsufficientFunds
 	}
-	return c.sendRawByPrivateKeyUnsafe(fromPrivateKey, from, to, amount, gasPrice, gas)
+	return c.sendRawBySignerUnsafe(signer, from, to, amount, nil, txFee)
 }
 
-func (c *Client) TransferAllByPrivateKey(fromPrivateKey []byte, from, to string) (txHash string, err error) {
-	fromAddress, _, err := c.addressCodec.PrivateKeyToAddress(fromPrivateKey)
+// TransferAllBySigner sends the whole native coin balance less the fee
+// from the address of the signer. The node requires the balance to cover
+// gas * maxFeePerGas, so for EIP-1559 transactions the difference between the
+// max fee and the fee actually paid (baseFee + tip) remains on the address as dust.
+func (c *Client) TransferAllBySigner(signer crypto.Signer, to string) (txHash string, err error) {
+	from, err := c.signerAddress(signer)
 	if err != nil {
 		return "", err
 	}
-	if strings.ToUpper(from) != strings.ToUpper(fromAddress) {
-		return "", address.ErrAddressPrivateKeyMismatch
-	}
 	_, err = c.addressCodec.DecodeAddressToBytes(to)
 	if err != nil {
 		return "", err
@@ -307,40 +396,26 @@ func (c *Client) TransferAllByPrivateKey(fromPrivateKey []byte, from, to string)
 		return "", err
 	}
 	log.Warning("Current Balance:", currentBalance)
-	fee, gasPrice, gas, err := c.GetEstimatedFee(fromAddress, to, "", currentBalance)
+	txFee, err := c.GetEstimatedTxFee(from, to, "", currentBalance)
 	if err != nil {
 		return "", err
 	}
+	fee := txFee.MaxFee()
 	amountToTransfer := new(big.Int).Sub(currentBalance, fee)
 	log.Warning("Estimated Fee:", fee)
 	log.Warning("Amount to transfer:", amountToTransfer)
 	if amountToTransfer.Cmp(big.NewInt(0)) <= 0 {
 		return "", ErrNothingToTransfer
 	}
-	return c.sendRawByPrivateKeyUnsafe(fromPrivateKey, from, to, amountToTransfer, gasPrice, gas)
+	return c.sendRawBySignerUnsafe(signer, from, to, amountToTransfer, nil, txFee)
 }
 
 func (c *Client) TransferGetEstimatedFee(from, to string, amount *big.Int) (fee *big.Int, err error) {
-	fee, _, _, err = c.GetEstimatedFee(from, to, "", amount)
+	txFee, err := c.GetEstimatedTxFee(from, to, "", amount)
 	if err != nil {
 		return nil, err
 	}
-	return fee, nil
-}
-
-func (c *Client) TransferTokenByPrivateKey(fromPrivateKey []byte, from, to string, amount *big.Int, token string) (txHash string, err error) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (c *Client) TransferAllTokenByPrivateKey(fromPrivateKey []byte, from, to string, token string) (txHash string, err error) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (c *Client) TransferTokenGetEstimatedFee(from, to string, amount *big.Int, token string) (fee *big.Int, err error) {
-	//TODO implement me
-	panic("implement me")
+	return txFee.ExpectedFee(), nil
 }
 
 func _isProtoInSlice(proto string, slice []string) bool {
@@ -0,0 +1,84 @@
+package ethclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ITProLabDev/ethbacknode/clients/urpc"
+)
+
+// nodeMethod answers a JSON-RPC call of the test node, a non nil rpcErr is
+// returned as the JSON-RPC error.
+type nodeMethod func(params []json.RawMessage) (result interface{}, rpcErr *urpc.Error)
+
+// testNode is a JSON-RPC node serving the given methods, it records the calls.
+type testNode struct {
+	mux     sync.Mutex
+	methods map[string]nodeMethod
+	calls   map[string]int
+}
+
+func (n *testNode) called(method string) int {
+	n.mux.Lock()
+	defer n.mux.Unlock()
+	return n.calls[method]
+}
+
+// newTestClient starts the test node and returns a client connected to it.
+func newTestClient(t *testing.T, methods map[string]nodeMethod) (*Client, *testNode) {
+	t.Helper()
+	node := &testNode{methods: methods, calls: make(map[string]int)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Id     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		node.mux.Lock()
+		node.calls[request.Method]++
+		method, found := node.methods[request.Method]
+		node.mux.Unlock()
+		response := map[string]interface{}{"jsonrpc": "2.0", "id": request.Id}
+		if !found {
+			response["error"] = &urpc.Error{Code: urpc.ERROR_CODE_METHOD_NOT_FOUND, Message: "the method " + request.Method + " does not exist/is not available"}
+		} else if result, rpcErr := method(request.Params); rpcErr != nil {
+			response["error"] = rpcErr
+		} else {
+			response["result"] = result
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+	client := NewClient()
+	client.rpcClient = urpc.NewClient(urpc.WithHTTPRpc(server.URL, nil))
+	return client, node
+}
+
+func TestTransactionBlockNum(t *testing.T) {
+	var blockNumber interface{} = "0x10"
+	client, _ := newTestClient(t, map[string]nodeMethod{
+		ethGetTransactionByHash: func(params []json.RawMessage) (interface{}, *urpc.Error) {
+			var txHash string
+			if json.Unmarshal(params[0], &txHash) != nil || txHash != testTxHash {
+				return nil, nil
+			}
+			// a router call, not a transfer by its call data
+			return map[string]interface{}{"hash": testTxHash, "blockNumber": blockNumber, "input": "0x38ed1739", "to": "0x7a250d5630b4cf539739df2c5dacb4c659f2488d"}, nil
+		},
+	})
+	blockNum, err := client.TransactionBlockNum(TransferLogId(testTxHash, 2))
+	if err != nil || blockNum != 16 {
+		t.Fatalf("TransactionBlockNum: %d, %v", blockNum, err)
+	}
+	blockNumber = nil
+	if blockNum, err = client.TransactionBlockNum(testTxHash); err != nil || blockNum != 0 {
+		t.Fatalf("pending TransactionBlockNum: %d, %v", blockNum, err)
+	}
+}
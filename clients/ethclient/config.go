@@ -5,17 +5,27 @@ import (
 	"github.com/ITProLabDev/ethbacknode/storage"
 	"github.com/ITProLabDev/ethbacknode/tools/log"
 	"github.com/ITProLabDev/ethbacknode/types"
+	"math/big"
 )
 
 type Config struct {
-	storage       storage.BinStorage
-	ChainName     string
-	ChainId       string
-	ChainSymbol   string
-	Decimals      int
-	Confirmations int  `json:"confirmations"`
-	Debug         bool `json:"debug"`
-	Tokens        []*types.TokenInfo
+	storage                storage.BinStorage
+	ChainName              string
+	ChainId                string
+	ChainSymbol            string
+	Decimals               int
+	Confirmations          int  `json:"confirmations"`
+	Debug                  bool `json:"debug"`
+	TokenTransferLogs      bool `json:"tokenTransferLogs"`
+	TraceInternalTransfers bool `json:"traceInternalTransfers"`
+	// SpeedUpAfterBlocks is the number of blocks an outgoing transaction may stay
+	// pending before it is replaced with a higher fee one, 0 (default) disables the speed up
+	SpeedUpAfterBlocks int `json:"speedUpAfterBlocks"`
+	// SpeedUpPercent is the fee increase of a replacement transaction
+	SpeedUpPercent int `json:"speedUpPercent"`
+	// SpeedUpMaxGasPrice caps the gas price (max fee per gas) of replacement transactions
+	SpeedUpMaxGasPrice *big.Int `json:"speedUpMaxGasPrice,omitempty"`
+	Tokens             []*types.TokenInfo
 }
 
 func _configDefaultStorage() storage.BinStorage {
@@ -38,6 +48,15 @@ func (c *Config) Load() (err error) {
 		return
 	}
 	err = json.Unmarshal(jsonBytes, c)
+	if err != nil {
+		return
+	}
+	if c.SpeedUpPercent == 0 {
+		c.SpeedUpPercent = defaultSpeedUpPercent
+	} else if c.SpeedUpPercent < minReplacementBump {
+		// nodes reject replacements with a lower fee increase
+		c.SpeedUpPercent = minReplacementBump
+	}
 	return
 }
 
@@ -59,6 +78,9 @@ func (c *Config) coldStart() (err error) {
 	c.ChainSymbol = "ETH"
 	c.Decimals = 18
 	c.Confirmations = 20
+	c.TokenTransferLogs = true
+	c.SpeedUpPercent = defaultSpeedUpPercent
+	c.SpeedUpMaxGasPrice = big.NewInt(200_000_000_000)
 	c.Tokens = []*types.TokenInfo{
 		{
 			Name:            "TetherToken",
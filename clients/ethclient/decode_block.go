@@ -15,23 +15,44 @@ func (c *Client) blockDecode(block *Block) (blockInfo *types.BlockInfo, err erro
 		Timestamp:  block.Timestamp,
 	}
 	if block.FullTransactions {
+		var decodedInternal []*Transaction
 		for _, txInternal := range block.transactionsFullDecoded {
-			txDecoded, err := c.transactionDecode(txInternal)
+			txDecoded, err := c.transactionDecode(txInternal, block.BaseFeePerGas)
 			if err != nil &&
 				!errors.Is(err, ErrTransactionNotTransfer) &&
 				!errors.Is(err, ErrUnsupportedTransactionType) &&
 				!errors.Is(err, abi.ErrUnknownContract) &&
 				!errors.Is(err, ErrUnsupportedTransactionFormat) {
 				return nil, err
+			} else if err == nil && c.skipCallDataTokenTransfer(txDecoded) {
+				// token transfers are taken from Transfer logs below
+				continue
 			} else if err == nil {
 				txDecoded.InPool = false
 				txDecoded.Timestamp = block.Timestamp
 				txDecoded.BlockNum = blockDecoded.Number
 				blockDecoded.Transactions = append(blockDecoded.Transactions, txDecoded)
+				decodedInternal = append(decodedInternal, txInternal)
 			} else {
 				log.Debug("Skipping transaction:", err)
 			}
 		}
+		tokenTransfers, err := c.blockTokenTransfers(block)
+		if err != nil {
+			return nil, err
+		}
+		if len(decodedInternal) != 0 || len(tokenTransfers) != 0 {
+			err = c.blockApplyReceipts(block, blockDecoded.Transactions, decodedInternal, tokenTransfers)
+			if err != nil {
+				return nil, err
+			}
+		}
+		blockDecoded.Transactions = append(blockDecoded.Transactions, tokenTransfers...)
+		internalTransfers, err := c.blockInternalTransfers(block)
+		if err != nil {
+			return nil, err
+		}
+		blockDecoded.Transactions = append(blockDecoded.Transactions, internalTransfers...)
 	} else {
 		blockDecoded.Transactions = make([]*types.TransferInfo, len(block.Transactions))
 		for i, txHash := range block.transactionsHashesDecoded {
@@ -42,3 +63,50 @@ func (c *Client) blockDecode(block *Block) (blockInfo *types.BlockInfo, err erro
 	}
 	return blockDecoded, nil
 }
+
+// blockApplyReceipts loads receipts of the decoded transactions and of the
+// transactions containing token transfer logs, and sets status and exact fees.
+func (c *Client) blockApplyReceipts(block *Block, decoded []*types.TransferInfo, decodedInternal []*Transaction, tokenTransfers []*types.TransferInfo) (err error) {
+	blockTxs := make(map[string]*Transaction, len(block.transactionsFullDecoded))
+	for _, txInternal := range block.transactionsFullDecoded {
+		blockTxs[txInternal.Hash] = txInternal
+	}
+	var txHashes []string
+	for _, txInternal := range decodedInternal {
+		txHashes = append(txHashes, txInternal.Hash)
+	}
+	tokenTransferTxs := make([]string, len(tokenTransfers))
+	tokenTransferTxSeen := make(map[string]bool)
+	for i, transfer := range tokenTransfers {
+		chainData := new(TxChainData)
+		_ = chainData.Decode(transfer.ChainSpecificData)
+		tokenTransferTxs[i] = chainData.TxHash
+		if _, found := blockTxs[chainData.TxHash]; found && !tokenTransferTxSeen[chainData.TxHash] {
+			tokenTransferTxSeen[chainData.TxHash] = true
+			txHashes = append(txHashes, chainData.TxHash)
+		}
+	}
+	receipts, err := c.blockReceipts(block.Number, txHashes)
+	if err != nil {
+		return err
+	}
+	for i, txInternal := range decodedInternal {
+		receipt, found := receipts[txInternal.Hash]
+		if !found {
+			return ErrTransactionReceiptNotFound
+		}
+		transactionApplyReceipt(decoded[i], txInternal, receipt, block.BaseFeePerGas)
+	}
+	for i, transfer := range tokenTransfers {
+		txInternal, found := blockTxs[tokenTransferTxs[i]]
+		if !found {
+			continue
+		}
+		receipt, found := receipts[txInternal.Hash]
+		if !found {
+			return ErrTransactionReceiptNotFound
+		}
+		tokenTransferLogApplyReceipt(transfer, txInternal, receipt, block.BaseFeePerGas)
+	}
+	return nil
+}
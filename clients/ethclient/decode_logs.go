@@ -0,0 +1,124 @@
+package ethclient
+
+import (
+	"errors"
+	"github.com/ITProLabDev/ethbacknode/abi"
+	"github.com/ITProLabDev/ethbacknode/common/hexnum"
+	"github.com/ITProLabDev/ethbacknode/tools/log"
+	"github.com/ITProLabDev/ethbacknode/types"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// TransferLogId returns the transfer ID of a token transfer detected from a log:
+// the transaction hash and the log index in the block, separated by colon.
+func TransferLogId(txHash string, logIndex int64) string {
+	return txHash + ":" + strconv.FormatInt(logIndex, 10)
+}
+
+// blockTokenTransfers scans Transfer(address,address,uint256) logs of all known tokens
+// in the block. Each log becomes a separate transfer, so deposits made by transferFrom,
+// routers, multisigs or batch senders are detected as well as direct transfer calls.
+func (c *Client) blockTokenTransfers(block *Block) (transfers []*types.TransferInfo, err error) {
+	if !c.config.TokenTransferLogs || len(c.tokens) == 0 {
+		return nil, nil
+	}
+	topic, err := c.abi.Erc20TransferTopic()
+	if err != nil {
+		return nil, err
+	}
+	contracts := make([]string, len(c.tokens))
+	for i, token := range c.tokens {
+		contracts[i] = token.ContractAddress
+	}
+	logs, err := c.GetLogs(block.Number, block.Number, contracts, hexnum.BytesToHex(topic))
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range logs {
+		if l.Removed {
+			continue
+		}
+		tokenInfo, found := c.tokenGetIfExistByAddress(l.Address)
+		if !found {
+			continue
+		}
+		transfer, err := c.tokenTransferLogDecode(l, tokenInfo)
+		if errors.Is(err, abi.ErrNotTransferEvent) {
+			continue
+		} else if err != nil {
+			if c.config.Debug {
+				log.Error("Can not decode token transfer log:", l.TransactionHash, l.LogIndex, err)
+			}
+			continue
+		}
+		transfer.Timestamp = block.Timestamp
+		transfer.BlockNum = int(block.Number)
+		transfers = append(transfers, transfer)
+	}
+	return transfers, nil
+}
+
+// tokenTransferLogDecode converts a Transfer event log of a known token to a transfer.
+// Logs are emitted by successfully executed transactions only. The fee is zero
+// unless it is set from the receipt for transfers sent by the transaction sender.
+func (c *Client) tokenTransferLogDecode(l *Log, tokenInfo *types.TokenInfo) (transfer *types.TransferInfo, err error) {
+	topics := make([][]byte, len(l.Topics))
+	for i, topic := range l.Topics {
+		topics[i], err = hexnum.ParseHexBytes(topic)
+		if err != nil {
+			return nil, err
+		}
+	}
+	data, err := hexnum.ParseHexBytes(l.Data)
+	if err != nil {
+		return nil, err
+	}
+	from, to, amount, err := c.abi.Erc20DecodeTransferLog(topics, data)
+	if err != nil {
+		return nil, err
+	}
+	chainData := &TxChainData{
+		TxHash:   l.TransactionHash,
+		LogIndex: l.LogIndex,
+	}
+	return &types.TransferInfo{
+		TxID:              TransferLogId(l.TransactionHash, l.LogIndex),
+		Success:           true,
+		SmartContract:     true,
+		From:              c._addressToNormal(from),
+		To:                c._addressToNormal(to),
+		Amount:            amount,
+		Token:             tokenInfo.Name,
+		TokenSymbol:       tokenInfo.Symbol,
+		Decimals:          tokenInfo.Decimals,
+		Fee:               new(big.Int),
+		ChainSpecificData: chainData.encode(),
+	}, nil
+}
+
+// tokenTransferLogApplyReceipt sets the fee of a log transfer if the transfer
+// was sent by the transaction sender, who paid for the transaction.
+// baseFee is the base fee of the including block.
+func tokenTransferLogApplyReceipt(transfer *types.TransferInfo, tx *Transaction, receipt *Receipt, baseFee *big.Int) {
+	if !strings.EqualFold(transfer.From, tx.From) {
+		return
+	}
+	chainData := new(TxChainData)
+	_ = chainData.Decode(transfer.ChainSpecificData)
+	gasPrice := tx.EffectiveGasPrice(baseFee)
+	transfer.Fee = receipt.Fee(gasPrice)
+	txData := newTxChainData(tx).applyReceipt(receipt, gasPrice)
+	txData.TxHash = chainData.TxHash
+	txData.LogIndex = chainData.LogIndex
+	transfer.ChainSpecificData = txData.encode()
+}
+
+// skipCallDataTokenTransfer reports whether a token transfer decoded from the call
+// data of a mined transaction must be skipped because token transfers are detected
+// from logs instead. Mempool token transfers are still decoded from the call data,
+// the record is replaced by the log transfer once the transaction is mined.
+func (c *Client) skipCallDataTokenTransfer(txInfo *types.TransferInfo) bool {
+	return c.config.TokenTransferLogs && txInfo.SmartContract && !txInfo.NativeCoin
+}
@@ -0,0 +1,62 @@
+package ethclient
+
+import (
+	"github.com/ITProLabDev/ethbacknode/clients/urpc"
+	"github.com/ITProLabDev/ethbacknode/tools/log"
+	"github.com/ITProLabDev/ethbacknode/types"
+	"math/big"
+)
+
+// transactionApplyReceipt sets the execution status and the fee actually paid
+// (gasUsed * effectiveGasPrice) from the transaction receipt. baseFee is the base
+// fee of the including block, used when the receipt has no effectiveGasPrice.
+func transactionApplyReceipt(txInfo *types.TransferInfo, tx *Transaction, receipt *Receipt, baseFee *big.Int) {
+	gasPrice := tx.EffectiveGasPrice(baseFee)
+	txInfo.Success = receipt.IsSuccessful()
+	txInfo.Fee = receipt.Fee(gasPrice)
+	txInfo.ChainSpecificData = newTxChainData(tx).applyReceipt(receipt, gasPrice).encode()
+}
+
+// receiptBaseFee returns the base fee of the block including the transaction if
+// it is needed to compute the fee: the transaction is a dynamic fee one and the
+// node does not return effectiveGasPrice in receipts.
+func (c *Client) receiptBaseFee(tx *Transaction, receipt *Receipt) (baseFee *big.Int, err error) {
+	if !tx.IsDynamicFee() || (receipt.EffectiveGasPrice != nil && !_isZeroBigInt(receipt.EffectiveGasPrice)) {
+		return nil, nil
+	}
+	block, err := c.GetBlockByNumber(receipt.BlockNumber, false)
+	if err != nil {
+		return nil, err
+	}
+	return block.BaseFeePerGas, nil
+}
+
+// blockReceipts loads receipts for the given transactions of a block, indexed by hash.
+// Uses eth_getBlockReceipts when the node supports it, otherwise requests
+// receipts one by one. The per transaction fallback is used only after the node
+// answers "method not found", other errors are returned to the caller.
+func (c *Client) blockReceipts(blockNum int64, txHashes []string) (receipts map[string]*Receipt, err error) {
+	receipts = make(map[string]*Receipt, len(txHashes))
+	if !c.noBlockReceipts.Load() {
+		blockReceipts, err := c.GetBlockReceipts(blockNum)
+		if err == nil {
+			for _, receipt := range blockReceipts {
+				receipts[receipt.TransactionHash] = receipt
+			}
+			return receipts, nil
+		}
+		if !urpc.IsMethodNotFound(err) {
+			return nil, err
+		}
+		log.Warning("Node does not support block receipts, fallback to transaction receipts:", err)
+		c.noBlockReceipts.Store(true)
+	}
+	for _, txHash := range txHashes {
+		receipt, err := c.GetTransactionReceipt(txHash)
+		if err != nil {
+			return nil, err
+		}
+		receipts[txHash] = receipt
+	}
+	return receipts, nil
+}
@@ -0,0 +1,152 @@
+package ethclient
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ITProLabDev/ethbacknode/clients/urpc"
+)
+
+const testTxHash = "0x5e3f1b5e2d1e1c56b3e4f4fbbd3c3f5c8b3e0c8f0f8d6b0c6f7a3f2e2b1c0a01"
+
+func testReceipt(txHash string) map[string]interface{} {
+	return map[string]interface{}{
+		"transactionHash":   txHash,
+		"blockNumber":       "0x10",
+		"gasUsed":           "0x5208",
+		"effectiveGasPrice": "0x3b9aca00",
+		"status":            "0x1",
+		"logs":              []interface{}{},
+	}
+}
+
+func TestBlockReceipts_Fallback(t *testing.T) {
+	receiptMethod := func(params []json.RawMessage) (interface{}, *urpc.Error) {
+		return testReceipt(testTxHash), nil
+	}
+	tests := []struct {
+		name          string
+		blockReceipts nodeMethod
+		wantErr       bool
+		wantFallback  bool
+	}{
+		{
+			name: "supported",
+			blockReceipts: func(params []json.RawMessage) (interface{}, *urpc.Error) {
+				return []interface{}{testReceipt(testTxHash)}, nil
+			},
+		},
+		{
+			name:         "method not found",
+			wantFallback: true,
+		},
+		{
+			name: "node error",
+			blockReceipts: func(params []json.RawMessage) (interface{}, *urpc.Error) {
+				return nil, &urpc.Error{Code: -32000, Message: "header not found"}
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			methods := map[string]nodeMethod{ethGetTransactionReceipt: receiptMethod}
+			if tt.blockReceipts != nil {
+				methods[ethGetBlockReceipts] = tt.blockReceipts
+			}
+			client, node := newTestClient(t, methods)
+			receipts, err := client.blockReceipts(16, []string{testTxHash})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("blockReceipts: error expected")
+				}
+			} else if err != nil || receipts[testTxHash] == nil {
+				t.Fatalf("blockReceipts: %v, %d receipts", err, len(receipts))
+			}
+			if got := client.noBlockReceipts.Load(); got != tt.wantFallback {
+				t.Fatalf("noBlockReceipts: got %v, want %v", got, tt.wantFallback)
+			}
+			if tt.wantFallback != (node.called(ethGetTransactionReceipt) == 1) {
+				t.Fatalf("transaction receipt calls: %d", node.called(ethGetTransactionReceipt))
+			}
+		})
+	}
+}
+
+// Transaction and receipt fixtures, the including block has base fee 10 gwei.
+const (
+	testBaseFee          = "0x2540be400"
+	testDynamicFeeTxJson = `{"hash":"` + testTxHash + `","blockNumber":"0x10","from":"0x9d8a62f656a8d1615c1294fd71e9cfb3e4855a4f","to":"0x3535353535353535353535353535353535353535","gas":"0x5208","gasPrice":"0x2cb417800","maxFeePerGas":"0x6fc23ac00","maxPriorityFeePerGas":"0x77359400","input":"0x","nonce":"0x9","value":"0xde0b6b3a7640000","type":"0x2","chainId":"0x1"}`
+	testLegacyTxJson     = `{"hash":"` + testTxHash + `","blockNumber":"0x10","from":"0x9d8a62f656a8d1615c1294fd71e9cfb3e4855a4f","to":"0x3535353535353535353535353535353535353535","gas":"0x7530","gasPrice":"0x4a817c800","input":"0x","nonce":"0x9","value":"0xde0b6b3a7640000","type":"0x0"}`
+	testReceiptJson      = `{"transactionHash":"` + testTxHash + `","blockNumber":"0x10","gasUsed":"0x5208","effectiveGasPrice":"0x2cb417800","status":"0x1","type":"0x2","logs":[]}`
+	testRevertedJson     = `{"transactionHash":"` + testTxHash + `","blockNumber":"0x10","gasUsed":"0x6000","effectiveGasPrice":"0x4a817c800","status":"0x0","type":"0x0","logs":[]}`
+	testNoEffectivePrice = `{"transactionHash":"` + testTxHash + `","blockNumber":"0x10","gasUsed":"0x5208","status":"0x1","type":"0x2","logs":[]}`
+	testDynamicFeeTxFee  = "252000000000000" // 21000 * (10 gwei base fee + 2 gwei tip)
+	testRevertedTxFee    = "491520000000000" // 24576 * 20 gwei
+)
+
+func TestTransactionApplyReceipt(t *testing.T) {
+	baseFee, _ := new(big.Int).SetString(testBaseFee[2:], 16)
+	tests := []struct {
+		name        string
+		tx          string
+		receipt     string
+		wantSuccess bool
+		wantFee     string
+	}{
+		{"dynamic fee", testDynamicFeeTxJson, testReceiptJson, true, testDynamicFeeTxFee},
+		{"reverted", testLegacyTxJson, testRevertedJson, false, testRevertedTxFee},
+		{"no effectiveGasPrice", testDynamicFeeTxJson, testNoEffectivePrice, true, testDynamicFeeTxFee},
+	}
+	client := NewClient()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, receipt := new(Transaction), new(Receipt)
+			if err := json.Unmarshal([]byte(tt.tx), tx); err != nil {
+				t.Fatalf("transaction: %v", err)
+			}
+			if err := json.Unmarshal([]byte(tt.receipt), receipt); err != nil {
+				t.Fatalf("receipt: %v", err)
+			}
+			txInfo, err := client.transactionDecode(tx, baseFee)
+			if err != nil {
+				t.Fatalf("transactionDecode: %v", err)
+			}
+			transactionApplyReceipt(txInfo, tx, receipt, baseFee)
+			if txInfo.Success != tt.wantSuccess {
+				t.Fatalf("success: got %v, want %v", txInfo.Success, tt.wantSuccess)
+			}
+			if txInfo.Fee.String() != tt.wantFee {
+				t.Fatalf("fee: got %s, want %s", txInfo.Fee, tt.wantFee)
+			}
+		})
+	}
+}
+
+func TestTransferInfoByHash_BlockBaseFee(t *testing.T) {
+	client, node := newTestClient(t, map[string]nodeMethod{
+		ethGetTransactionByHash: func(params []json.RawMessage) (interface{}, *urpc.Error) {
+			return json.RawMessage(testDynamicFeeTxJson), nil
+		},
+		ethGetTransactionReceipt: func(params []json.RawMessage) (interface{}, *urpc.Error) {
+			return json.RawMessage(testNoEffectivePrice), nil
+		},
+		ethGetBlockByNumber: func(params []json.RawMessage) (interface{}, *urpc.Error) {
+			return map[string]interface{}{"number": "0x10", "baseFeePerGas": testBaseFee, "transactions": []string{testTxHash}}, nil
+		},
+		ethGetBlockNumber: func(params []json.RawMessage) (interface{}, *urpc.Error) {
+			return "0x20", nil
+		},
+	})
+	txInfo, err := client.TransferInfoByHash(testTxHash)
+	if err != nil {
+		t.Fatalf("TransferInfoByHash: %v", err)
+	}
+	if txInfo.Fee.String() != testDynamicFeeTxFee {
+		t.Fatalf("fee: got %s, want %s", txInfo.Fee, testDynamicFeeTxFee)
+	}
+	if node.called(ethGetBlockByNumber) != 1 {
+		t.Fatalf("block requests: %d", node.called(ethGetBlockByNumber))
+	}
+}
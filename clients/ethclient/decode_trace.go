@@ -0,0 +1,83 @@
+package ethclient
+
+import (
+	"github.com/ITProLabDev/ethbacknode/types"
+	"math/big"
+	"strconv"
+)
+
+const (
+	callTypeCall         = "CALL"
+	callTypeSelfDestruct = "SELFDESTRUCT"
+)
+
+// TransferCallId returns the transfer ID of an internal native coin transfer detected
+// by call tracing: the transaction hash and the call index in the transaction call tree.
+func TransferCallId(txHash string, callIndex int64) string {
+	return txHash + ":call:" + strconv.FormatInt(callIndex, 10)
+}
+
+// blockInternalTransfers traces the block with the callTracer and returns the value
+// bearing internal calls as native coin transfers. Top level calls are skipped, they
+// are decoded from the block transactions. Filtering by known addresses is done by the watchdog.
+func (c *Client) blockInternalTransfers(block *Block) (transfers []*types.TransferInfo, err error) {
+	if !c.config.TraceInternalTransfers {
+		return nil, nil
+	}
+	traces, err := c.TraceBlockByNumber(block.Number)
+	if err != nil {
+		return nil, err
+	}
+	for i, trace := range traces {
+		if trace.Result == nil || trace.Result.Error != "" {
+			continue
+		}
+		txHash := trace.TxHash
+		if txHash == "" && i < len(block.transactionsFullDecoded) {
+			txHash = block.transactionsFullDecoded[i].Hash
+		}
+		if txHash == "" {
+			continue
+		}
+		var callIndex int64
+		c.callFrameTransfers(trace.Result.Calls, txHash, &callIndex, func(transfer *types.TransferInfo) {
+			transfer.Timestamp = block.Timestamp
+			transfer.BlockNum = int(block.Number)
+			transfers = append(transfers, transfer)
+		})
+	}
+	return transfers, nil
+}
+
+// callFrameTransfers walks the call tree depth first. Every nested call gets the next
+// call index, so transfer IDs are stable for the same trace. Failed calls are skipped
+// together with their nested calls, because their state changes are reverted.
+func (c *Client) callFrameTransfers(calls []*CallFrame, txHash string, callIndex *int64, emit func(transfer *types.TransferInfo)) {
+	for _, call := range calls {
+		*callIndex++
+		if call.Error != "" {
+			continue
+		}
+		if (call.Type == callTypeCall || call.Type == callTypeSelfDestruct) &&
+			call.Value != nil && call.Value.Sign() > 0 {
+			chainData := &TxChainData{
+				TxHash:    txHash,
+				CallIndex: *callIndex,
+			}
+			emit(&types.TransferInfo{
+				TxID:              TransferCallId(txHash, *callIndex),
+				Transfer:          true,
+				Success:           true,
+				NativeCoin:        true,
+				From:              c._addressToNormal(call.From),
+				To:                c._addressToNormal(call.To),
+				Amount:            new(big.Int).Set(call.Value),
+				Symbol:            c.chainSymbol,
+				Decimals:          c.decimals,
+				Fee:               new(big.Int),
+				ChainSpecificData: chainData.encode(),
+			})
+		}
+		c.callFrameTransfers(call.Calls, txHash, callIndex, emit)
+	}
+}
@@ -8,18 +8,25 @@ import (
 	"time"
 )
 
-func (c *Client) transactionDecode(tx *Transaction) (txInfo *types.TransferInfo, err error) {
-	fee := new(big.Int).Mul(tx.GasPrice, new(big.Int).SetUint64(uint64(tx.Gas)))
+// transactionDecode converts a transaction to types.TransferInfo. Legacy and all
+// typed transactions are supported; baseFee is the base fee of the including block
+// (nil for mempool transactions) and is used to compute the effective fee of
+// dynamic fee transactions. The fee is refined later from the receipt.
+func (c *Client) transactionDecode(tx *Transaction, baseFee *big.Int) (txInfo *types.TransferInfo, err error) {
+	fee := new(big.Int).Mul(tx.EffectiveGasPrice(baseFee), new(big.Int).SetUint64(uint64(tx.Gas)))
+	fee.Add(fee, tx.MaxBlobFee())
 	txInfo = &types.TransferInfo{
-		TxID:      tx.Hash,
-		BlockNum:  int(tx.BlockNumber),
-		Success:   true,
-		Timestamp: time.Now().Unix(),
-		From:      c._addressToNormal(tx.From),
-		To:        c._addressToNormal(tx.To),
-		Fee:       fee,
-		//todo save chain specific data
-		//ChainSpecificData:
+		TxID:              tx.Hash,
+		BlockNum:          int(tx.BlockNumber),
+		Success:           true,
+		Timestamp:         time.Now().Unix(),
+		From:              c._addressToNormal(tx.From),
+		To:                c._addressToNormal(tx.To),
+		Fee:               fee,
+		ChainSpecificData: newTxChainData(tx).encode(),
+	}
+	if tx.Value == nil {
+		tx.Value = new(big.Int)
 	}
 	if (tx.Input == "0x" || len(tx.Input) == 0) && tx.Value.Cmp(new(big.Int)) == 1 {
 		txInfo.Transfer = true
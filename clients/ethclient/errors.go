@@ -5,6 +5,8 @@ import "errors"
 var (
 	ErrHashesOnlyBlockHash          = errors.New("only transactions hashes requested")
 	ErrTransactionNotFound          = errors.New("transaction not found")
+	ErrBlockNotFound                = errors.New("block not found")
+	ErrTransactionReceiptNotFound   = errors.New("transaction receipt not found")
 	ErrInvalidAddressCheckSum       = errors.New("invalid address checksum")
 	ErrInvalidAddress               = errors.New("invalid address")
 	ErrTransactionNotTransfer       = errors.New("transaction not transfer")
@@ -12,7 +14,13 @@ var (
 	ErrUnsupportedTransactionType   = errors.New("unsupported transaction type")
 	ErrTransactionSignError         = errors.New("transaction sign error")
 	ErrInsufficientFunds            = errors.New("insufficient funds")
+	ErrInsufficientTokenFunds       = errors.New("insufficient token funds")
+	ErrInsufficientGasFunds         = errors.New("insufficient funds for gas")
 	ErrNothingToTransfer            = errors.New("nothing to transfer")
 	ErrConfigStorageEmpty           = errors.New("config storage is empty")
 	ErrUnknownToken                 = errors.New("unknown token")
+	ErrTransactionNotPending        = errors.New("transaction is not pending")
+	ErrFeeCapExceeded               = errors.New("replacement fee exceeds max gas price")
+	ErrPrivateKeyMismatch           = errors.New("private key does not match transaction sender")
+	ErrClientStopped                = errors.New("client stopped")
 )
@@ -0,0 +1,156 @@
+package ethclient
+
+import (
+	"github.com/ITProLabDev/ethbacknode/tools/log"
+	"math/big"
+)
+
+const (
+	// baseFeeMultiplier is the headroom applied to the current base fee in maxFeePerGas,
+	// so the transaction stays includable while the base fee grows for several blocks
+	baseFeeMultiplier = 2
+	// defaultPriorityFeePerGas is used when the node can not suggest a priority fee (1 gwei)
+	defaultPriorityFeePerGas = 1_000_000_000
+	// minReplacementBump is the minimal fee increase in percent the nodes accept
+	// for a transaction replacing a pending one with the same nonce
+	minReplacementBump = 10
+	// defaultSpeedUpPercent is the default fee increase of a replacement transaction
+	defaultSpeedUpPercent = 15
+	// transferGas is the gas used by a plain native coin transfer
+	transferGas = 21000
+)
+
+// TxFee holds the gas parameters of an outgoing transaction.
+// Legacy transactions use GasPrice, EIP-1559 transactions use
+// MaxFeePerGas and MaxPriorityFeePerGas.
+type TxFee struct {
+	Gas                  int64
+	GasPrice             *big.Int
+	BaseFeePerGas        *big.Int
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+}
+
+// IsDynamic returns true if the fee is for an EIP-1559 transaction.
+func (f *TxFee) IsDynamic() bool {
+	return f.MaxFeePerGas != nil
+}
+
+// MaxFee returns the maximum amount that can be charged for the transaction.
+// The sender balance must cover it in addition to the transferred value.
+func (f *TxFee) MaxFee() *big.Int {
+	if f.IsDynamic() {
+		return new(big.Int).Mul(f.MaxFeePerGas, big.NewInt(f.Gas))
+	}
+	return new(big.Int).Mul(f.GasPrice, big.NewInt(f.Gas))
+}
+
+// ExpectedFee returns the fee expected to be paid at the current base fee.
+func (f *TxFee) ExpectedFee() *big.Int {
+	if !f.IsDynamic() {
+		return f.MaxFee()
+	}
+	price := new(big.Int).Add(f.BaseFeePerGas, f.MaxPriorityFeePerGas)
+	if price.Cmp(f.MaxFeePerGas) > 0 {
+		price = f.MaxFeePerGas
+	}
+	return new(big.Int).Mul(price, big.NewInt(f.Gas))
+}
+
+// GetEstimatedTxFee estimates the gas parameters of a transaction.
+// If the latest block has BaseFeePerGas (London fork is active), EIP-1559 fee
+// parameters are returned, otherwise the legacy gas price is used.
+func (c *Client) GetEstimatedTxFee(from, to, data string, amount *big.Int) (fee *TxFee, err error) {
+	gas, err := c.GetEstimatedGas(from, to, data, amount)
+	if err != nil {
+		return nil, err
+	}
+	log.Warning("Estimated Gas:", gas)
+	return c.gasFee(gas)
+}
+
+// gasFee returns the current network fee parameters for the given gas limit.
+func (c *Client) gasFee(gas int64) (fee *TxFee, err error) {
+	fee = &TxFee{Gas: gas}
+	latestBlock, err := c.GetLatestBlock()
+	if err != nil {
+		return nil, err
+	}
+	if latestBlock.BaseFeePerGas == nil {
+		fee.GasPrice, err = c.GetEstimatedGasPrice()
+		if err != nil {
+			return nil, err
+		}
+		log.Warning("Estimated Gas Price:", fee.GasPrice)
+		return fee, nil
+	}
+	fee.BaseFeePerGas = latestBlock.BaseFeePerGas
+	fee.MaxPriorityFeePerGas, err = c.GetMaxPriorityFeePerGas()
+	if err != nil {
+		log.Warning("Can not get max priority fee, use default:", err)
+		fee.MaxPriorityFeePerGas = big.NewInt(defaultPriorityFeePerGas)
+	}
+	fee.MaxFeePerGas = new(big.Int).Mul(fee.BaseFeePerGas, big.NewInt(baseFeeMultiplier))
+	fee.MaxFeePerGas.Add(fee.MaxFeePerGas, fee.MaxPriorityFeePerGas)
+	log.Warning("Base Fee:", fee.BaseFeePerGas, "Max Fee:", fee.MaxFeePerGas, "Priority Fee:", fee.MaxPriorityFeePerGas)
+	return fee, nil
+}
+
+// bumpFee returns the fee of a transaction replacing a pending one with the fee.
+// The fee is raised by percent but not below the current network fee, and capped
+// at maxGasPrice if set. ErrFeeCapExceeded is returned when the capped fee is too
+// low for the nodes to accept the replacement.
+func (c *Client) bumpFee(fee *TxFee, gas int64, percent int, maxGasPrice *big.Int) (bumped *TxFee, err error) {
+	current, err := c.gasFee(gas)
+	if err != nil {
+		return nil, err
+	}
+	bumped = &TxFee{Gas: gas}
+	if !fee.IsDynamic() {
+		currentPrice := current.GasPrice
+		if current.IsDynamic() {
+			currentPrice = current.MaxFeePerGas
+		}
+		bumped.GasPrice = _bigMax(_percentUp(fee.GasPrice, percent), currentPrice)
+		if maxGasPrice != nil && bumped.GasPrice.Cmp(maxGasPrice) > 0 {
+			bumped.GasPrice = new(big.Int).Set(maxGasPrice)
+		}
+		if bumped.GasPrice.Cmp(_percentUp(fee.GasPrice, minReplacementBump)) < 0 {
+			return nil, ErrFeeCapExceeded
+		}
+		return bumped, nil
+	}
+	currentMaxFee, currentTip := current.MaxFeePerGas, current.MaxPriorityFeePerGas
+	bumped.BaseFeePerGas = current.BaseFeePerGas
+	if !current.IsDynamic() {
+		currentMaxFee, currentTip = current.GasPrice, current.GasPrice
+		bumped.BaseFeePerGas = fee.BaseFeePerGas
+	}
+	bumped.MaxFeePerGas = _bigMax(_percentUp(fee.MaxFeePerGas, percent), currentMaxFee)
+	bumped.MaxPriorityFeePerGas = _bigMax(_percentUp(fee.MaxPriorityFeePerGas, percent), currentTip)
+	if maxGasPrice != nil && bumped.MaxFeePerGas.Cmp(maxGasPrice) > 0 {
+		bumped.MaxFeePerGas = new(big.Int).Set(maxGasPrice)
+	}
+	if bumped.MaxPriorityFeePerGas.Cmp(bumped.MaxFeePerGas) > 0 {
+		bumped.MaxPriorityFeePerGas = new(big.Int).Set(bumped.MaxFeePerGas)
+	}
+	if bumped.MaxFeePerGas.Cmp(_percentUp(fee.MaxFeePerGas, minReplacementBump)) < 0 ||
+		bumped.MaxPriorityFeePerGas.Cmp(_percentUp(fee.MaxPriorityFeePerGas, minReplacementBump)) < 0 {
+		return nil, ErrFeeCapExceeded
+	}
+	return bumped, nil
+}
+
+// _percentUp returns value increased by percent, rounded up.
+func _percentUp(value *big.Int, percent int) *big.Int {
+	result := new(big.Int).Mul(value, big.NewInt(int64(100+percent)))
+	result.Add(result, big.NewInt(99))
+	return result.Div(result, big.NewInt(100))
+}
+
+func _bigMax(a, b *big.Int) *big.Int {
+	if b != nil && b.Cmp(a) > 0 {
+		return new(big.Int).Set(b)
+	}
+	return new(big.Int).Set(a)
+}
@@ -12,6 +12,7 @@ const (
 	ethGetBalance                          = "eth_getBalance"
 	ethGetTransactionByHash                = "eth_getTransactionByHash"
 	ethGetTransactionReceipt               = "eth_getTransactionReceipt"
+	ethGetBlockReceipts                    = "eth_getBlockReceipts"
 	ethGetTransactionByBlockHashAndIndex   = "eth_getTransactionByBlockHashAndIndex"
 	ethGetTransactionByBlockNumberAndIndex = "eth_getTransactionByBlockNumberAndIndex"
 	ethGetBlockNumber                      = "eth_blockNumber"
@@ -19,14 +20,19 @@ const (
 	ethGetBlockByNumber                    = "eth_getBlockByNumber"
 	ethEstimateGas                         = "eth_estimateGas"
 	ethGasPrice                            = "eth_gasPrice"
+	ethMaxPriorityFeePerGas                = "eth_maxPriorityFeePerGas"
 	ethSendRawTransaction                  = "eth_sendRawTransaction"
 	ethGetTransactionCount                 = "eth_getTransactionCount"
 	ethCall                                = "eth_call"
+	ethGetLogs                             = "eth_getLogs"
+	debugTraceBlockByNumber                = "debug_traceBlockByNumber"
 	txpoolСontent                          = "txpool_content"
+	txpoolContentFrom                      = "txpool_contentFrom"
 
 	web3Version = "web3_version"
 
-	tagBlockLatest = "latest"
+	tagBlockLatest  = "latest"
+	tagBlockPending = "pending"
 )
 
 func (c *Client) GetNetId() (netId int64, err error) {
@@ -69,6 +75,23 @@ func (c *Client) GetBalance(address string) (*big.Int, error) {
 	return balance, nil
 }
 
+// GetBalanceByBlockNumber returns the balance in wei of the account of given address
+// at the given block.
+func (c *Client) GetBalanceByBlockNumber(address string, blockNumber int64) (*big.Int, error) {
+	req := urpc.NewRequest(ethGetBalance)
+	req.AddParams(address, hexnum.Int64ToHex(blockNumber))
+	result, err := c.rpcClient.Call(req)
+	if err != nil {
+		return nil, err
+	}
+	var balanceStr string
+	err = result.ParseResult(&balanceStr)
+	if err != nil {
+		return nil, err
+	}
+	return hexnum.ParseBigInt(balanceStr)
+}
+
 // GasPrice returns the current price per gas in wei.
 // The gas price is determined by the last few blocks
 // median gas price. You need know the gas price for
@@ -111,6 +134,47 @@ func (c *Client) GetTransactionByHash(hash string) (*Transaction, error) {
 	return tx, nil
 }
 
+// GetTransactionReceipt returns the receipt of a mined transaction.
+// If the transaction is not mined yet (geth rpc call return null), it returns error.
+func (c *Client) GetTransactionReceipt(hash string) (*Receipt, error) {
+	req := urpc.NewRequest(ethGetTransactionReceipt)
+	req.SetParams(hash)
+	result, err := c.rpcClient.Call(req)
+	if err != nil {
+		return nil, err
+	}
+	if result.Result == nil || string(result.Result) == "null" {
+		return nil, ErrTransactionReceiptNotFound
+	}
+	receipt := new(Receipt)
+	err = result.ParseResult(receipt)
+	if err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// GetBlockReceipts returns receipts of all transactions in the block.
+// Not every node supports eth_getBlockReceipts, callers should fall back
+// to GetTransactionReceipt on error.
+func (c *Client) GetBlockReceipts(number int64) ([]*Receipt, error) {
+	req := urpc.NewRequest(ethGetBlockReceipts)
+	req.AddParams(hexnum.Int64ToHex(number))
+	result, err := c.rpcClient.Call(req)
+	if err != nil {
+		return nil, err
+	}
+	if result.Result == nil || string(result.Result) == "null" {
+		return nil, ErrTransactionReceiptNotFound
+	}
+	var receipts []*Receipt
+	err = result.ParseResult(&receipts)
+	if err != nil {
+		return nil, err
+	}
+	return receipts, nil
+}
+
 // GetTransactionByBlockHashAndIndex returns the information about a transaction requested by
 // block hash and tx index. If the transaction not found (geth rpc call return null),
 // it returns error.
@@ -173,6 +237,7 @@ func (c *Client) GetBlockNumber() (int64, error) {
 }
 
 // GetBlockByHash returns information about a block by hash.
+// If the block is unknown to the node (geth rpc call return null), it returns ErrBlockNotFound.
 // If "fullTransactions" is true it returns the full transaction objects,
 // if "fullTransactions" is false, only the hashes of the transactions.
 func (c *Client) GetBlockByHash(hash string, fullTransactions bool) (*Block, error) {
@@ -182,6 +247,9 @@ func (c *Client) GetBlockByHash(hash string, fullTransactions bool) (*Block, err
 	if err != nil {
 		return nil, err
 	}
+	if result.Result == nil || string(result.Result) == "null" {
+		return nil, ErrBlockNotFound
+	}
 	block := new(Block)
 	block.FullTransactions = fullTransactions
 	err = result.ParseResult(block)
@@ -201,6 +269,9 @@ func (c *Client) GetBlockByNumber(number int64, fullTransactions bool) (*Block,
 	if err != nil {
 		return nil, err
 	}
+	if result.Result == nil || string(result.Result) == "null" {
+		return nil, ErrBlockNotFound
+	}
 	block := new(Block)
 	block.FullTransactions = fullTransactions
 	err = result.ParseResult(block)
@@ -210,6 +281,22 @@ func (c *Client) GetBlockByNumber(number int64, fullTransactions bool) (*Block,
 	return block, nil
 }
 
+// GetLatestBlock returns the latest block header, only the hashes of the transactions are included.
+func (c *Client) GetLatestBlock() (*Block, error) {
+	req := urpc.NewRequest(ethGetBlockByNumber)
+	req.AddParams(tagBlockLatest, false)
+	result, err := c.rpcClient.Call(req)
+	if err != nil {
+		return nil, err
+	}
+	block := new(Block)
+	err = result.ParseResult(block)
+	if err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
 // SendRawTransaction sends the signed and RPL encoded transaction to the network.
 // In fact, any transaction - transfer of funds, call of a smart contract function
 // or deployment of a smart contract is carried out by calling this function
@@ -279,6 +366,51 @@ func (c *Client) CallByBlockNumber(contractAddress, data string, blockNumber int
 	return callResult, nil
 }
 
+// logFilter is the filter object of eth_getLogs request.
+type logFilter struct {
+	FromBlock string   `json:"fromBlock"`
+	ToBlock   string   `json:"toBlock"`
+	Address   []string `json:"address,omitempty"`
+	Topics    []string `json:"topics,omitempty"`
+}
+
+// GetLogs returns logs of the blocks range emitted by any of the given contracts
+// and matching the first topic (event signature).
+func (c *Client) GetLogs(fromBlock, toBlock int64, contracts []string, topic string) (logs []*Log, err error) {
+	req := urpc.NewRequest(ethGetLogs)
+	req.AddParams(&logFilter{
+		FromBlock: hexnum.Int64ToHex(fromBlock),
+		ToBlock:   hexnum.Int64ToHex(toBlock),
+		Address:   contracts,
+		Topics:    []string{topic},
+	})
+	result, err := c.rpcClient.Call(req)
+	if err != nil {
+		return nil, err
+	}
+	err = result.ParseResult(&logs)
+	if err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// TraceBlockByNumber returns the call trees of all transactions in the block,
+// traced by the built-in callTracer. Requires the debug API enabled on the node.
+func (c *Client) TraceBlockByNumber(number int64) (traces []*TxTrace, err error) {
+	req := urpc.NewRequest(debugTraceBlockByNumber)
+	req.AddParams(hexnum.Int64ToHex(number), map[string]string{"tracer": callTracer})
+	result, err := c.rpcClient.Call(req)
+	if err != nil {
+		return nil, err
+	}
+	err = result.ParseResult(&traces)
+	if err != nil {
+		return nil, err
+	}
+	return traces, nil
+}
+
 // GetTxPoolContent returns the information about the transaction pool.
 // It returns two maps: pending and queued transactions.
 func (c *Client) GetTxPoolContent() (pending, queued map[string]map[string]*Transaction, err error) {
@@ -301,6 +433,26 @@ func (c *Client) GetTxPoolContent() (pending, queued map[string]map[string]*Tran
 	return txPoolContent.Pending, txPoolContent.Queued, nil
 }
 
+// GetTxPoolContentFrom returns the pending and queued transactions of the address
+// in the transaction pool, keyed by nonce.
+func (c *Client) GetTxPoolContentFrom(address string) (pending, queued map[string]*Transaction, err error) {
+	req := urpc.NewRequest(txpoolContentFrom)
+	req.AddParams(address)
+	result, err := c.rpcClient.Call(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	txPoolContent := &struct {
+		Pending map[string]*Transaction `json:"pending"`
+		Queued  map[string]*Transaction `json:"queued"`
+	}{}
+	err = result.ParseResult(txPoolContent)
+	if err != nil {
+		return nil, nil, err
+	}
+	return txPoolContent.Pending, txPoolContent.Queued, nil
+}
+
 type estimateGasRequest struct {
 	FromAddress string `json:"from,omitempty"`
 	ToAddress   string `json:"to,omitempty"`
@@ -351,6 +503,26 @@ func (c *Client) GetEstimatedGasPrice() (gasPrice *big.Int, err error) {
 	return gasPrice, nil
 }
 
+// GetMaxPriorityFeePerGas returns the priority fee (tip) per gas suggested by the node
+// for EIP-1559 transactions.
+func (c *Client) GetMaxPriorityFeePerGas() (tip *big.Int, err error) {
+	req := urpc.NewRequest(ethMaxPriorityFeePerGas)
+	result, err := c.rpcClient.Call(req)
+	if err != nil {
+		return nil, err
+	}
+	var tipStr string
+	err = result.ParseResult(&tipStr)
+	if err != nil {
+		return nil, err
+	}
+	tip, err = hexnum.ParseBigInt(tipStr)
+	if err != nil {
+		return nil, err
+	}
+	return tip, nil
+}
+
 func (c *Client) GetEstimatedFee(from, to, data string, amount *big.Int) (fee, gasPrice *big.Int, gas int64, err error) {
 	gas, err = c.GetEstimatedGas(from, to, data, amount)
 	if err != nil {
@@ -366,9 +538,19 @@ func (c *Client) GetEstimatedFee(from, to, data string, amount *big.Int) (fee, g
 	return fee, gasPrice, gas, nil
 }
 
+// PendingNonceAt returns the next nonce of the address including the transactions in the node mempool.
 func (c *Client) PendingNonceAt(address string) (nonce int64, err error) {
+	return c.nonceAt(address, tagBlockPending)
+}
+
+// LatestNonceAt returns the next nonce of the address counting only the mined transactions.
+func (c *Client) LatestNonceAt(address string) (nonce int64, err error) {
+	return c.nonceAt(address, tagBlockLatest)
+}
+
+func (c *Client) nonceAt(address, blockTag string) (nonce int64, err error) {
 	req := urpc.NewRequest(ethGetTransactionCount)
-	req.AddParams(address, "pending")
+	req.AddParams(address, blockTag)
 	result, err := c.rpcClient.Call(req)
 	if err != nil {
 		return 0, err
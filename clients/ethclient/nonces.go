@@ -0,0 +1,396 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ITProLabDev/ethbacknode/clients/urpc"
+	"github.com/ITProLabDev/ethbacknode/storage"
+	"github.com/ITProLabDev/ethbacknode/tools/log"
+	"github.com/timshannon/badgerhold"
+)
+
+// NonceRecord is the nonce allocation state of a sending address.
+// Next is the nonce the next transaction gets, Pending holds the allocated
+// nonces which are not mined yet.
+type NonceRecord struct {
+	Address   string          `json:"address"`
+	Next      int64           `json:"next"`
+	Pending   []*PendingNonce `json:"pending"`
+	UpdatedAt int64           `json:"updatedAt"`
+}
+
+// PendingNonce is an allocated nonce with the signed transaction sent with it.
+// The transaction fields are empty until the transaction is accepted by the node.
+// When the transaction is replaced with a higher fee one, the replaced hashes are
+// kept in Replaced, SentBlock is the block the current transaction was first seen
+// pending at.
+type PendingNonce struct {
+	Nonce     int64    `json:"nonce"`
+	TxHash    string   `json:"txHash,omitempty"`
+	RawTx     string   `json:"rawTx,omitempty"`
+	To        string   `json:"to,omitempty"`
+	Value     *big.Int `json:"value,omitempty"`
+	Data      []byte   `json:"data,omitempty"`
+	Fee       *TxFee   `json:"fee,omitempty"`
+	Replaced  []string `json:"replaced,omitempty"`
+	Cancel    bool     `json:"cancel,omitempty"`
+	SentBlock int64    `json:"sentBlock,omitempty"`
+	Bumps     int      `json:"bumps,omitempty"`
+	CreatedAt int64    `json:"createdAt"`
+}
+
+// pendingNonce returns the allocated nonce entry or nil.
+func (r *NonceRecord) pendingNonce(nonce int64) *PendingNonce {
+	for _, p := range r.Pending {
+		if p.Nonce == nonce {
+			return p
+		}
+	}
+	return nil
+}
+
+// pendingByHash returns the allocated nonce entry of the transaction or of
+// the transaction replacing it, nil if the transaction is unknown.
+func (r *NonceRecord) pendingByHash(txHash string) *PendingNonce {
+	for _, p := range r.Pending {
+		if strings.EqualFold(p.TxHash, txHash) {
+			return p
+		}
+		for _, replaced := range p.Replaced {
+			if strings.EqualFold(replaced, txHash) {
+				return p
+			}
+		}
+	}
+	return nil
+}
+
+// setPending adds the allocated nonce entry, replacing the previous one with the same nonce.
+func (r *NonceRecord) setPending(pending *PendingNonce) {
+	r.removePending(pending.Nonce)
+	r.Pending = append(r.Pending, pending)
+}
+
+// removePending drops the allocated nonce entry.
+func (r *NonceRecord) removePending(nonce int64) {
+	pending := r.Pending[:0]
+	for _, p := range r.Pending {
+		if p.Nonce != nonce {
+			pending = append(pending, p)
+		}
+	}
+	r.Pending = pending
+}
+
+// nonceManager keeps the nonce allocation state of the sending addresses.
+// The records are cached in memory and persisted when storage is set,
+// so the nonces survive restart.
+type nonceManager struct {
+	storage *storage.BadgerHoldStorage
+	mux     sync.Mutex
+	locks   map[string]*sync.Mutex
+	records map[string]*NonceRecord
+	stopped bool
+	active  sync.WaitGroup // users of the storage started before stop
+}
+
+func newNonceManager() *nonceManager {
+	return &nonceManager{
+		locks:   make(map[string]*sync.Mutex),
+		records: make(map[string]*NonceRecord),
+	}
+}
+
+func nonceKey(address string) string {
+	return strings.ToLower(address)
+}
+
+// begin registers a user of the nonce storage, so stop waits for it.
+// Returns ErrClientStopped once the manager is stopped.
+func (m *nonceManager) begin() (end func(), err error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if m.stopped {
+		return nil, ErrClientStopped
+	}
+	m.active.Add(1)
+	return m.active.Done, nil
+}
+
+// lock serializes the nonce allocation and send of the address.
+// Returns ErrClientStopped once the manager is stopped.
+func (m *nonceManager) lock(address string) (unlock func(), err error) {
+	end, err := m.begin()
+	if err != nil {
+		return nil, err
+	}
+	key := nonceKey(address)
+	m.mux.Lock()
+	l, found := m.locks[key]
+	if !found {
+		l = new(sync.Mutex)
+		m.locks[key] = l
+	}
+	m.mux.Unlock()
+	l.Lock()
+	return func() {
+		l.Unlock()
+		end()
+	}, nil
+}
+
+// stop rejects new users and waits until the started ones are finished,
+// so the storage can be closed. Returns ctx.Err() if they do not finish in time.
+func (m *nonceManager) stop(ctx context.Context) (err error) {
+	m.mux.Lock()
+	m.stopped = true
+	m.mux.Unlock()
+	done := make(chan struct{})
+	go func() {
+		m.active.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// get returns the record of the address, a new one if the address never sent.
+// The caller must hold the address lock.
+func (m *nonceManager) get(address string) (record *NonceRecord, err error) {
+	key := nonceKey(address)
+	m.mux.Lock()
+	record, found := m.records[key]
+	m.mux.Unlock()
+	if found {
+		return record, nil
+	}
+	record = &NonceRecord{Address: address}
+	if m.storage != nil {
+		m.storage.Do(func(db *badgerhold.Store) {
+			err = db.Get(key, record)
+		})
+		if errors.Is(err, badgerhold.ErrNotFound) {
+			err = nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	m.mux.Lock()
+	m.records[key] = record
+	m.mux.Unlock()
+	return record, nil
+}
+
+func (m *nonceManager) save(record *NonceRecord) (err error) {
+	record.UpdatedAt = time.Now().Unix()
+	if m.storage == nil {
+		return nil
+	}
+	m.storage.Do(func(db *badgerhold.Store) {
+		err = db.Upsert(nonceKey(record.Address), record)
+	})
+	return err
+}
+
+// addresses returns the addresses with a nonce record.
+func (m *nonceManager) addresses() (addresses []string, err error) {
+	known := make(map[string]bool)
+	m.mux.Lock()
+	for key, record := range m.records {
+		known[key] = true
+		addresses = append(addresses, record.Address)
+	}
+	m.mux.Unlock()
+	if m.storage == nil {
+		return addresses, nil
+	}
+	var records []*NonceRecord
+	m.storage.Do(func(db *badgerhold.Store) {
+		err = db.Find(&records, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		if !known[nonceKey(record.Address)] {
+			addresses = append(addresses, record.Address)
+		}
+	}
+	return addresses, nil
+}
+
+// nonceAllocate returns the nonce for the next transaction of the address.
+// A nonce gap left by a dropped or failed transaction is filled first,
+// otherwise the nonce following the last allocated one is used.
+// The caller must hold the address lock.
+func (c *Client) nonceAllocate(from string) (nonce int64, err error) {
+	record, err := c.nonces.get(from)
+	if err != nil {
+		return 0, err
+	}
+	gap, err := c.nonceReconcile(record)
+	if err != nil {
+		return 0, err
+	}
+	if gap >= 0 {
+		nonce = gap
+	} else {
+		nonce = record.Next
+		record.Next++
+	}
+	record.setPending(&PendingNonce{
+		Nonce:     nonce,
+		CreatedAt: time.Now().Unix(),
+	})
+	return nonce, c.nonces.save(record)
+}
+
+// nonceCommit stores the transaction accepted by the node with the allocated nonce,
+// so it can be rebroadcast if the node drops it or replaced with a higher fee one.
+func (c *Client) nonceCommit(from string, pending *PendingNonce) {
+	record, err := c.nonces.get(from)
+	if err != nil {
+		log.Error("Can not load nonce record:", err)
+		return
+	}
+	if allocated := record.pendingNonce(pending.Nonce); allocated != nil {
+		pending.CreatedAt = allocated.CreatedAt
+	} else {
+		pending.CreatedAt = time.Now().Unix()
+	}
+	record.setPending(pending)
+	err = c.nonces.save(record)
+	if err != nil {
+		log.Error("Can not save nonce record:", err)
+	}
+}
+
+// nonceRelease returns the nonce of a transaction the node did not accept.
+// The last allocated nonce is simply reused, an earlier one becomes a gap
+// filled by the next allocation.
+func (c *Client) nonceRelease(from string, nonce int64) {
+	record, err := c.nonces.get(from)
+	if err != nil {
+		log.Error("Can not load nonce record:", err)
+		return
+	}
+	record.removePending(nonce)
+	if nonce == record.Next-1 {
+		record.Next = nonce
+	}
+	err = c.nonces.save(record)
+	if err != nil {
+		log.Error("Can not save nonce record:", err)
+	}
+}
+
+// sendRejectedErrors are the node errors of a transaction which is rejected and
+// never enters the pool, so its nonce is free to be used by the next one.
+var sendRejectedErrors = []string{
+	"nonce too low",
+	"insufficient funds",
+	"intrinsic gas too low",
+}
+
+// sendRejected reports whether the broadcast error is a definite rejection of the
+// transaction by the node. Transport errors and other node errors leave it unknown
+// whether the transaction was accepted.
+func sendRejected(err error) bool {
+	var rpcErr *urpc.WarpedError
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	message := strings.ToLower(rpcErr.Message)
+	for _, rejected := range sendRejectedErrors {
+		if strings.Contains(message, rejected) {
+			return true
+		}
+	}
+	return false
+}
+
+// nonceReconcile brings the record in line with the chain state: mined nonces
+// are dropped, nonces used outside of the client are skipped and nonce gaps are
+// repaired. A gap is an allocated nonce which is neither mined nor known by the
+// node, it blocks all the following transactions of the address. The gap is repaired
+// by rebroadcasting the stored transaction, if that is not possible the gap nonce is
+// returned to be reused, -1 is returned when there is no gap.
+func (c *Client) nonceReconcile(record *NonceRecord) (gap int64, err error) {
+	latest, err := c.LatestNonceAt(record.Address)
+	if err != nil {
+		return -1, err
+	}
+	pending, err := c.PendingNonceAt(record.Address)
+	if err != nil {
+		return -1, err
+	}
+	kept := record.Pending[:0]
+	for _, p := range record.Pending {
+		if p.Nonce >= latest {
+			kept = append(kept, p)
+		}
+	}
+	record.Pending = kept
+	if record.Next < pending {
+		record.Next = pending
+	}
+	for nonce := pending; nonce < record.Next; nonce++ {
+		p := record.pendingNonce(nonce)
+		if p != nil && p.TxHash != "" {
+			_, err = c.GetTransactionByHash(p.TxHash)
+			if err == nil {
+				continue
+			} else if !errors.Is(err, ErrTransactionNotFound) {
+				return -1, err
+			}
+		}
+		if p != nil && p.RawTx != "" {
+			_, err = c.SendRawTransaction(p.RawTx)
+			if err == nil {
+				log.Warning("Nonce gap repaired:", record.Address, "nonce", nonce, "transaction rebroadcast", p.TxHash)
+				continue
+			}
+			log.Warning("Can not rebroadcast transaction", p.TxHash, ":", err)
+		}
+		log.Warning("Nonce gap detected:", record.Address, "nonce", nonce, "will be reused")
+		record.removePending(nonce)
+		return nonce, nil
+	}
+	return -1, nil
+}
+
+// nonceReconcileAll reconciles the persisted nonces of all the sending addresses
+// against the chain state.
+func (c *Client) nonceReconcileAll() (err error) {
+	addresses, err := c.nonces.addresses()
+	if err != nil {
+		return err
+	}
+	for _, address := range addresses {
+		unlock, err := c.nonces.lock(address)
+		if err != nil {
+			return err
+		}
+		record, err := c.nonces.get(address)
+		if err == nil {
+			_, err = c.nonceReconcile(record)
+		}
+		if err == nil {
+			err = c.nonces.save(record)
+		}
+		unlock()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
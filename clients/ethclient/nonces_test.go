@@ -0,0 +1,160 @@
+package ethclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ITProLabDev/ethbacknode/clients/urpc"
+	"github.com/ITProLabDev/ethbacknode/crypto"
+)
+
+const testSender = "0x9d8a62f656a8d1615c1294fd71e9cfb3e4855a4f"
+
+func TestNonceManager_Stop(t *testing.T) {
+	m := newNonceManager()
+	unlock, err := m.lock(testSender)
+	if err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+	stopped := make(chan error, 1)
+	go func() { stopped <- m.stop(context.Background()) }()
+	select {
+	case err = <-stopped:
+		t.Fatalf("stop returned with the address locked: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if _, err = m.lock("0x3535353535353535353535353535353535353535"); !errors.Is(err, ErrClientStopped) {
+		t.Fatalf("lock after stop: got %v, want ErrClientStopped", err)
+	}
+	unlock()
+	if err = <-stopped; err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+}
+
+// newTestSendClient returns a client of a test node with no mined or pending
+// transactions, which answers the broadcast with sendErr, and the signer of the sender.
+func newTestSendClient(t *testing.T, sendErr *urpc.Error) (*Client, crypto.Signer, string) {
+	t.Helper()
+	client, _ := newTestClient(t, map[string]nodeMethod{
+		ethChainId: func(params []json.RawMessage) (interface{}, *urpc.Error) {
+			return "0x1", nil
+		},
+		ethGetTransactionCount: func(params []json.RawMessage) (interface{}, *urpc.Error) {
+			return "0x0", nil
+		},
+		ethGetTransactionByHash: func(params []json.RawMessage) (interface{}, *urpc.Error) {
+			var txHash string
+			json.Unmarshal(params[0], &txHash)
+			return map[string]interface{}{"hash": txHash}, nil
+		},
+		ethSendRawTransaction: func(params []json.RawMessage) (interface{}, *urpc.Error) {
+			if sendErr != nil {
+				return nil, sendErr
+			}
+			var rawTx string
+			json.Unmarshal(params[0], &rawTx)
+			txHash, _ := rawTxHash(rawTx)
+			return txHash, nil
+		},
+	})
+	signer, err := crypto.NewLocalSigner(bytes.Repeat([]byte{0x11}, 32))
+	if err != nil {
+		t.Fatalf("NewLocalSigner: %v", err)
+	}
+	from, err := client.signerAddress(signer)
+	if err != nil {
+		t.Fatalf("signerAddress: %v", err)
+	}
+	return client, signer, from
+}
+
+func testSendFee() *TxFee {
+	return &TxFee{Gas: 21000, GasPrice: big.NewInt(1000000000)}
+}
+
+func TestSendRaw_ConcurrentNonces(t *testing.T) {
+	client, signer, from := newTestSendClient(t, nil)
+	const sends = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, sends)
+	for i := 0; i < sends; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.sendRawBySignerUnsafe(signer, from, testSender, big.NewInt(1), nil, testSendFee())
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("sendRawBySignerUnsafe: %v", err)
+		}
+	}
+	record, err := client.nonces.get(from)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if record.Next != sends || len(record.Pending) != sends {
+		t.Fatalf("got next %d with %d pending, want %d", record.Next, len(record.Pending), sends)
+	}
+	hashes := make(map[string]bool)
+	for nonce := int64(0); nonce < sends; nonce++ {
+		p := record.pendingNonce(nonce)
+		if p == nil || p.TxHash == "" || p.RawTx == "" {
+			t.Fatalf("nonce %d not committed: %+v", nonce, p)
+		}
+		hashes[p.TxHash] = true
+	}
+	if len(hashes) != sends {
+		t.Fatalf("got %d distinct transactions, want %d", len(hashes), sends)
+	}
+}
+
+func TestSendRaw_ReleaseOrKeep(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		release bool
+	}{
+		{"nonce too low", "nonce too low: next nonce 5, tx nonce 0", true},
+		{"insufficient funds", "insufficient funds for gas * price + value", true},
+		{"intrinsic gas", "intrinsic gas too low", true},
+		{"already known", "already known", false},
+		{"timeout", "request timed out", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, signer, from := newTestSendClient(t, &urpc.Error{Code: -32000, Message: tt.message})
+			txHash, err := client.sendRawBySignerUnsafe(signer, from, testSender, big.NewInt(1), nil, testSendFee())
+			if err == nil {
+				t.Fatalf("sendRawBySignerUnsafe: no error")
+			}
+			record, err := client.nonces.get(from)
+			if err != nil {
+				t.Fatalf("get: %v", err)
+			}
+			if tt.release {
+				if txHash != "" || record.Next != 0 || len(record.Pending) != 0 {
+					t.Fatalf("nonce not released: hash %q, next %d, pending %d", txHash, record.Next, len(record.Pending))
+				}
+				return
+			}
+			p := record.pendingNonce(0)
+			if record.Next != 1 || p == nil || p.RawTx == "" {
+				t.Fatalf("nonce not kept: next %d, pending %+v", record.Next, p)
+			}
+			if wantHash, _ := rawTxHash(p.RawTx); txHash == "" || txHash != wantHash || p.TxHash != wantHash {
+				t.Fatalf("got hash %q, kept %q, want %q", txHash, p.TxHash, wantHash)
+			}
+		})
+	}
+}
@@ -37,3 +37,11 @@ func WithConfigStorage(storage storage.BinStorage) Option {
 		client.config.storage = storage
 	}
 }
+
+// WithNonceStorage sets the storage of the allocated nonces of outgoing transactions.
+// Without it the nonces are kept in memory only.
+func WithNonceStorage(storage *storage.BadgerHoldStorage) Option {
+	return func(client *Client) {
+		client.nonces.storage = storage
+	}
+}
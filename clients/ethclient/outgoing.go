@@ -0,0 +1,281 @@
+package ethclient
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ITProLabDev/ethbacknode/crypto"
+	"github.com/ITProLabDev/ethbacknode/tools/log"
+)
+
+// SignerSource returns the signer of a sending address.
+// It is used to sign the replacement transactions of the automatic speed up.
+type SignerSource func(address string) (signer crypto.Signer, err error)
+
+// SetSignerSource sets the signer source of the automatic speed up.
+// Without it the pending transactions are replaced only on request.
+func (c *Client) SetSignerSource(source SignerSource) {
+	c.signerSource = source
+}
+
+// ReplacementListener receives the hash of a replaced outgoing transaction and the
+// hash of the transaction replacing it, cancel is set if the replacement is a 0-value
+// self-send. It is called after the replacement is broadcast, for the replacements
+// on request and the automatic speed up.
+type ReplacementListener func(txHash, newTxHash string, cancel bool)
+
+// SetReplacementListener sets the listener of the outgoing transaction replacements.
+func (c *Client) SetReplacementListener(listener ReplacementListener) {
+	c.onReplace = listener
+}
+
+// replaced passes the replacement to the listener. Must be called without the address
+// lock held, so the listener may use the client.
+func (c *Client) replaced(txHash, newTxHash string, cancel bool) {
+	if c.onReplace != nil {
+		c.onReplace(txHash, newTxHash, cancel)
+	}
+}
+
+// PendingTransferFrom returns the sender of a pending outgoing transaction.
+// The transaction may be referenced by the hash of any of its replaced versions.
+func (c *Client) PendingTransferFrom(txHash string) (from string, err error) {
+	end, err := c.nonces.begin()
+	if err != nil {
+		return "", err
+	}
+	defer end()
+	addresses, err := c.nonces.addresses()
+	if err != nil {
+		return "", err
+	}
+	for _, address := range addresses {
+		unlock, err := c.nonces.lock(address)
+		if err != nil {
+			return "", err
+		}
+		record, err := c.nonces.get(address)
+		found := err == nil && record.pendingByHash(txHash) != nil
+		unlock()
+		if err != nil {
+			return "", err
+		}
+		if found {
+			return address, nil
+		}
+	}
+	return "", ErrTransactionNotPending
+}
+
+// SpeedUpByPrivateKey replaces a pending outgoing transaction with the same
+// transaction sent with a higher fee. Returns the hash of the replacement.
+func (c *Client) SpeedUpByPrivateKey(fromPrivateKey []byte, txHash string) (newTxHash string, err error) {
+	signer, err := crypto.NewLocalSigner(fromPrivateKey)
+	if err != nil {
+		return "", err
+	}
+	return c.replaceBySigner(signer, txHash, false)
+}
+
+// CancelByPrivateKey replaces a pending outgoing transaction with a 0-value
+// transfer to the sender itself sent with a higher fee. Returns the hash of the replacement.
+func (c *Client) CancelByPrivateKey(fromPrivateKey []byte, txHash string) (newTxHash string, err error) {
+	signer, err := crypto.NewLocalSigner(fromPrivateKey)
+	if err != nil {
+		return "", err
+	}
+	return c.replaceBySigner(signer, txHash, true)
+}
+
+// SpeedUpBySigner replaces a pending outgoing transaction of the signer address
+// with the same transaction sent with a higher fee. Returns the hash of the replacement.
+func (c *Client) SpeedUpBySigner(signer crypto.Signer, txHash string) (newTxHash string, err error) {
+	return c.replaceBySigner(signer, txHash, false)
+}
+
+// CancelBySigner replaces a pending outgoing transaction of the signer address with
+// a 0-value transfer to the sender itself sent with a higher fee. Returns the hash of the replacement.
+func (c *Client) CancelBySigner(signer crypto.Signer, txHash string) (newTxHash string, err error) {
+	return c.replaceBySigner(signer, txHash, true)
+}
+
+func (c *Client) replaceBySigner(signer crypto.Signer, txHash string, cancel bool) (newTxHash string, err error) {
+	from, err := c.PendingTransferFrom(txHash)
+	if err != nil {
+		return "", err
+	}
+	signerAddress, err := c.signerAddress(signer)
+	if err != nil {
+		return "", err
+	}
+	if !strings.EqualFold(signerAddress, from) {
+		return "", ErrPrivateKeyMismatch
+	}
+	replacedTxHash, newTxHash, err := c.replaceLocked(signer, from, txHash, cancel)
+	if err != nil {
+		return "", err
+	}
+	c.replaced(replacedTxHash, newTxHash, cancel)
+	return newTxHash, nil
+}
+
+// replaceLocked replaces the current version of the pending transaction under the address lock.
+// Returns the hash of the replaced version, which may differ from the referenced one.
+func (c *Client) replaceLocked(signer crypto.Signer, from, txHash string, cancel bool) (replacedTxHash, newTxHash string, err error) {
+	unlock, err := c.nonces.lock(from)
+	if err != nil {
+		return "", "", err
+	}
+	defer unlock()
+	record, err := c.nonces.get(from)
+	if err != nil {
+		return "", "", err
+	}
+	pending := record.pendingByHash(txHash)
+	if pending == nil || pending.RawTx == "" || pending.Fee == nil {
+		return "", "", ErrTransactionNotPending
+	}
+	latest, err := c.LatestNonceAt(from)
+	if err != nil {
+		return "", "", err
+	}
+	if pending.Nonce < latest {
+		return "", "", ErrTransactionNotPending
+	}
+	replacedTxHash = pending.TxHash
+	newTxHash, err = c.replaceUnsafe(signer, record, pending, cancel)
+	if err != nil {
+		return "", "", err
+	}
+	return replacedTxHash, newTxHash, nil
+}
+
+// replaceUnsafe sends the replacement of the pending transaction with the same nonce
+// and a bumped fee. The caller must hold the address lock.
+func (c *Client) replaceUnsafe(signer crypto.Signer, record *NonceRecord, pending *PendingNonce, cancel bool) (txHash string, err error) {
+	to, value, data, gas := pending.To, pending.Value, pending.Data, pending.Fee.Gas
+	if cancel {
+		to, value, data, gas = record.Address, big.NewInt(0), nil, transferGas
+	}
+	fee, err := c.bumpFee(pending.Fee, gas, c.config.SpeedUpPercent, c.config.SpeedUpMaxGasPrice)
+	if err != nil {
+		return "", err
+	}
+	rawTx, err := c.signTx(signer, pending.Nonce, to, value, data, fee)
+	if err != nil {
+		return "", err
+	}
+	txHash, err = c.SendRawTransaction(rawTx)
+	if err != nil {
+		log.Error("Can not broadcast replacement transaction:", err)
+		return "", err
+	}
+	pending.Replaced = append(pending.Replaced, pending.TxHash)
+	pending.TxHash = txHash
+	pending.RawTx = rawTx
+	pending.To = to
+	pending.Value = value
+	pending.Data = data
+	pending.Fee = fee
+	pending.Cancel = pending.Cancel || cancel
+	pending.SentBlock = 0
+	pending.Bumps++
+	return txHash, c.nonces.save(record)
+}
+
+// BlockEvent speeds up the outgoing transactions pending for more than
+// SpeedUpAfterBlocks blocks. It is registered as the watchdog block listener and
+// returns at once, the transactions are replaced in the background.
+func (c *Client) BlockEvent(blockNum int64, blockId string) {
+	if c.config.SpeedUpAfterBlocks <= 0 || c.signerSource == nil {
+		return
+	}
+	if !c.speedUpMux.TryLock() {
+		return
+	}
+	go c.speedUp(blockNum)
+}
+
+// speedUp replaces the stuck transactions of all addresses with pending nonces.
+// Must be called with speedUpMux locked, unlocks it when done.
+func (c *Client) speedUp(blockNum int64) {
+	defer c.speedUpMux.Unlock()
+	end, err := c.nonces.begin()
+	if err != nil {
+		return
+	}
+	defer end()
+	addresses, err := c.nonces.addresses()
+	if err != nil {
+		log.Error("Can not load nonce records:", err)
+		return
+	}
+	for _, address := range addresses {
+		for replacedTxHash, newTxHash := range c.speedUpAddress(address, blockNum) {
+			c.replaced(replacedTxHash, newTxHash, false)
+		}
+	}
+}
+
+// speedUpAddress replaces the stuck transactions of the address.
+// Returns the hashes of the replacements by the hashes of the replaced transactions.
+func (c *Client) speedUpAddress(address string, blockNum int64) (replacements map[string]string) {
+	replacements = make(map[string]string)
+	unlock, err := c.nonces.lock(address)
+	if err != nil {
+		return
+	}
+	defer unlock()
+	record, err := c.nonces.get(address)
+	if err != nil {
+		log.Error("Can not load nonce record:", err)
+		return
+	}
+	if len(record.Pending) == 0 {
+		return
+	}
+	_, err = c.nonceReconcile(record)
+	if err != nil {
+		log.Error("Can not reconcile nonces of", address, ":", err)
+		return
+	}
+	var signer crypto.Signer
+	for _, pending := range record.Pending {
+		if pending.RawTx == "" || pending.Fee == nil {
+			continue
+		}
+		if pending.SentBlock == 0 {
+			pending.SentBlock = blockNum
+			continue
+		}
+		if blockNum-pending.SentBlock < int64(c.config.SpeedUpAfterBlocks) {
+			continue
+		}
+		if signer == nil {
+			signer, err = c.signerSource(address)
+			if err != nil || signer == nil {
+				log.Debug("Can not speed up transaction", pending.TxHash, ": signer unavailable")
+				break
+			}
+		}
+		stuckTxHash := pending.TxHash
+		txHash, err := c.replaceUnsafe(signer, record, pending, false)
+		if errors.Is(err, ErrFeeCapExceeded) {
+			log.Warning("Can not speed up transaction", stuckTxHash, ":", err)
+			// wait another period before the next attempt
+			pending.SentBlock = blockNum
+			continue
+		} else if err != nil {
+			log.Error("Can not speed up transaction", stuckTxHash, ":", err)
+			continue
+		}
+		log.Info("Transaction", stuckTxHash, "replaced with", txHash)
+		replacements[stuckTxHash] = txHash
+	}
+	err = c.nonces.save(record)
+	if err != nil {
+		log.Error("Can not save nonce record:", err)
+	}
+	return replacements
+}
@@ -0,0 +1,116 @@
+package ethclient
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ITProLabDev/ethbacknode/common/hexnum"
+	"github.com/ITProLabDev/ethbacknode/types"
+)
+
+// replacementSearchDepth limits the blocks searched for the transaction replacing
+// a pending one, the state of older blocks is pruned by non-archive nodes.
+const replacementSearchDepth = 128
+
+// PendingTransferStatus reports whether a transaction seen in the mempool is still
+// pending, mined, dropped or replaced. The transaction is replaced if the sender nonce
+// is used by another mined transaction or by another transaction in the mempool.
+func (c *Client) PendingTransferStatus(tx *types.TransferInfo, sinceBlock int64) (status string, replacedBy string, err error) {
+	chainData := new(TxChainData)
+	err = tx.DecodeChainSpecificData(chainData.Decode)
+	if err != nil {
+		return "", "", err
+	}
+	txHash := tx.TxID
+	if chainData.TxHash != "" {
+		txHash = chainData.TxHash
+	}
+	txInternal, err := c.GetTransactionByHash(txHash)
+	if err == nil {
+		if txInternal.BlockNumber != 0 {
+			return types.TransferStatusMined, "", nil
+		}
+		return types.TransferStatusPending, "", nil
+	} else if !errors.Is(err, ErrTransactionNotFound) {
+		return "", "", err
+	}
+	latest, err := c.LatestNonceAt(tx.From)
+	if err != nil {
+		return "", "", err
+	}
+	if chainData.Nonce >= latest {
+		// the nonce is not used yet, a replacement may wait in the mempool
+		replacedBy, err = c.poolTransactionByNonce(tx.From, chainData.Nonce)
+		if err != nil {
+			return "", "", err
+		}
+		if replacedBy != "" {
+			return types.TransferStatusReplaced, replacedBy, nil
+		}
+		return types.TransferStatusDropped, "", nil
+	}
+	replacedBy, err = c.minedTransactionByNonce(tx.From, chainData.Nonce, sinceBlock)
+	if err != nil {
+		return "", "", err
+	}
+	return types.TransferStatusReplaced, replacedBy, nil
+}
+
+// poolTransactionByNonce returns the hash of the mempool transaction of the sender
+// with the nonce, empty if there is no such transaction.
+func (c *Client) poolTransactionByNonce(from string, nonce int64) (txHash string, err error) {
+	pending, queued, err := c.GetTxPoolContentFrom(from)
+	if err != nil {
+		return "", err
+	}
+	for _, txs := range []map[string]*Transaction{pending, queued} {
+		for _, tx := range txs {
+			if tx.Nonce == nonce {
+				return tx.Hash, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// minedTransactionByNonce returns the hash of the mined transaction of the sender
+// with the nonce. The block is found by the binary search of the block the sender
+// nonce was used at, starting from sinceBlock but not deeper than replacementSearchDepth.
+// Returns empty hash if the transaction is not found.
+func (c *Client) minedTransactionByNonce(from string, nonce int64, sinceBlock int64) (txHash string, err error) {
+	latestBlock, err := c.GetBlockNumber()
+	if err != nil {
+		return "", err
+	}
+	low, high := sinceBlock, latestBlock
+	if low < latestBlock-replacementSearchDepth {
+		low = latestBlock - replacementSearchDepth
+	}
+	if low < 0 {
+		low = 0
+	}
+	for low < high {
+		mid := (low + high) / 2
+		count, err := c.nonceAt(from, hexnum.Int64ToHex(mid))
+		if err != nil {
+			return "", err
+		}
+		if count > nonce {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+	block, err := c.GetBlockByNumber(low, true)
+	if err != nil {
+		return "", err
+	}
+	err = block.WalkTransactions(func(tx *Transaction) (stop bool) {
+		if tx.Nonce == nonce && strings.EqualFold(tx.From, from) {
+			txHash = tx.Hash
+			return true
+		}
+		return false
+	})
+	return txHash, err
+}
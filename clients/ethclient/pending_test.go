@@ -0,0 +1,112 @@
+package ethclient
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ITProLabDev/ethbacknode/clients/urpc"
+	"github.com/ITProLabDev/ethbacknode/common/hexnum"
+	"github.com/ITProLabDev/ethbacknode/types"
+)
+
+func TestPendingTransferStatus(t *testing.T) {
+	const (
+		replacementHash = "0x7c1d3b2a5e4f6a8b9c0d1e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b7c8d9e0f1a2b"
+		txNonce         = 5
+		minedAtBlock    = 0x60
+	)
+	chainData, _ := json.Marshal(&TxChainData{Nonce: txNonce})
+	tests := []struct {
+		name         string
+		known        interface{} // eth_getTransactionByHash result
+		latestNonce  int64
+		pool         map[string]interface{}
+		wantStatus   string
+		wantReplaced string
+	}{
+		{
+			name:       "mined",
+			known:      map[string]interface{}{"hash": testTxHash, "blockNumber": "0x61"},
+			wantStatus: types.TransferStatusMined,
+		},
+		{
+			name:       "pending",
+			known:      map[string]interface{}{"hash": testTxHash, "blockNumber": nil},
+			wantStatus: types.TransferStatusPending,
+		},
+		{
+			name:        "dropped",
+			latestNonce: txNonce,
+			pool: map[string]interface{}{
+				"4": map[string]interface{}{"hash": "0x01", "nonce": "0x4"},
+			},
+			wantStatus: types.TransferStatusDropped,
+		},
+		{
+			name:         "replaced in the pool",
+			latestNonce:  txNonce,
+			pool:         map[string]interface{}{"5": map[string]interface{}{"hash": replacementHash, "nonce": "0x5"}},
+			wantStatus:   types.TransferStatusReplaced,
+			wantReplaced: replacementHash,
+		},
+		{
+			name:         "replaced by a mined transaction",
+			latestNonce:  txNonce + 1,
+			wantStatus:   types.TransferStatusReplaced,
+			wantReplaced: replacementHash,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, node := newTestClient(t, map[string]nodeMethod{
+				ethGetTransactionByHash: func(params []json.RawMessage) (interface{}, *urpc.Error) {
+					return tt.known, nil
+				},
+				ethGetTransactionCount: func(params []json.RawMessage) (interface{}, *urpc.Error) {
+					var address, tag string
+					json.Unmarshal(params[0], &address)
+					json.Unmarshal(params[1], &tag)
+					if tag == tagBlockLatest {
+						return hexnum.Int64ToHex(tt.latestNonce), nil
+					}
+					blockNum, _ := hexnum.ParseHexInt64(tag)
+					if blockNum >= minedAtBlock {
+						return hexnum.Int64ToHex(txNonce + 1), nil
+					}
+					return hexnum.Int64ToHex(txNonce), nil
+				},
+				txpoolContentFrom: func(params []json.RawMessage) (interface{}, *urpc.Error) {
+					var address string
+					if json.Unmarshal(params[0], &address) != nil || address != testSender {
+						return nil, &urpc.Error{Code: -32602, Message: "invalid address"}
+					}
+					return map[string]interface{}{"pending": tt.pool, "queued": map[string]interface{}{}}, nil
+				},
+				ethGetBlockNumber: func(params []json.RawMessage) (interface{}, *urpc.Error) {
+					return "0x64", nil
+				},
+				ethGetBlockByNumber: func(params []json.RawMessage) (interface{}, *urpc.Error) {
+					var number string
+					json.Unmarshal(params[0], &number)
+					if number != hexnum.Int64ToHex(minedAtBlock) {
+						return map[string]interface{}{"number": number, "transactions": []interface{}{}}, nil
+					}
+					return map[string]interface{}{"number": number, "transactions": []interface{}{
+						map[string]interface{}{"hash": replacementHash, "from": testSender, "nonce": "0x5", "blockNumber": number},
+					}}, nil
+				},
+			})
+			tx := &types.TransferInfo{TxID: testTxHash, From: testSender, InPool: true, ChainSpecificData: chainData}
+			status, replacedBy, err := client.PendingTransferStatus(tx, 0x50)
+			if err != nil {
+				t.Fatalf("PendingTransferStatus: %v", err)
+			}
+			if status != tt.wantStatus || replacedBy != tt.wantReplaced {
+				t.Fatalf("got %q replaced by %q, want %q replaced by %q", status, replacedBy, tt.wantStatus, tt.wantReplaced)
+			}
+			if tt.pool != nil && node.called(txpoolContentFrom) != 1 {
+				t.Fatalf("txpool_contentFrom called %d times", node.called(txpoolContentFrom))
+			}
+		})
+	}
+}
@@ -1,19 +1,71 @@
 package ethclient
 
 import (
+	"github.com/ITProLabDev/ethbacknode/address"
 	"github.com/ITProLabDev/ethbacknode/common/hexnum"
 	"github.com/ITProLabDev/ethbacknode/crypto"
 	"github.com/ITProLabDev/ethbacknode/tools/log"
 	"math/big"
+	"strings"
 )
 
-func (c *Client) sendRawByPrivateKeyUnsafe(fromPrivateKey []byte, from, to string, amount, gasPrice *big.Int, gas int64) (txHash string, err error) {
-	toBytes, err := c.addressCodec.DecodeAddressToBytes(to)
+// sendRawBySignerUnsafe signs and broadcasts the transaction with the next nonce of the address.
+// The nonce is released only when the node definitely rejected the transaction, on any other
+// broadcast error it stays allocated and the hash of the kept transaction is returned with the error.
+func (c *Client) sendRawBySignerUnsafe(signer crypto.Signer, from, to string, amount *big.Int, data []byte, fee *TxFee) (txHash string, err error) {
+	// sends from the same address are serialized, so each one gets its own nonce
+	unlock, err := c.nonces.lock(from)
 	if err != nil {
 		return "", err
 	}
-	pk, _ := crypto.ECDSAKeysFromPrivateKeyBytes(fromPrivateKey)
-	nonce, err := c.PendingNonceAt(from)
+	defer unlock()
+	nonce, err := c.nonceAllocate(from)
+	if err != nil {
+		return "", err
+	}
+	rawTx, err := c.signTx(signer, nonce, to, amount, data, fee)
+	if err != nil {
+		c.nonceRelease(from, nonce)
+		return "", err
+	}
+	pending := &PendingNonce{
+		Nonce: nonce,
+		RawTx: rawTx,
+		To:    to,
+		Value: amount,
+		Data:  data,
+		Fee:   fee,
+	}
+	txHash, err = c.SendRawTransaction(rawTx)
+	if err != nil {
+		log.Error("Can not broadcast transaction:", err)
+		if sendRejected(err) {
+			c.nonceRelease(from, nonce)
+			return "", err
+		}
+		// the node may have accepted the transaction, the nonce stays allocated
+		// and the transaction is rebroadcast if the node does not know it
+		pending.TxHash, _ = rawTxHash(rawTx)
+		c.nonceCommit(from, pending)
+		return pending.TxHash, err
+	}
+	pending.TxHash = txHash
+	c.nonceCommit(from, pending)
+	return txHash, nil
+}
+
+// rawTxHash returns the hash of the signed transaction encoded for broadcast.
+func rawTxHash(rawTx string) (txHash string, err error) {
+	txBytes, err := hexnum.ParseHexBytes(rawTx)
+	if err != nil {
+		return "", err
+	}
+	return hexnum.BytesToHex(crypto.Keccak256(txBytes)), nil
+}
+
+// signTx signs the transaction with the nonce and returns it encoded for broadcast.
+func (c *Client) signTx(signer crypto.Signer, nonce int64, to string, amount *big.Int, data []byte, fee *TxFee) (rawTx string, err error) {
+	toBytes, err := c.addressCodec.DecodeAddressToBytes(to)
 	if err != nil {
 		return "", err
 	}
@@ -23,30 +75,47 @@ func (c *Client) sendRawByPrivateKeyUnsafe(fromPrivateKey []byte, from, to strin
 	}
 	chainID := big.NewInt(netId)
 	log.Warning("ChainID:", chainID)
-	txSigner := &crypto.EthTxSigner{
-		Nonce:    uint64(nonce),
-		GasPrice: gasPrice,
-		Gas:      uint64(gas),
-		To:       &toBytes,
-		Value:    amount,
-	}
-	txSigner.SetChainId(chainID)
-	sign := txSigner.Sign(pk)
-	if len(sign) == 0 {
-		log.Error("Can not sign transaction: sign is empty")
-		return "", ErrTransactionSignError
+	tx := &crypto.Tx{
+		ChainId: chainID,
+		Nonce:   uint64(nonce),
+		To:      toBytes,
+		Value:   amount,
+		Data:    data,
+		Gas:     uint64(fee.Gas),
 	}
-	txSignedBytes, err := txSigner.EncodeRPL()
+	if fee.IsDynamic() {
+		tx.MaxFeePerGas = fee.MaxFeePerGas
+		tx.MaxPriorityFeePerGas = fee.MaxPriorityFeePerGas
+	} else {
+		tx.GasPrice = fee.GasPrice
+	}
+	txSignedBytes, err := signer.SignTx(tx)
 	if err != nil {
-		log.Error("Can not encode signed transaction:", err)
-		return "", err
+		log.Error("Can not sign transaction:", err)
+		return "", ErrTransactionSignError
+	}
+	rawTx = hexnum.BytesToHex(txSignedBytes)
+	log.Warning("txSignedBytes", rawTx)
+	return rawTx, nil
+}
+
+// signerAddress returns the address the signer signs for.
+func (c *Client) signerAddress(signer crypto.Signer) (from string, err error) {
+	return c.addressCodec.EncodeBytesToAddress(signer.Address())
+}
 
+// privateKeySigner returns the local signer of the private key, which must own the from address.
+func (c *Client) privateKeySigner(fromPrivateKey []byte, from string) (signer crypto.Signer, err error) {
+	signer, err = crypto.NewLocalSigner(fromPrivateKey)
+	if err != nil {
+		return nil, err
 	}
-	log.Warning("txSignedBytes", hexnum.BytesToHex(txSignedBytes))
-	txHash, err = c.SendRawTransaction(hexnum.BytesToHex(txSignedBytes))
+	fromAddress, err := c.signerAddress(signer)
 	if err != nil {
-		log.Error("Can not broadcast transaction:", err)
-		return "", err
+		return nil, err
 	}
-	return txHash, nil
+	if strings.ToUpper(from) != strings.ToUpper(fromAddress) {
+		return nil, address.ErrAddressPrivateKeyMismatch
+	}
+	return signer, nil
 }
@@ -0,0 +1,147 @@
+package ethclient
+
+import (
+	"github.com/ITProLabDev/ethbacknode/common/hexnum"
+	"github.com/ITProLabDev/ethbacknode/crypto"
+	"github.com/ITProLabDev/ethbacknode/tools/log"
+	"github.com/ITProLabDev/ethbacknode/types"
+	"math/big"
+)
+
+// TransferTokenByPrivateKey sends amount of ERC-20 token (symbol or contract address)
+// from the address owning the private key. Checks both the token balance and
+// the native coin balance required to pay for gas.
+func (c *Client) TransferTokenByPrivateKey(fromPrivateKey []byte, from, to string, amount *big.Int, token string) (txHash string, err error) {
+	signer, err := c.privateKeySigner(fromPrivateKey, from)
+	if err != nil {
+		return "", err
+	}
+	return c.TransferTokenBySigner(signer, to, amount, token)
+}
+
+// TransferAllTokenByPrivateKey sends the whole ERC-20 token balance
+// from the address owning the private key.
+func (c *Client) TransferAllTokenByPrivateKey(fromPrivateKey []byte, from, to string, token string) (txHash string, err error) {
+	signer, err := c.privateKeySigner(fromPrivateKey, from)
+	if err != nil {
+		return "", err
+	}
+	return c.TransferAllTokenBySigner(signer, to, token)
+}
+
+// TransferTokenBySigner sends amount of ERC-20 token (symbol or contract address)
+// from the address of the signer. Checks both the token balance and
+// the native coin balance required to pay for gas.
+func (c *Client) TransferTokenBySigner(signer crypto.Signer, to string, amount *big.Int, token string) (txHash string, err error) {
+	from, tokenInfo, err := c.tokenTransferPrepare(signer, to, token)
+	if err != nil {
+		return "", err
+	}
+	if amount == nil || amount.Sign() <= 0 {
+		return "", ErrNothingToTransfer
+	}
+	tokenBalance, err := c.ContractGetBalanceOf(tokenInfo.ContractAddress, from)
+	if err != nil {
+		return "", err
+	}
+	log.Warning("Current Token Balance:", tokenBalance, tokenInfo.Symbol)
+	if amount.Cmp(tokenBalance) > 0 {
+		return "", ErrInsufficientTokenFunds
+	}
+	return c.tokenTransferSend(signer, from, to, tokenInfo.ContractAddress, amount)
+}
+
+// TransferAllTokenBySigner sends the whole ERC-20 token balance
+// from the address of the signer.
+func (c *Client) TransferAllTokenBySigner(signer crypto.Signer, to string, token string) (txHash string, err error) {
+	from, tokenInfo, err := c.tokenTransferPrepare(signer, to, token)
+	if err != nil {
+		return "", err
+	}
+	tokenBalance, err := c.ContractGetBalanceOf(tokenInfo.ContractAddress, from)
+	if err != nil {
+		return "", err
+	}
+	log.Warning("Current Token Balance:", tokenBalance, tokenInfo.Symbol)
+	if tokenBalance.Sign() <= 0 {
+		return "", ErrNothingToTransfer
+	}
+	return c.tokenTransferSend(signer, from, to, tokenInfo.ContractAddress, tokenBalance)
+}
+
+// TransferTokenGetEstimatedFee estimates the native coin fee of an ERC-20 token transfer.
+func (c *Client) TransferTokenGetEstimatedFee(from, to string, amount *big.Int, token string) (fee *big.Int, err error) {
+	tokenInfo, err := c.tokenGet(token)
+	if err != nil {
+		return nil, err
+	}
+	callData, err := c.abi.Erc20CallTransfer(to, amount)
+	if err != nil {
+		return nil, err
+	}
+	txFee, err := c.GetEstimatedTxFee(from, tokenInfo.ContractAddress, hexnum.BytesToHex(callData), big.NewInt(0))
+	if err != nil {
+		return nil, err
+	}
+	return txFee.ExpectedFee(), nil
+}
+
+// TransferTokenGetMaxFee returns the maximum native coin fee of an ERC-20 token transfer,
+// i.e. the native coin balance the sender needs to broadcast it.
+func (c *Client) TransferTokenGetMaxFee(from, to string, amount *big.Int, token string) (fee *big.Int, err error) {
+	tokenInfo, err := c.tokenGet(token)
+	if err != nil {
+		return nil, err
+	}
+	callData, err := c.abi.Erc20CallTransfer(to, amount)
+	if err != nil {
+		return nil, err
+	}
+	txFee, err := c.GetEstimatedTxFee(from, tokenInfo.ContractAddress, hexnum.BytesToHex(callData), big.NewInt(0))
+	if err != nil {
+		return nil, err
+	}
+	return txFee.MaxFee(), nil
+}
+
+// tokenTransferPrepare resolves the sender address of the signer, validates
+// the recipient address and resolves the token.
+func (c *Client) tokenTransferPrepare(signer crypto.Signer, to string, token string) (from string, tokenInfo *types.TokenInfo, err error) {
+	from, err = c.signerAddress(signer)
+	if err != nil {
+		return "", nil, err
+	}
+	_, err = c.addressCodec.DecodeAddressToBytes(to)
+	if err != nil {
+		return "", nil, err
+	}
+	tokenInfo, err = c.tokenGet(token)
+	if err != nil {
+		return "", nil, err
+	}
+	return from, tokenInfo, nil
+}
+
+// tokenTransferSend encodes the ERC-20 transfer call, checks the native coin
+// balance covers the estimated fee and broadcasts the transaction to the token contract.
+func (c *Client) tokenTransferSend(signer crypto.Signer, from, to, contractAddress string, amount *big.Int) (txHash string, err error) {
+	callData, err := c.abi.Erc20CallTransfer(to, amount)
+	if err != nil {
+		return "", err
+	}
+	txFee, err := c.GetEstimatedTxFee(from, contractAddress, hexnum.BytesToHex(callData), big.NewInt(0))
+	if err != nil {
+		return "", err
+	}
+	fee := txFee.MaxFee()
+	currentBalance, err := c.GetBalance(from)
+	if err != nil {
+		return "", err
+	}
+	log.Warning("Current Balance:", currentBalance)
+	log.Warning("Estimated Fee:", fee)
+	if fee.Cmp(currentBalance) > 0 {
+		return "", ErrInsufficientGasFunds
+	}
+	return c.sendRawBySignerUnsafe(signer, from, contractAddress, big.NewInt(0), callData, txFee)
+}
@@ -22,3 +22,15 @@ func (c *Client) tokenGetIfExistBySymbol(symbol string) (tokenInfo *types.TokenI
 	}
 	return nil, false
 }
+
+// tokenGet resolves a known token by symbol or contract address.
+func (c *Client) tokenGet(token string) (tokenInfo *types.TokenInfo, err error) {
+	tokenInfo, ok := c.tokenGetIfExistBySymbol(token)
+	if !ok {
+		tokenInfo, ok = c.tokenGetIfExistByAddress(token)
+		if !ok {
+			return nil, ErrUnknownToken
+		}
+	}
+	return tokenInfo, nil
+}
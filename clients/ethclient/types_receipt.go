@@ -0,0 +1,296 @@
+package ethclient
+
+import (
+	"encoding/json"
+	"github.com/ITProLabDev/ethbacknode/common/hexnum"
+	"math/big"
+)
+
+// receiptStatusSuccessful is the receipt status of a successfully executed transaction
+const receiptStatusSuccessful = 1
+
+type Receipt struct {
+	// TransactionHash 32 Bytes - hash of the transaction.
+	TransactionHash string `json:"transactionHash"`
+
+	// TransactionIndex integer of the transactions index position in the block.
+	TransactionIndex int64 `json:"transactionIndex"`
+
+	// BlockHash 32 Bytes - hash of the block where this transaction was in.
+	BlockHash string `json:"blockHash"`
+
+	// BlockNumber block number where this transaction was in.
+	BlockNumber int64 `json:"blockNumber"`
+
+	// From 20 Bytes - address of the sender.
+	From string `json:"from"`
+
+	// To 20 Bytes - address of the receiver. null when its a contract creation transaction.
+	To string `json:"to"`
+
+	// ContractAddress 20 Bytes - the contract address created, if the transaction
+	// was a contract creation, otherwise null.
+	ContractAddress string `json:"contractAddress"`
+
+	// CumulativeGasUsed the total amount of gas used when this transaction
+	// was executed in the block.
+	CumulativeGasUsed int64 `json:"cumulativeGasUsed"`
+
+	// GasUsed the amount of gas used by this specific transaction alone.
+	GasUsed int64 `json:"gasUsed"`
+
+	// EffectiveGasPrice the actual value per gas deducted from the sender's
+	// account. Before EIP-1559, this is equal to the transaction's gas price.
+	EffectiveGasPrice *big.Int `json:"effectiveGasPrice"`
+
+	// BlobGasUsed the amount of blob gas used by an EIP-4844 transaction.
+	BlobGasUsed int64 `json:"blobGasUsed"`
+
+	// BlobGasPrice the actual value per blob gas deducted from the sender's account.
+	BlobGasPrice *big.Int `json:"blobGasPrice"`
+
+	// Status either 1 (success) or 0 (failure)
+	Status int64 `json:"status"`
+
+	// Type integer of the transaction type
+	Type int64 `json:"type"`
+
+	// Logs array of log objects, which this transaction generated.
+	Logs []*Log `json:"logs"`
+}
+
+// IsSuccessful returns true if the transaction was executed without revert
+func (r *Receipt) IsSuccessful() bool {
+	return r.Status == receiptStatusSuccessful
+}
+
+// Fee returns the amount actually paid for the transaction execution
+// (gasUsed * effectiveGasPrice + blobGasUsed * blobGasPrice). The gasPrice
+// fallback is used for nodes that do not return effectiveGasPrice in receipts.
+func (r *Receipt) Fee(gasPrice *big.Int) *big.Int {
+	price := r.GetEffectiveGasPrice(gasPrice)
+	fee := new(big.Int).Mul(price, new(big.Int).SetInt64(r.GasUsed))
+	if r.BlobGasPrice != nil && r.BlobGasUsed != 0 {
+		fee.Add(fee, new(big.Int).Mul(r.BlobGasPrice, new(big.Int).SetInt64(r.BlobGasUsed)))
+	}
+	return fee
+}
+
+// GetEffectiveGasPrice returns effectiveGasPrice of the receipt or the
+// gasPrice fallback if the node does not provide it.
+func (r *Receipt) GetEffectiveGasPrice(gasPrice *big.Int) *big.Int {
+	if r.EffectiveGasPrice != nil && !_isZeroBigInt(r.EffectiveGasPrice) {
+		return r.EffectiveGasPrice
+	}
+	if gasPrice == nil {
+		return new(big.Int)
+	}
+	return gasPrice
+}
+
+// UnmarshalJSON Since Ethereum uses non-standard encoding of integer data
+// (0x prefixed hex) in its RPC responses, we need to implement full
+// decoding of receipts through a special method.
+func (r *Receipt) UnmarshalJSON(data []byte) (err error) {
+	proxy := make(map[string]json.RawMessage)
+	err = json.Unmarshal(data, &proxy)
+	if err != nil {
+		return err
+	}
+	if transactionHash, found := proxy[`transactionHash`]; found {
+		err = json.Unmarshal(transactionHash, &r.TransactionHash)
+		if err != nil {
+			return err
+		}
+	}
+	if blockHash, found := proxy[`blockHash`]; found {
+		err = json.Unmarshal(blockHash, &r.BlockHash)
+		if err != nil {
+			return err
+		}
+	}
+	if from, found := proxy[`from`]; found {
+		err = json.Unmarshal(from, &r.From)
+		if err != nil {
+			return err
+		}
+	}
+	if to, found := proxy[`to`]; found {
+		err = json.Unmarshal(to, &r.To)
+		if err != nil {
+			return err
+		}
+	}
+	if contractAddress, found := proxy[`contractAddress`]; found {
+		err = json.Unmarshal(contractAddress, &r.ContractAddress)
+		if err != nil {
+			return err
+		}
+	}
+	r.TransactionIndex, err = _parseProxyHexInt64(proxy, `transactionIndex`)
+	if err != nil {
+		return err
+	}
+	r.BlockNumber, err = _parseProxyHexInt64(proxy, `blockNumber`)
+	if err != nil {
+		return err
+	}
+	r.CumulativeGasUsed, err = _parseProxyHexInt64(proxy, `cumulativeGasUsed`)
+	if err != nil {
+		return err
+	}
+	r.GasUsed, err = _parseProxyHexInt64(proxy, `gasUsed`)
+	if err != nil {
+		return err
+	}
+	r.BlobGasUsed, err = _parseProxyHexInt64(proxy, `blobGasUsed`)
+	if err != nil {
+		return err
+	}
+	r.BlobGasPrice, err = _parseProxyBigInt(proxy, `blobGasPrice`)
+	if err != nil {
+		return err
+	}
+	r.Status, err = _parseProxyHexInt64(proxy, `status`)
+	if err != nil {
+		return err
+	}
+	r.Type, err = _parseProxyHexInt64(proxy, `type`)
+	if err != nil {
+		return err
+	}
+	r.EffectiveGasPrice, err = _parseProxyBigInt(proxy, `effectiveGasPrice`)
+	if err != nil {
+		return err
+	}
+	if logs, found := proxy[`logs`]; found {
+		err = json.Unmarshal(logs, &r.Logs)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type Log struct {
+	// Address 20 Bytes - address from which this log originated.
+	Address string `json:"address"`
+
+	// Topics array of 0 to 4 32 Bytes of indexed log arguments.
+	Topics []string `json:"topics"`
+
+	// Data contains one or more 32 Bytes non-indexed arguments of the log.
+	Data string `json:"data"`
+
+	// BlockNumber the block number where this log was in.
+	BlockNumber int64 `json:"blockNumber"`
+
+	// BlockHash 32 Bytes - hash of the block where this log was in.
+	BlockHash string `json:"blockHash"`
+
+	// TransactionHash 32 Bytes - hash of the transactions this log was created from.
+	TransactionHash string `json:"transactionHash"`
+
+	// TransactionIndex integer of the transactions index position log was created from.
+	TransactionIndex int64 `json:"transactionIndex"`
+
+	// LogIndex integer of the log index position in the block.
+	LogIndex int64 `json:"logIndex"`
+
+	// Removed true when the log was removed, due to a chain reorganization.
+	Removed bool `json:"removed"`
+}
+
+// UnmarshalJSON decodes a log object with 0x prefixed hex integers.
+func (l *Log) UnmarshalJSON(data []byte) (err error) {
+	proxy := make(map[string]json.RawMessage)
+	err = json.Unmarshal(data, &proxy)
+	if err != nil {
+		return err
+	}
+	if address, found := proxy[`address`]; found {
+		err = json.Unmarshal(address, &l.Address)
+		if err != nil {
+			return err
+		}
+	}
+	if topics, found := proxy[`topics`]; found {
+		err = json.Unmarshal(topics, &l.Topics)
+		if err != nil {
+			return err
+		}
+	}
+	if logData, found := proxy[`data`]; found {
+		err = json.Unmarshal(logData, &l.Data)
+		if err != nil {
+			return err
+		}
+	}
+	if blockHash, found := proxy[`blockHash`]; found {
+		err = json.Unmarshal(blockHash, &l.BlockHash)
+		if err != nil {
+			return err
+		}
+	}
+	if transactionHash, found := proxy[`transactionHash`]; found {
+		err = json.Unmarshal(transactionHash, &l.TransactionHash)
+		if err != nil {
+			return err
+		}
+	}
+	if removed, found := proxy[`removed`]; found {
+		err = json.Unmarshal(removed, &l.Removed)
+		if err != nil {
+			return err
+		}
+	}
+	l.BlockNumber, err = _parseProxyHexInt64(proxy, `blockNumber`)
+	if err != nil {
+		return err
+	}
+	l.TransactionIndex, err = _parseProxyHexInt64(proxy, `transactionIndex`)
+	if err != nil {
+		return err
+	}
+	l.LogIndex, err = _parseProxyHexInt64(proxy, `logIndex`)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// _parseProxyHexInt64 decodes an optional 0x prefixed hex integer field.
+// Missing, null and empty values are decoded as 0.
+func _parseProxyHexInt64(proxy map[string]json.RawMessage, field string) (value int64, err error) {
+	raw, found := proxy[field]
+	if !found || string(raw) == "null" {
+		return 0, nil
+	}
+	var valueStr string
+	err = json.Unmarshal(raw, &valueStr)
+	if err != nil {
+		return 0, err
+	}
+	if valueStr == "" || valueStr == "0x" {
+		return 0, nil
+	}
+	return hexnum.ParseHexInt64(valueStr)
+}
+
+// _parseProxyBigInt decodes an optional 0x prefixed hex big integer field.
+// Missing, null and empty values are decoded as nil.
+func _parseProxyBigInt(proxy map[string]json.RawMessage, field string) (value *big.Int, err error) {
+	raw, found := proxy[field]
+	if !found || string(raw) == "null" {
+		return nil, nil
+	}
+	var valueStr string
+	err = json.Unmarshal(raw, &valueStr)
+	if err != nil {
+		return nil, err
+	}
+	if valueStr == "" || valueStr == "0x" {
+		return nil, nil
+	}
+	return hexnum.ParseBigInt(valueStr)
+}
@@ -0,0 +1,71 @@
+package ethclient
+
+import (
+	"encoding/json"
+	"github.com/ITProLabDev/ethbacknode/common/hexnum"
+	"math/big"
+)
+
+// callTracer is the name of the geth built-in tracer returning the call tree of a transaction.
+const callTracer = "callTracer"
+
+// TxTrace is a callTracer result of a single transaction of the traced block.
+// TxHash is empty on older nodes, the result order matches block transactions order.
+type TxTrace struct {
+	TxHash string     `json:"txHash"`
+	Result *CallFrame `json:"result"`
+}
+
+// CallFrame is a call tree node returned by the callTracer.
+type CallFrame struct {
+	// Type is the call type: CALL, STATICCALL, DELEGATECALL, CALLCODE, CREATE, CREATE2 or SELFDESTRUCT.
+	Type string `json:"type"`
+
+	// From is the caller address.
+	From string `json:"from"`
+
+	// To is the callee (or created contract, or selfdestruct beneficiary) address.
+	To string `json:"to"`
+
+	// Value is the amount of wei transferred with the call, nil for calls without value.
+	Value *big.Int `json:"value"`
+
+	// Input is the call data.
+	Input string `json:"input"`
+
+	// Error is set when the call reverted or failed, all nested calls are reverted as well.
+	Error string `json:"error"`
+
+	// Calls are the nested calls made by this call.
+	Calls []*CallFrame `json:"calls"`
+}
+
+// UnmarshalJSON decodes a call frame with 0x prefixed hex value.
+func (f *CallFrame) UnmarshalJSON(data []byte) (err error) {
+	proxy := &struct {
+		Type  string       `json:"type"`
+		From  string       `json:"from"`
+		To    string       `json:"to"`
+		Value string       `json:"value"`
+		Input string       `json:"input"`
+		Error string       `json:"error"`
+		Calls []*CallFrame `json:"calls"`
+	}{}
+	err = json.Unmarshal(data, proxy)
+	if err != nil {
+		return err
+	}
+	f.Type = proxy.Type
+	f.From = proxy.From
+	f.To = proxy.To
+	f.Input = proxy.Input
+	f.Error = proxy.Error
+	f.Calls = proxy.Calls
+	if proxy.Value != "" && proxy.Value != "0x" {
+		f.Value, err = hexnum.ParseBigInt(proxy.Value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
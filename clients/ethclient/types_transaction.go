@@ -6,24 +6,77 @@ import (
 	"math/big"
 )
 
+// Transaction types defined by EIP-2718 typed transaction envelopes.
+const (
+	LegacyTxType     = 0x00
+	AccessListTxType = 0x01 // EIP-2930
+	DynamicFeeTxType = 0x02 // EIP-1559
+	BlobTxType       = 0x03 // EIP-4844
+	SetCodeTxType    = 0x04 // EIP-7702
+)
+
+// blobGasPerBlob is the amount of blob gas consumed by a single blob (EIP-4844).
+const blobGasPerBlob = 1 << 17
+
 type Transaction struct {
-	BlockHash        string          `json:"blockHash"`
-	BlockNumber      int64           `json:"blockNumber"`
-	Hash             string          `json:"hash"`
-	From             string          `json:"from"`
-	To               string          `json:"to"`
-	Gas              int64           `json:"gas"`
-	GasPrice         *big.Int        `json:"gasPrice"`
-	Value            *big.Int        `json:"value"`
-	Input            string          `json:"input"`
-	Nonce            int64           `json:"nonce"`
-	TransactionIndex int64           `json:"transactionIndex"`
-	Type             int64           `json:"type"`
-	ChainId          int64           `json:"chainId"`
-	AccessList       json.RawMessage `json:"accessList"`
-	V                string          `json:"v"`
-	R                string          `json:"r"`
-	S                string          `json:"s"`
+	BlockHash            string          `json:"blockHash"`
+	BlockNumber          int64           `json:"blockNumber"`
+	Hash                 string          `json:"hash"`
+	From                 string          `json:"from"`
+	To                   string          `json:"to"`
+	Gas                  int64           `json:"gas"`
+	GasPrice             *big.Int        `json:"gasPrice"`
+	MaxFeePerGas         *big.Int        `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *big.Int        `json:"maxPriorityFeePerGas"`
+	MaxFeePerBlobGas     *big.Int        `json:"maxFeePerBlobGas"`
+	BlobVersionedHashes  []string        `json:"blobVersionedHashes"`
+	Value                *big.Int        `json:"value"`
+	Input                string          `json:"input"`
+	Nonce                int64           `json:"nonce"`
+	TransactionIndex     int64           `json:"transactionIndex"`
+	Type                 int64           `json:"type"`
+	ChainId              int64           `json:"chainId"`
+	AccessList           json.RawMessage `json:"accessList"`
+	YParity              string          `json:"yParity"`
+	V                    string          `json:"v"`
+	R                    string          `json:"r"`
+	S                    string          `json:"s"`
+}
+
+// IsDynamicFee returns true if the transaction pays with maxFeePerGas and
+// maxPriorityFeePerGas (EIP-1559 and later transaction types).
+func (t *Transaction) IsDynamicFee() bool {
+	return t.MaxFeePerGas != nil
+}
+
+// EffectiveGasPrice returns the price per gas paid by the transaction in a block
+// with the given base fee. For dynamic fee transactions it is
+// min(maxFeePerGas, baseFee + maxPriorityFeePerGas). If the base fee is unknown
+// (mempool transactions), maxFeePerGas is returned as the upper bound.
+func (t *Transaction) EffectiveGasPrice(baseFee *big.Int) *big.Int {
+	if !t.IsDynamicFee() {
+		if t.GasPrice == nil {
+			return new(big.Int)
+		}
+		return t.GasPrice
+	}
+	if baseFee == nil || t.MaxPriorityFeePerGas == nil {
+		return t.MaxFeePerGas
+	}
+	price := new(big.Int).Add(baseFee, t.MaxPriorityFeePerGas)
+	if price.Cmp(t.MaxFeePerGas) > 0 {
+		return t.MaxFeePerGas
+	}
+	return price
+}
+
+// MaxBlobFee returns the maximum fee for the blobs carried by an EIP-4844 transaction.
+func (t *Transaction) MaxBlobFee() *big.Int {
+	if t.MaxFeePerBlobGas == nil || len(t.BlobVersionedHashes) == 0 {
+		return new(big.Int)
+	}
+	blobGas := big.NewInt(int64(len(t.BlobVersionedHashes)) * blobGasPerBlob)
+	return blobGas.Mul(blobGas, t.MaxFeePerBlobGas)
 }
 
 // UnmarshalJSON Since Ethereum uses non-standard encoding of integer data
@@ -81,6 +134,24 @@ func (t *Transaction) UnmarshalJSON(data []byte) (err error) {
 			return err
 		}
 	}
+	t.MaxFeePerGas, err = _parseProxyBigInt(proxy, `maxFeePerGas`)
+	if err != nil {
+		return err
+	}
+	t.MaxPriorityFeePerGas, err = _parseProxyBigInt(proxy, `maxPriorityFeePerGas`)
+	if err != nil {
+		return err
+	}
+	t.MaxFeePerBlobGas, err = _parseProxyBigInt(proxy, `maxFeePerBlobGas`)
+	if err != nil {
+		return err
+	}
+	if blobVersionedHashes, found := proxy[`blobVersionedHashes`]; found {
+		err = json.Unmarshal(blobVersionedHashes, &t.BlobVersionedHashes)
+		if err != nil {
+			return err
+		}
+	}
 	if hash, found := proxy[`hash`]; found {
 		err = json.Unmarshal(hash, &t.Hash)
 		if err != nil {
@@ -162,6 +233,12 @@ func (t *Transaction) UnmarshalJSON(data []byte) (err error) {
 		t.AccessList = accessList
 
 	}
+	if yParity, found := proxy[`yParity`]; found {
+		err = json.Unmarshal(yParity, &t.YParity)
+		if err != nil {
+			return err
+		}
+	}
 	if v, found := proxy[`v`]; found {
 		err = json.Unmarshal(v, &t.V)
 		if err != nil {
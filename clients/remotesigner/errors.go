@@ -0,0 +1,11 @@
+package remotesigner
+
+import "errors"
+
+var (
+	// ErrRemoteSignerResponse is returned for an unexpected response of the remote signer.
+	ErrRemoteSignerResponse = errors.New("unexpected remote signer response")
+	// ErrSignedTxMismatch is returned when the transaction signed by the remote signer
+	// differs from the requested one or is signed by another address.
+	ErrSignedTxMismatch = errors.New("remote signer signed another transaction")
+)
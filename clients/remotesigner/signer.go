@@ -0,0 +1,103 @@
+// Package remotesigner signs transactions with keys held by a remote signer
+// over JSON-RPC, such as web3signer or Clef.
+package remotesigner
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/ITProLabDev/ethbacknode/clients/urpc"
+	"github.com/ITProLabDev/ethbacknode/common/hexnum"
+	"github.com/ITProLabDev/ethbacknode/crypto"
+)
+
+// remoteSignMethod is the JSON-RPC method of the remote signer, served by
+// web3signer and by geth-compatible signers such as Clef.
+const remoteSignMethod = "eth_signTransaction"
+
+// RemoteSigner signs with a key held by a remote signer over JSON-RPC, the key
+// never enters the process. Implements crypto.Signer.
+type RemoteSigner struct {
+	client  *urpc.Client
+	address []byte
+}
+
+// NewRemoteSigner creates a signer of the address served by the remote signer at url.
+func NewRemoteSigner(url string, headers map[string]string, address []byte) *RemoteSigner {
+	return &RemoteSigner{
+		client:  urpc.NewClient(urpc.WithHTTPRpc(url, headers)),
+		address: address,
+	}
+}
+
+// remoteTxArgs is the transaction object of the eth_signTransaction request.
+type remoteTxArgs struct {
+	From                 string `json:"from"`
+	To                   string `json:"to"`
+	Gas                  string `json:"gas"`
+	GasPrice             string `json:"gasPrice,omitempty"`
+	MaxFeePerGas         string `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas string `json:"maxPriorityFeePerGas,omitempty"`
+	Value                string `json:"value"`
+	Nonce                string `json:"nonce"`
+	Data                 string `json:"data"`
+	ChainId              string `json:"chainId"`
+}
+
+// Address returns the address the remote signer signs for.
+func (s *RemoteSigner) Address() []byte {
+	return s.address
+}
+
+// SignTx requests the signature of the transaction from the remote signer.
+// The result is either the raw transaction (web3signer) or an object with
+// the raw transaction in the raw field (Clef). The returned transaction is
+// decoded and must be the requested one signed by the address, otherwise
+// ErrSignedTxMismatch is returned.
+func (s *RemoteSigner) SignTx(tx *crypto.Tx) (signedTx []byte, err error) {
+	value := tx.Value
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	args := &remoteTxArgs{
+		From:    hexnum.BytesToHex(s.address),
+		To:      hexnum.BytesToHex(tx.To),
+		Gas:     hexnum.Uint64ToHex(tx.Gas),
+		Value:   hexnum.BigIntToHex(value),
+		Nonce:   hexnum.Uint64ToHex(tx.Nonce),
+		Data:    hexnum.BytesToHex(tx.Data),
+		ChainId: hexnum.BigIntToHex(tx.ChainId),
+	}
+	if tx.IsDynamic() {
+		args.MaxFeePerGas = hexnum.BigIntToHex(tx.MaxFeePerGas)
+		args.MaxPriorityFeePerGas = hexnum.BigIntToHex(tx.MaxPriorityFeePerGas)
+	} else {
+		args.GasPrice = hexnum.BigIntToHex(tx.GasPrice)
+	}
+	response, err := s.client.Call(urpc.NewRequest(remoteSignMethod, args))
+	if err != nil {
+		return nil, err
+	}
+	var raw string
+	if err = response.ParseResult(&raw); err != nil {
+		result := &struct {
+			Raw string `json:"raw"`
+		}{}
+		if err = response.ParseResult(result); err != nil {
+			return nil, ErrRemoteSignerResponse
+		}
+		raw = result.Raw
+	}
+	signedTx, err = hexnum.ParseHexBytes(raw)
+	if err != nil || len(signedTx) == 0 {
+		return nil, ErrRemoteSignerResponse
+	}
+	signed, from, err := crypto.DecodeSignedTx(signedTx)
+	if err != nil {
+		return nil, ErrRemoteSignerResponse
+	}
+	if !bytes.Equal(from, s.address) || !signed.Equal(tx) {
+		return nil, ErrSignedTxMismatch
+	}
+	return signedTx, nil
+}
@@ -0,0 +1,136 @@
+package remotesigner
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ITProLabDev/ethbacknode/common/hexnum"
+	"github.com/ITProLabDev/ethbacknode/crypto"
+)
+
+// EIP-155 example transaction signed with the key 0x4646...46.
+const (
+	testPrivateKey = "4646464646464646464646464646464646464646464646464646464646464646"
+	testAddress    = "9d8a62f656a8d1615c1294fd71e9cfb3e4855a4f"
+	testSignedTx   = "f86c098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a76400008025a028ef61340bd939bc2195fe537567866003e1a15d3c71ff63e1590620aa636276a067cbe9d8997f761aecb703304b3800ccf555c9f3dc64214b297fb1966a3b6d83"
+)
+
+func testTx() *crypto.Tx {
+	value, _ := new(big.Int).SetString("1000000000000000000", 10)
+	return &crypto.Tx{
+		ChainId:  big.NewInt(1),
+		Nonce:    9,
+		To:       bytes.Repeat([]byte{0x35}, 20),
+		Value:    value,
+		Gas:      21000,
+		GasPrice: big.NewInt(20000000000),
+	}
+}
+
+func testLocalSigner(t *testing.T, privateKeyHex string) *crypto.LocalSigner {
+	t.Helper()
+	privateKey, _ := hex.DecodeString(privateKeyHex)
+	signer, err := crypto.NewLocalSigner(privateKey)
+	if err != nil {
+		t.Fatalf("NewLocalSigner: %v", err)
+	}
+	return signer
+}
+
+// testRemoteSigner starts a remote signer answering eth_signTransaction with the
+// transaction signed by sign, in the Clef result format if clef is set.
+func testRemoteSigner(t *testing.T, clef bool, sign func(args remoteTxArgs) []byte) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Id     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params []remoteTxArgs  `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || len(request.Params) != 1 {
+			t.Errorf("bad request: %v", err)
+			return
+		}
+		if request.Method != remoteSignMethod {
+			t.Errorf("method: got %s", request.Method)
+		}
+		signedTx := sign(request.Params[0])
+		var result interface{} = hexnum.BytesToHex(signedTx)
+		if clef {
+			result = map[string]interface{}{"raw": hexnum.BytesToHex(signedTx), "tx": map[string]string{}}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": request.Id, "result": result})
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestRemoteSigner_SignTransaction(t *testing.T) {
+	local := testLocalSigner(t, testPrivateKey)
+	for _, clef := range []bool{false, true} {
+		url := testRemoteSigner(t, clef, func(args remoteTxArgs) []byte {
+			if args.From != "0x"+testAddress || args.GasPrice != "0x4a817c800" || args.MaxFeePerGas != "" {
+				t.Errorf("unexpected args: %+v", args)
+			}
+			signedTx, err := local.SignTx(testTx())
+			if err != nil {
+				t.Errorf("SignTx: %v", err)
+			}
+			return signedTx
+		})
+		signer := NewRemoteSigner(url, nil, local.Address())
+		signedTx, err := signer.SignTx(testTx())
+		if err != nil {
+			t.Fatalf("clef %v: SignTx: %v", clef, err)
+		}
+		if got := hex.EncodeToString(signedTx); got != testSignedTx {
+			t.Fatalf("clef %v: signed tx: got %s", clef, got)
+		}
+	}
+}
+
+func TestRemoteSigner_RejectsAnotherTransaction(t *testing.T) {
+	local := testLocalSigner(t, testPrivateKey)
+	other := testLocalSigner(t, "4747474747474747474747474747474747474747474747474747474747474747")
+	tests := []struct {
+		name   string
+		signer *crypto.LocalSigner
+		change func(tx *crypto.Tx)
+	}{
+		{"nonce", local, func(tx *crypto.Tx) { tx.Nonce++ }},
+		{"to", local, func(tx *crypto.Tx) { tx.To = bytes.Repeat([]byte{0x36}, 20) }},
+		{"value", local, func(tx *crypto.Tx) { tx.Value = big.NewInt(2) }},
+		{"data", local, func(tx *crypto.Tx) { tx.Data = []byte{0x01} }},
+		{"gas", local, func(tx *crypto.Tx) { tx.Gas = 50000 }},
+		{"chain id", local, func(tx *crypto.Tx) { tx.ChainId = big.NewInt(5) }},
+		{"gas price", local, func(tx *crypto.Tx) { tx.GasPrice = big.NewInt(30000000000) }},
+		{"sender", other, func(tx *crypto.Tx) {}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := testRemoteSigner(t, false, func(args remoteTxArgs) []byte {
+				tx := testTx()
+				tt.change(tx)
+				signedTx, err := tt.signer.SignTx(tx)
+				if err != nil {
+					t.Errorf("SignTx: %v", err)
+				}
+				return signedTx
+			})
+			signer := NewRemoteSigner(url, nil, local.Address())
+			if _, err := signer.SignTx(testTx()); !errors.Is(err, ErrSignedTxMismatch) {
+				t.Fatalf("SignTx: got %v, want ErrSignedTxMismatch", err)
+			}
+		})
+	}
+	url := testRemoteSigner(t, false, func(args remoteTxArgs) []byte { return []byte{0xc0} })
+	if _, err := NewRemoteSigner(url, nil, local.Address()).SignTx(testTx()); !errors.Is(err, ErrRemoteSignerResponse) {
+		t.Fatalf("SignTx malformed: got %v, want ErrRemoteSignerResponse", err)
+	}
+}
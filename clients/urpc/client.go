@@ -3,7 +3,6 @@
 package urpc
 
 import (
-	"errors"
 	"net/http"
 )
 
@@ -89,7 +88,7 @@ func (c *Client) Call(request *Request) (response *Response, err error) {
 		return nil, err
 	}
 	if response.Error != nil {
-		return response, errors.New(response.Error.Message)
+		return response, response.ParseError()
 	}
 	return response, nil
 }
@@ -1,5 +1,10 @@
 package urpc
 
+import (
+	"errors"
+	"strings"
+)
+
 // WarpedError wraps a JSON-RPC error as a Go error.
 // Implements the error interface.
 type WarpedError struct {
@@ -11,3 +16,14 @@ type WarpedError struct {
 func (err *WarpedError) Error() string {
 	return err.Message
 }
+
+// IsMethodNotFound reports whether the error is the JSON-RPC "method not found"
+// error returned by nodes that do not implement the called method.
+func IsMethodNotFound(err error) bool {
+	var rpcErr *WarpedError
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	return rpcErr.Code == ERROR_CODE_METHOD_NOT_FOUND ||
+		strings.Contains(strings.ToLower(rpcErr.Message), "method not found")
+}
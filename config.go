@@ -22,21 +22,25 @@ var (
 // Config holds the global application configuration.
 // It supports both HCL (primary) and JSON (legacy) formats.
 type Config struct {
-	storage           storage.BinStorage `json:"-"`
-	NodeUrl           string             `json:"nodeUrl" hcl:"nodeUrl,attr"`
-	NodePort          string             `json:"nodePort" hcl:"nodePort,attr"`
-	NodeUseSSl        bool               `json:"nodeUseSSL" hcl:"nodeUseSSL,attr"`
-	NodeUseIPC        bool               `json:"nodeUseIPC" hcl:"nodeUseIPC,attr"`
-	NodeIPCSocket     string             `json:"nodeIPCSocket" hcl:"nodeIPCSocket,attr"`
-	RpcAddress        string             `json:"rpcAddress" hcl:"rpcAddress,attr"`
-	RpcPort           string             `json:"rpcPort" hcl:"rpcPort,attr"`
-	DataPath          string             `json:"dataPath" hcl:"dataPath,attr"`
-	DebugMode         bool               `json:"debug_mode" hcl:"debugMode,attr"`
-	ParamsFlags       map[string]bool    `json:"flags" hcl:"flags,optional"`
-	ParamsString      map[string]string  `json:"paramsString" hcl:"paramsString,optional"`
-	ParamsInt         map[string]int     `json:"paramsInt" hcl:"paramsInt,optional"`
-	AdditionalHeaders map[string]string  `json:"additionalHeaders" hcl:"additionalHeaders,optional"`
-	BurnAddress       string             `json:"burnAddress" hcl:"burnAddress,attr"`
+	storage             storage.BinStorage `json:"-"`
+	NodeUrl             string             `json:"nodeUrl" hcl:"nodeUrl,attr"`
+	NodePort            string             `json:"nodePort" hcl:"nodePort,attr"`
+	NodeUseSSl          bool               `json:"nodeUseSSL" hcl:"nodeUseSSL,attr"`
+	NodeUseIPC          bool               `json:"nodeUseIPC" hcl:"nodeUseIPC,attr"`
+	NodeIPCSocket       string             `json:"nodeIPCSocket" hcl:"nodeIPCSocket,attr"`
+	RpcAddress          string             `json:"rpcAddress" hcl:"rpcAddress,attr"`
+	RpcPort             string             `json:"rpcPort" hcl:"rpcPort,attr"`
+	DataPath            string             `json:"dataPath" hcl:"dataPath,attr"`
+	DebugMode           bool               `json:"debug_mode" hcl:"debugMode,attr"`
+	ParamsFlags         map[string]bool    `json:"flags" hcl:"flags,optional"`
+	ParamsString        map[string]string  `json:"paramsString" hcl:"paramsString,optional"`
+	ParamsInt           map[string]int     `json:"paramsInt" hcl:"paramsInt,optional"`
+	AdditionalHeaders   map[string]string  `json:"additionalHeaders" hcl:"additionalHeaders,optional"`
+	BurnAddress         string             `json:"burnAddress" hcl:"burnAddress,attr"`
+	EncryptKeys         bool               `json:"encryptKeys" hcl:"encryptKeys,optional"`
+	KeyPassphraseFile   string             `json:"keyPassphraseFile" hcl:"keyPassphraseFile,optional"`
+	RemoteSignerUrl     string             `json:"remoteSignerUrl" hcl:"remoteSignerUrl,optional"`
+	RemoteSignerHeaders map[string]string  `json:"remoteSignerHeaders" hcl:"remoteSignerHeaders,optional"`
 }
 
 // _configDefaultStorage creates and returns the default configuration storage.
@@ -122,6 +126,9 @@ func (c *Config) Save() (err error) {
 	body.SetAttributeValue("dataPath", cty.StringVal(c.DataPath))
 	body.SetAttributeValue("debugMode", cty.BoolVal(c.DebugMode))
 	body.SetAttributeValue("burnAddress", cty.StringVal(c.BurnAddress))
+	body.SetAttributeValue("encryptKeys", cty.BoolVal(c.EncryptKeys))
+	body.SetAttributeValue("keyPassphraseFile", cty.StringVal(c.KeyPassphraseFile))
+	body.SetAttributeValue("remoteSignerUrl", cty.StringVal(c.RemoteSignerUrl))
 
 	// Set optional maps
 	if len(c.ParamsFlags) > 0 {
@@ -152,6 +159,13 @@ func (c *Config) Save() (err error) {
 		}
 		body.SetAttributeValue("additionalHeaders", cty.MapVal(headerMap))
 	}
+	if len(c.RemoteSignerHeaders) > 0 {
+		headerMap := make(map[string]cty.Value)
+		for k, v := range c.RemoteSignerHeaders {
+			headerMap[k] = cty.StringVal(v)
+		}
+		body.SetAttributeValue("remoteSignerHeaders", cty.MapVal(headerMap))
+	}
 
 	data := hclwrite.Format(f.Bytes())
 	err = c.storage.Save(data)
@@ -0,0 +1,21 @@
+package crypto
+
+import "errors"
+
+// Error definitions for signing operations.
+var (
+	// ErrInvalidPrivateKey is returned for a private key which is not a valid secp256k1 key.
+	ErrInvalidPrivateKey = errors.New("invalid private key")
+	// ErrSignFailed is returned when the transaction signature can not be created.
+	ErrSignFailed = errors.New("can not sign transaction")
+	// ErrSignedTxInvalid is returned for a signed transaction which can not be decoded.
+	ErrSignedTxInvalid = errors.New("invalid signed transaction")
+	// ErrKeystoreInvalid is returned for a malformed keystore file.
+	ErrKeystoreInvalid = errors.New("invalid keystore file")
+	// ErrKeystoreUnsupported is returned for a keystore version, cipher or KDF which is not supported.
+	ErrKeystoreUnsupported = errors.New("unsupported keystore file")
+	// ErrKeystoreWrongPassphrase is returned when the keystore MAC does not match the passphrase.
+	ErrKeystoreWrongPassphrase = errors.New("wrong keystore passphrase")
+	// ErrKeystoreAddressMismatch is returned when the keystore address does not match its key.
+	ErrKeystoreAddressMismatch = errors.New("keystore address and private key mismatch")
+)
@@ -0,0 +1,119 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"github.com/ITProLabDev/ethbacknode/common/rlp"
+	"math/big"
+)
+
+// DynamicFeeTxType is the EIP-2718 envelope type of EIP-1559 transactions.
+const DynamicFeeTxType = 0x02
+
+// AccessTuple is an EIP-2930 access list entry: an address and the storage keys it accesses.
+type AccessTuple struct {
+	Address     []byte   // 20 bytes account address
+	StorageKeys [][]byte // 32 bytes storage keys
+}
+
+// AccessList is an EIP-2930 access list.
+type AccessList []AccessTuple
+
+// NewEthDynamicFeeTxSigner creates a new EIP-1559 transaction signer with the given parameters.
+func NewEthDynamicFeeTxSigner(chainId *big.Int, nonce uint64, maxPriorityFeePerGas, maxFeePerGas *big.Int, gas uint64, To []byte, value *big.Int, data []byte) *EthDynamicFeeTxSigner {
+	return &EthDynamicFeeTxSigner{
+		ChainId:              chainId,
+		Nonce:                nonce,
+		MaxPriorityFeePerGas: maxPriorityFeePerGas,
+		MaxFeePerGas:         maxFeePerGas,
+		Gas:                  gas,
+		To:                   &To,
+		Value:                value,
+		Data:                 data,
+	}
+}
+
+// EthDynamicFeeTxSigner represents an EIP-1559 (type 2) Ethereum transaction
+// with signing capabilities. Encoded as a typed envelope: 0x02 || rlp(fields).
+type EthDynamicFeeTxSigner struct {
+	ChainId              *big.Int   // chain ID, part of the signed payload
+	Nonce                uint64     // nonce of sender account
+	MaxPriorityFeePerGas *big.Int   // wei per gas paid to the block producer
+	MaxFeePerGas         *big.Int   // max wei per gas including base fee
+	Gas                  uint64     // gas limit
+	To                   *[]byte    `rlp:"nil"` // nil means contract creation
+	Value                *big.Int   // wei amount
+	Data                 []byte     // contract invocation input data
+	AccessList           AccessList // EIP-2930 access list
+	V, R, S              *big.Int   // signature values, V is the y parity (0 or 1)
+}
+
+// SetChainId sets the chain ID for replay protection.
+func (tx *EthDynamicFeeTxSigner) SetChainId(id *big.Int) {
+	tx.ChainId = id
+}
+
+// Sign creates an ECDSA signature for the transaction using RFC 6979.
+// Returns 65-byte signature in [R || S || V] format and populates V, R, S fields.
+func (tx *EthDynamicFeeTxSigner) Sign(privateKey *ecdsa.PrivateKey) (sig []byte) {
+	if tx.ChainId == nil {
+		tx.ChainId = big.NewInt(1)
+	}
+	if tx.AccessList == nil {
+		tx.AccessList = AccessList{}
+	}
+	digestHash := tx.Hash()
+	refId, r, s := SignEcdsaRfc6979(privateKey, digestHash, sha256.New)
+
+	sig = make([]byte, 65)
+	copy(sig, padBytes(r.Bytes(), 32))
+	copy(sig[32:], padBytes(s.Bytes(), 32))
+	sig[64] = refId
+
+	tx.V = big.NewInt(int64(refId))
+	tx.R = r
+	tx.S = s
+	return sig
+}
+
+// EncodeRPL encodes the signed transaction as a typed envelope for broadcasting.
+func (tx *EthDynamicFeeTxSigner) EncodeRPL() (data []byte, err error) {
+	if tx.AccessList == nil {
+		tx.AccessList = AccessList{}
+	}
+	rplBuf := new(bytes.Buffer)
+	rplBuf.WriteByte(DynamicFeeTxType)
+	err = rlp.Encode(rplBuf, tx)
+	if err != nil {
+		return nil, err
+	}
+	data = rplBuf.Bytes()
+	return data, nil
+}
+
+// Hash computes the EIP-1559 signing hash: keccak256(0x02 || rlp(unsigned fields)).
+func (tx *EthDynamicFeeTxSigner) Hash() []byte {
+	return prefixedRlpHash(DynamicFeeTxType, []interface{}{
+		tx.ChainId,
+		tx.Nonce,
+		tx.MaxPriorityFeePerGas,
+		tx.MaxFeePerGas,
+		tx.Gas,
+		tx.To,
+		tx.Value,
+		tx.Data,
+		tx.AccessList,
+	})
+}
+
+// prefixedRlpHash computes Keccak-256 hash of the type prefixed RLP-encoded data.
+func prefixedRlpHash(prefix byte, x interface{}) []byte {
+	rplBuffer := new(bytes.Buffer)
+	rplBuffer.WriteByte(prefix)
+	err := rlp.Encode(rplBuffer, x)
+	if err != nil {
+		panic(err)
+	}
+	return Keccak256(rplBuffer.Bytes())
+}
@@ -0,0 +1,31 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+// EIP-1559 transaction signed by go-ethereum (types.LondonSigner) with the key 0x4646...46.
+const (
+	testSignedDynamicFeeTx   = "02f877010984773594008506fc23ac00825208943535353535353535353535353535353535353535880de0b6b3a764000084deadbeefc080a0dc4debd19bab56f8ad4f41dbbe5b875f9ae3fa94c3320aa306565a5e7020e2e9a05e6da4462db96b38fa234aa5a2dfddcf5a0e9c3ad87bfa0b3d5db40e77d5bb87"
+	testSignedDynamicFeeTxId = "d7ca4d35c2bc9e7d0bb4eee829118dd6bbc96754b87cff31f228e6c72c68456d"
+)
+
+func TestEthDynamicFeeTxSigner_GethVector(t *testing.T) {
+	tx := testTx()
+	tx.GasPrice = nil
+	tx.MaxPriorityFeePerGas = big.NewInt(2000000000)
+	tx.MaxFeePerGas = big.NewInt(30000000000)
+	tx.Data = []byte{0xde, 0xad, 0xbe, 0xef}
+	signedTx, err := testLocalSigner(t).SignTx(tx)
+	if err != nil {
+		t.Fatalf("SignTx: %v", err)
+	}
+	if got := hex.EncodeToString(signedTx); got != testSignedDynamicFeeTx {
+		t.Fatalf("signed tx:\n got %s\nwant %s", got, testSignedDynamicFeeTx)
+	}
+	if got := hex.EncodeToString(Keccak256(signedTx)); got != testSignedDynamicFeeTxId {
+		t.Fatalf("tx hash: got %s, want %s", got, testSignedDynamicFeeTxId)
+	}
+}
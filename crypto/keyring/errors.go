@@ -0,0 +1,14 @@
+package keyring
+
+import "errors"
+
+var (
+	// ErrWrongPassphrase is returned when the keyring can not be unlocked with the passphrase.
+	ErrWrongPassphrase = errors.New("wrong keyring passphrase")
+	// ErrUnsupportedKeyring is returned for a keyring file of an unknown version or KDF.
+	ErrUnsupportedKeyring = errors.New("unsupported keyring format")
+	// ErrEmptyPassphrase is returned when the keyring is locked with an empty passphrase.
+	ErrEmptyPassphrase = errors.New("empty keyring passphrase")
+	// ErrSealedDataInvalid is returned when sealed data is malformed or fails authentication.
+	ErrSealedDataInvalid = errors.New("sealed data invalid")
+)
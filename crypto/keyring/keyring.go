@@ -0,0 +1,148 @@
+// Package keyring provides envelope encryption of key material at rest.
+// A random master key encrypts every record with XChaCha20-Poly1305. The master
+// key itself is stored wrapped by a key derived from a passphrase with Argon2id,
+// so changing the passphrase rewraps the master key only and leaves the
+// encrypted records untouched.
+package keyring
+
+import (
+	"crypto/rand"
+	"encoding/json"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Keyring file format and Argon2id parameters of new keyring files.
+const (
+	fileVersion   = 1
+	kdfArgon2id   = "argon2id"
+	argon2Time    = 3
+	argon2Memory  = 64 * 1024 // KiB
+	argon2Threads = 4
+	saltLength    = 16
+)
+
+// sealedVersion is the first byte of the sealed data.
+const sealedVersion = 1
+
+// File is the persisted keyring: the master key wrapped by the passphrase derived key.
+type File struct {
+	Version    int    `json:"version"`
+	Kdf        string `json:"kdf"`
+	Salt       []byte `json:"salt"`
+	Time       uint32 `json:"time"`
+	Memory     uint32 `json:"memory"`
+	Threads    uint8  `json:"threads"`
+	Nonce      []byte `json:"nonce"`
+	WrappedKey []byte `json:"wrappedKey"`
+}
+
+// Keyring holds the unlocked master key.
+type Keyring struct {
+	masterKey []byte
+}
+
+// New creates a keyring with a new random master key.
+func New() (k *Keyring, err error) {
+	k = &Keyring{masterKey: make([]byte, chacha20poly1305.KeySize)}
+	_, err = rand.Read(k.masterKey)
+	if err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Unlock decodes the keyring file and unwraps the master key with the passphrase.
+// Returns ErrWrongPassphrase if the passphrase does not match.
+func Unlock(data []byte, passphrase []byte) (k *Keyring, err error) {
+	file := new(File)
+	err = json.Unmarshal(data, file)
+	if err != nil {
+		return nil, err
+	}
+	if file.Version != fileVersion || file.Kdf != kdfArgon2id {
+		return nil, ErrUnsupportedKeyring
+	}
+	aead, err := chacha20poly1305.NewX(file.deriveKey(passphrase))
+	if err != nil {
+		return nil, err
+	}
+	if len(file.Nonce) != aead.NonceSize() {
+		return nil, ErrUnsupportedKeyring
+	}
+	masterKey, err := aead.Open(nil, file.Nonce, file.WrappedKey, []byte(file.Kdf))
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return &Keyring{masterKey: masterKey}, nil
+}
+
+// Lock wraps the master key with the passphrase and returns the keyring file data.
+// A new salt is used every time, so it also serves to change the passphrase.
+func (k *Keyring) Lock(passphrase []byte) (data []byte, err error) {
+	if len(passphrase) == 0 {
+		return nil, ErrEmptyPassphrase
+	}
+	file := &File{
+		Version: fileVersion,
+		Kdf:     kdfArgon2id,
+		Salt:    make([]byte, saltLength),
+		Time:    argon2Time,
+		Memory:  argon2Memory,
+		Threads: argon2Threads,
+		Nonce:   make([]byte, chacha20poly1305.NonceSizeX),
+	}
+	_, err = rand.Read(file.Salt)
+	if err != nil {
+		return nil, err
+	}
+	_, err = rand.Read(file.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.NewX(file.deriveKey(passphrase))
+	if err != nil {
+		return nil, err
+	}
+	file.WrappedKey = aead.Seal(nil, file.Nonce, k.masterKey, []byte(file.Kdf))
+	return json.MarshalIndent(file, "", " ")
+}
+
+// Seal encrypts the plaintext with the master key. additionalData is authenticated
+// but not encrypted, it binds the sealed data to its record (e.g. the address).
+func (k *Keyring) Seal(plaintext, additionalData []byte) (sealed []byte, err error) {
+	aead, err := chacha20poly1305.NewX(k.masterKey)
+	if err != nil {
+		return nil, err
+	}
+	sealed = make([]byte, 1+aead.NonceSize(), 1+aead.NonceSize()+len(plaintext)+aead.Overhead())
+	sealed[0] = sealedVersion
+	_, err = rand.Read(sealed[1:])
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(sealed, sealed[1:], plaintext, additionalData), nil
+}
+
+// Open decrypts the data sealed by Seal with the same additionalData.
+func (k *Keyring) Open(sealed, additionalData []byte) (plaintext []byte, err error) {
+	aead, err := chacha20poly1305.NewX(k.masterKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < 1+aead.NonceSize()+aead.Overhead() || sealed[0] != sealedVersion {
+		return nil, ErrSealedDataInvalid
+	}
+	nonce := sealed[1 : 1+aead.NonceSize()]
+	plaintext, err = aead.Open(nil, nonce, sealed[1+aead.NonceSize():], additionalData)
+	if err != nil {
+		return nil, ErrSealedDataInvalid
+	}
+	return plaintext, nil
+}
+
+// deriveKey derives the master key wrapping key from the passphrase.
+func (f *File) deriveKey(passphrase []byte) []byte {
+	return argon2.IDKey(passphrase, f.Salt, f.Time, f.Memory, f.Threads, chacha20poly1305.KeySize)
+}
@@ -0,0 +1,42 @@
+package keyring
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestKeyring_LockUnlock(t *testing.T) {
+	k, err := New()
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	sealed, err := k.Seal([]byte("secret"), []byte("address"))
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	data, err := k.Lock([]byte("passphrase"))
+	if err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	if _, err = Unlock(data, []byte("wrong")); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("Unlock with wrong passphrase: got %v, want ErrWrongPassphrase", err)
+	}
+	unlocked, err := Unlock(data, []byte("passphrase"))
+	if err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	plaintext, err := unlocked.Open(sealed, []byte("address"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if !bytes.Equal(plaintext, []byte("secret")) {
+		t.Fatalf("Open = %q, want %q", plaintext, "secret")
+	}
+	if _, err = unlocked.Open(sealed, []byte("other address")); !errors.Is(err, ErrSealedDataInvalid) {
+		t.Fatalf("Open with other additional data: got %v, want ErrSealedDataInvalid", err)
+	}
+	if _, err = k.Lock(nil); !errors.Is(err, ErrEmptyPassphrase) {
+		t.Fatalf("Lock with empty passphrase: got %v, want ErrEmptyPassphrase", err)
+	}
+}
@@ -0,0 +1,240 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Scrypt parameters of the keystore files. Standard parameters match the wallets
+// defaults, light ones are cheaper to derive and meant for tests and low memory hosts.
+const (
+	StandardScryptN = 1 << 18
+	StandardScryptP = 1
+	LightScryptN    = 1 << 12
+	LightScryptP    = 6
+)
+
+// Keystore V3 format constants.
+const (
+	keystoreVersion = 3
+	keystoreCipher  = "aes-128-ctr"
+	kdfScrypt       = "scrypt"
+	kdfPbkdf2       = "pbkdf2"
+	pbkdf2Prf       = "hmac-sha256"
+	scryptR         = 8
+	keystoreDkLen   = 32
+)
+
+// Upper bounds of the KDF parameters accepted from a keystore file. The parameters
+// come from the file, unbounded ones let a crafted file exhaust CPU and memory.
+const (
+	maxScryptN       = 1 << 20
+	maxScryptRP      = 1 << 8
+	maxScryptMemory  = 1 << 30 // 128 * r * n bytes
+	maxPbkdf2C       = 1 << 22
+	maxKeystoreDkLen = 64
+	maxKdfRuns       = 2
+)
+
+// kdfSlots limits the key derivations running at once, each scrypt run may allocate
+// up to maxScryptMemory.
+var kdfSlots = make(chan struct{}, maxKdfRuns)
+
+// runKdf runs the key derivation once a derivation slot is free.
+func runKdf(derive func() ([]byte, error)) ([]byte, error) {
+	kdfSlots <- struct{}{}
+	defer func() { <-kdfSlots }()
+	return derive()
+}
+
+// Keystore is a Web3 Secret Storage V3 file.
+type Keystore struct {
+	Address string         `json:"address,omitempty"`
+	Crypto  KeystoreCrypto `json:"crypto"`
+	Id      string         `json:"id"`
+	Version int            `json:"version"`
+}
+
+// KeystoreCrypto is the encrypted private key of the keystore file.
+type KeystoreCrypto struct {
+	Cipher       string         `json:"cipher"`
+	CipherText   string         `json:"ciphertext"`
+	CipherParams KeystoreCipher `json:"cipherparams"`
+	Kdf          string         `json:"kdf"`
+	KdfParams    KeystoreKdf    `json:"kdfparams"`
+	Mac          string         `json:"mac"`
+}
+
+// KeystoreCipher holds the cipher parameters.
+type KeystoreCipher struct {
+	IV string `json:"iv"`
+}
+
+// KeystoreKdf holds the parameters of both scrypt (n, r, p) and pbkdf2 (c, prf).
+type KeystoreKdf struct {
+	DkLen int    `json:"dklen"`
+	Salt  string `json:"salt"`
+	N     int    `json:"n,omitempty"`
+	R     int    `json:"r,omitempty"`
+	P     int    `json:"p,omitempty"`
+	C     int    `json:"c,omitempty"`
+	Prf   string `json:"prf,omitempty"`
+}
+
+// EncryptKeystore encrypts the private key with the passphrase into a keystore V3
+// file with the scrypt KDF. scryptN and scryptP are StandardScryptN and
+// StandardScryptP unless the file is meant for tests.
+func EncryptKeystore(privateKey, passphrase []byte, scryptN, scryptP int) (data []byte, err error) {
+	signer, err := NewLocalSigner(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 32)
+	iv := make([]byte, aes.BlockSize)
+	id := make([]byte, 16)
+	for _, b := range [][]byte{salt, iv, id} {
+		if _, err = rand.Read(b); err != nil {
+			return nil, err
+		}
+	}
+	derivedKey, err := runKdf(func() ([]byte, error) {
+		return scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, keystoreDkLen)
+	})
+	if err != nil {
+		return nil, err
+	}
+	cipherText, err := aesCTRXOR(derivedKey[:16], privateKey, iv)
+	if err != nil {
+		return nil, err
+	}
+	// random UUID version 4
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+	return json.Marshal(&Keystore{
+		Address: hex.EncodeToString(signer.Address()),
+		Crypto: KeystoreCrypto{
+			Cipher:       keystoreCipher,
+			CipherText:   hex.EncodeToString(cipherText),
+			CipherParams: KeystoreCipher{IV: hex.EncodeToString(iv)},
+			Kdf:          kdfScrypt,
+			KdfParams: KeystoreKdf{
+				DkLen: keystoreDkLen,
+				Salt:  hex.EncodeToString(salt),
+				N:     scryptN,
+				R:     scryptR,
+				P:     scryptP,
+			},
+			Mac: hex.EncodeToString(Keccak256(derivedKey[16:32], cipherText)),
+		},
+		Id:      fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:]),
+		Version: keystoreVersion,
+	})
+}
+
+// DecryptKeystore decrypts the private key of the keystore V3 file with the passphrase.
+// Returns ErrKeystoreWrongPassphrase if the MAC does not match.
+func DecryptKeystore(data, passphrase []byte) (privateKey []byte, err error) {
+	keystore := new(Keystore)
+	err = json.Unmarshal(data, keystore)
+	if err != nil {
+		return nil, ErrKeystoreInvalid
+	}
+	c := &keystore.Crypto
+	if keystore.Version != keystoreVersion || c.Cipher != keystoreCipher {
+		return nil, ErrKeystoreUnsupported
+	}
+	cipherText, err := hex.DecodeString(c.CipherText)
+	if err != nil {
+		return nil, ErrKeystoreInvalid
+	}
+	iv, err := hex.DecodeString(c.CipherParams.IV)
+	if err != nil || len(iv) != aes.BlockSize {
+		return nil, ErrKeystoreInvalid
+	}
+	mac, err := hex.DecodeString(c.Mac)
+	if err != nil {
+		return nil, ErrKeystoreInvalid
+	}
+	derivedKey, err := c.deriveKey(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(Keccak256(derivedKey[16:32], cipherText), mac) {
+		return nil, ErrKeystoreWrongPassphrase
+	}
+	privateKey, err = aesCTRXOR(derivedKey[:16], cipherText, iv)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := NewLocalSigner(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	if keystore.Address != "" {
+		address, err := hex.DecodeString(strings.TrimPrefix(strings.ToLower(keystore.Address), "0x"))
+		if err != nil || !bytes.Equal(address, signer.Address()) {
+			return nil, ErrKeystoreAddressMismatch
+		}
+	}
+	return privateKey, nil
+}
+
+// deriveKey derives the decryption key from the passphrase with the keystore KDF.
+func (c *KeystoreCrypto) deriveKey(passphrase []byte) (derivedKey []byte, err error) {
+	params := &c.KdfParams
+	salt, err := hex.DecodeString(params.Salt)
+	if err != nil {
+		return nil, ErrKeystoreInvalid
+	}
+	if params.DkLen < keystoreDkLen || params.DkLen > maxKeystoreDkLen {
+		return nil, ErrKeystoreUnsupported
+	}
+	switch c.Kdf {
+	case kdfScrypt:
+		if !validScryptParams(params.N, params.R, params.P) {
+			return nil, ErrKeystoreUnsupported
+		}
+		return runKdf(func() ([]byte, error) {
+			return scrypt.Key(passphrase, salt, params.N, params.R, params.P, params.DkLen)
+		})
+	case kdfPbkdf2:
+		if params.Prf != pbkdf2Prf || params.C <= 0 || params.C > maxPbkdf2C {
+			return nil, ErrKeystoreUnsupported
+		}
+		return runKdf(func() ([]byte, error) {
+			return pbkdf2.Key(passphrase, salt, params.C, params.DkLen, sha256.New), nil
+		})
+	}
+	return nil, ErrKeystoreUnsupported
+}
+
+// validScryptParams reports whether the scrypt parameters are valid and within the
+// bounds: n is a power of two not above maxScryptN, r * p and the memory are capped.
+func validScryptParams(n, r, p int) bool {
+	if n <= 1 || n > maxScryptN || n&(n-1) != 0 || r <= 0 || p <= 0 {
+		return false
+	}
+	return r <= maxScryptRP/p && r <= maxScryptMemory/(128*n)
+}
+
+// aesCTRXOR encrypts or decrypts the data with AES-128 in CTR mode.
+func aesCTRXOR(key, data, iv []byte) (result []byte, err error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	result = make([]byte, len(data))
+	cipher.NewCTR(block, iv).XORKeyStream(result, data)
+	return result, nil
+}
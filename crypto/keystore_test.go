@@ -0,0 +1,107 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Test vectors of the Web3 Secret Storage Definition, passphrase "testpassword".
+const (
+	keystoreVectorKey    = "7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d"
+	keystoreVectorPbkdf2 = `{"crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"6087dab2f9fdbbfaddc31a909735c1e6"},"ciphertext":"5318b4d5bcd28de64ee5559e671353e16f075ecae9f99c7a79a38af5f869aa46","kdf":"pbkdf2","kdfparams":{"c":262144,"dklen":32,"prf":"hmac-sha256","salt":"ae3cd4e7013836a3df6bd7241b12db061dbe2c6785853cce422d148a624ce0bd"},"mac":"517ead924a9d0dc3124507e3393d175ce3ff7c1e96529c6c555ce9e51205e9b2"},"id":"3198bc9c-6672-5ab3-d995-4942343ae5b6","version":3}`
+	keystoreVectorScrypt = `{"crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"83dbcc02d8ccb40e466191a123791e0e"},"ciphertext":"d172bf743a674da9cdad04534d56926ef8358534d458fffccd4e6ad2fbde479c","kdf":"scrypt","kdfparams":{"dklen":32,"n":262144,"r":1,"p":8,"salt":"ab0c7876052600dd703518d6fc3fe8984592145b591fc8fb5c6d43190334ba19"},"mac":"2103ac29920d71da29f15d75b4a16dbe95cfd7ff8faea1056c33131d846e3097"},"id":"3198bc9c-6672-5ab3-d995-4942343ae5b6","version":3}`
+)
+
+func TestDecryptKeystore_Vectors(t *testing.T) {
+	for kdf, data := range map[string]string{"pbkdf2": keystoreVectorPbkdf2, "scrypt": keystoreVectorScrypt} {
+		privateKey, err := DecryptKeystore([]byte(data), []byte("testpassword"))
+		if err != nil {
+			t.Fatalf("%s: DecryptKeystore: %v", kdf, err)
+		}
+		if got := hex.EncodeToString(privateKey); got != keystoreVectorKey {
+			t.Fatalf("%s: private key: got %s, want %s", kdf, got, keystoreVectorKey)
+		}
+		if _, err = DecryptKeystore([]byte(data), []byte("wrong")); !errors.Is(err, ErrKeystoreWrongPassphrase) {
+			t.Fatalf("%s: wrong passphrase: got %v, want ErrKeystoreWrongPassphrase", kdf, err)
+		}
+	}
+}
+
+func TestEncryptKeystore_RoundTrip(t *testing.T) {
+	privateKey, _ := hex.DecodeString(testPrivateKey)
+	data, err := EncryptKeystore(privateKey, []byte("passphrase"), LightScryptN, LightScryptP)
+	if err != nil {
+		t.Fatalf("EncryptKeystore: %v", err)
+	}
+	decrypted, err := DecryptKeystore(data, []byte("passphrase"))
+	if err != nil {
+		t.Fatalf("DecryptKeystore: %v", err)
+	}
+	if hex.EncodeToString(decrypted) != testPrivateKey {
+		t.Fatalf("private key: got %x", decrypted)
+	}
+}
+
+func TestDecryptKeystore_RejectsKdfParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		vector string
+		modify func(params *KeystoreKdf)
+	}{
+		{"scrypt n not a power of two", keystoreVectorScrypt, func(params *KeystoreKdf) { params.N = 262143 }},
+		{"scrypt n too small", keystoreVectorScrypt, func(params *KeystoreKdf) { params.N = 1 }},
+		{"scrypt n too large", keystoreVectorScrypt, func(params *KeystoreKdf) { params.N = 1 << 21 }},
+		{"scrypt r * p too large", keystoreVectorScrypt, func(params *KeystoreKdf) { params.R, params.P = 1<<5, 1<<4 }},
+		{"scrypt memory too large", keystoreVectorScrypt, func(params *KeystoreKdf) { params.N, params.R, params.P = 1<<20, 16, 1 }},
+		{"scrypt r zero", keystoreVectorScrypt, func(params *KeystoreKdf) { params.R = 0 }},
+		{"scrypt p negative", keystoreVectorScrypt, func(params *KeystoreKdf) { params.P = -1 }},
+		{"dklen too small", keystoreVectorScrypt, func(params *KeystoreKdf) { params.DkLen = 16 }},
+		{"dklen too large", keystoreVectorScrypt, func(params *KeystoreKdf) { params.DkLen = 1 << 20 }},
+		{"pbkdf2 c too large", keystoreVectorPbkdf2, func(params *KeystoreKdf) { params.C = 1 << 30 }},
+		{"pbkdf2 dklen too large", keystoreVectorPbkdf2, func(params *KeystoreKdf) { params.DkLen = 1 << 20 }},
+	}
+	for _, tt := range tests {
+		keystore := new(Keystore)
+		if err := json.Unmarshal([]byte(tt.vector), keystore); err != nil {
+			t.Fatalf("%s: unmarshal: %v", tt.name, err)
+		}
+		tt.modify(&keystore.Crypto.KdfParams)
+		data, err := json.Marshal(keystore)
+		if err != nil {
+			t.Fatalf("%s: marshal: %v", tt.name, err)
+		}
+		if _, err = DecryptKeystore(data, []byte("testpassword")); !errors.Is(err, ErrKeystoreUnsupported) {
+			t.Fatalf("%s: got %v, want ErrKeystoreUnsupported", tt.name, err)
+		}
+	}
+}
+
+func TestDecryptKeystore_WaitsForKdfSlot(t *testing.T) {
+	for i := 0; i < maxKdfRuns; i++ {
+		kdfSlots <- struct{}{}
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := DecryptKeystore([]byte(keystoreVectorPbkdf2), []byte("testpassword"))
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("key derived while all slots are busy: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	for i := 0; i < maxKdfRuns; i++ {
+		<-kdfSlots
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("DecryptKeystore: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("key not derived after the slots are released")
+	}
+}
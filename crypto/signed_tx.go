@@ -0,0 +1,72 @@
+package crypto
+
+import (
+	"math/big"
+
+	"github.com/ITProLabDev/ethbacknode/common/rlp"
+	"github.com/ITProLabDev/ethbacknode/crypto/secp256k1"
+)
+
+// DecodeSignedTx decodes the RLP encoded signed transaction, legacy EIP-155 or
+// EIP-1559 dynamic fee, and recovers the address of the signing key.
+// Returns ErrSignedTxInvalid for a malformed transaction or signature.
+func DecodeSignedTx(signedTx []byte) (tx *Tx, from []byte, err error) {
+	if len(signedTx) == 0 {
+		return nil, nil, ErrSignedTxInvalid
+	}
+	var hash []byte
+	var recoveryId *big.Int
+	var r, s *big.Int
+	if signedTx[0] == DynamicFeeTxType {
+		dynamicTx := new(EthDynamicFeeTxSigner)
+		if rlp.DecodeBytes(signedTx[1:], dynamicTx) != nil || dynamicTx.To == nil {
+			return nil, nil, ErrSignedTxInvalid
+		}
+		tx = &Tx{
+			ChainId:              dynamicTx.ChainId,
+			Nonce:                dynamicTx.Nonce,
+			To:                   *dynamicTx.To,
+			Value:                dynamicTx.Value,
+			Data:                 dynamicTx.Data,
+			Gas:                  dynamicTx.Gas,
+			MaxFeePerGas:         dynamicTx.MaxFeePerGas,
+			MaxPriorityFeePerGas: dynamicTx.MaxPriorityFeePerGas,
+		}
+		hash, recoveryId, r, s = dynamicTx.Hash(), dynamicTx.V, dynamicTx.R, dynamicTx.S
+	} else {
+		legacyTx := new(EthTxSigner)
+		if rlp.DecodeBytes(signedTx, legacyTx) != nil || legacyTx.To == nil || legacyTx.V == nil {
+			return nil, nil, ErrSignedTxInvalid
+		}
+		// EIP-155: v = recoveryId + 35 + 2 * chainId
+		v := new(big.Int).Sub(legacyTx.V, big.NewInt(35))
+		if v.Sign() < 0 {
+			return nil, nil, ErrSignedTxInvalid
+		}
+		recoveryId = new(big.Int).And(v, big.NewInt(1))
+		legacyTx.SetChainId(new(big.Int).Rsh(v, 1))
+		tx = &Tx{
+			ChainId:  legacyTx.chainId,
+			Nonce:    legacyTx.Nonce,
+			To:       *legacyTx.To,
+			Value:    legacyTx.Value,
+			Data:     legacyTx.Data,
+			Gas:      legacyTx.Gas,
+			GasPrice: legacyTx.GasPrice,
+		}
+		hash, r, s = legacyTx.Hash(), legacyTx.R, legacyTx.S
+	}
+	if recoveryId == nil || recoveryId.Cmp(big.NewInt(1)) > 0 || r == nil || s == nil ||
+		r.BitLen() > 256 || s.BitLen() > 256 {
+		return nil, nil, ErrSignedTxInvalid
+	}
+	sig := make([]byte, 65)
+	copy(sig, padBytes(r.Bytes(), 32))
+	copy(sig[32:], padBytes(s.Bytes(), 32))
+	sig[64] = byte(recoveryId.Uint64())
+	publicKey, err := secp256k1.RecoverEthereum(hash, sig)
+	if err != nil || len(publicKey) != 65 {
+		return nil, nil, ErrSignedTxInvalid
+	}
+	return tx, Keccak256(publicKey[1:])[12:], nil
+}
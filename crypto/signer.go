@@ -0,0 +1,59 @@
+package crypto
+
+import (
+	"bytes"
+	"math/big"
+)
+
+// Signer signs Ethereum transactions for a single address. The key may be held
+// in the process, sealed in an encrypted keystore or by a remote signer.
+type Signer interface {
+	// Address returns the 20-byte address of the signing key.
+	Address() []byte
+	// SignTx signs the transaction and returns the RLP encoded signed transaction.
+	SignTx(tx *Tx) (signedTx []byte, err error)
+}
+
+// Tx is an unsigned Ethereum transaction. A transaction with MaxFeePerGas set is
+// an EIP-1559 dynamic fee transaction, otherwise an EIP-155 legacy one.
+type Tx struct {
+	ChainId              *big.Int
+	Nonce                uint64
+	To                   []byte
+	Value                *big.Int
+	Data                 []byte
+	Gas                  uint64
+	GasPrice             *big.Int
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+}
+
+// IsDynamic reports whether the transaction is an EIP-1559 dynamic fee transaction.
+func (tx *Tx) IsDynamic() bool {
+	return tx.MaxFeePerGas != nil
+}
+
+// Equal reports whether the transactions have the same fields. A nil amount equals zero.
+func (tx *Tx) Equal(other *Tx) bool {
+	return tx.Nonce == other.Nonce &&
+		tx.Gas == other.Gas &&
+		bytes.Equal(tx.To, other.To) &&
+		bytes.Equal(tx.Data, other.Data) &&
+		tx.IsDynamic() == other.IsDynamic() &&
+		bigEqual(tx.ChainId, other.ChainId) &&
+		bigEqual(tx.Value, other.Value) &&
+		bigEqual(tx.GasPrice, other.GasPrice) &&
+		bigEqual(tx.MaxFeePerGas, other.MaxFeePerGas) &&
+		bigEqual(tx.MaxPriorityFeePerGas, other.MaxPriorityFeePerGas)
+}
+
+// bigEqual compares the amounts, nil is zero.
+func bigEqual(a, b *big.Int) bool {
+	if a == nil {
+		a = new(big.Int)
+	}
+	if b == nil {
+		b = new(big.Int)
+	}
+	return a.Cmp(b) == 0
+}
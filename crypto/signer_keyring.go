@@ -0,0 +1,54 @@
+package crypto
+
+import (
+	"github.com/ITProLabDev/ethbacknode/crypto/keyring"
+)
+
+// KeyringSigner keeps the private key sealed with the keyring master key and
+// opens it only for the time of signing.
+type KeyringSigner struct {
+	keyring   *keyring.Keyring
+	sealedKey []byte
+	address   []byte
+}
+
+// NewKeyringSigner seals the private key with the keyring. The caller should wipe
+// its copy of the private key.
+func NewKeyringSigner(k *keyring.Keyring, privateKey []byte) (signer *KeyringSigner, err error) {
+	local, err := NewLocalSigner(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	sealedKey, err := k.Seal(privateKey, local.address)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyringSigner{
+		keyring:   k,
+		sealedKey: sealedKey,
+		address:   local.address,
+	}, nil
+}
+
+// Address returns the address of the sealed private key.
+func (s *KeyringSigner) Address() []byte {
+	return s.address
+}
+
+// SignTx opens the private key, signs the transaction and wipes the opened key.
+func (s *KeyringSigner) SignTx(tx *Tx) (signedTx []byte, err error) {
+	privateKey, err := s.keyring.Open(s.sealedKey, s.address)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for i := range privateKey {
+			privateKey[i] = 0
+		}
+	}()
+	local, err := NewLocalSigner(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return local.SignTx(tx)
+}
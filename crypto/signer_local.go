@@ -0,0 +1,68 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+)
+
+// txSigner is implemented by both legacy and EIP-1559 transaction signers.
+type txSigner interface {
+	Sign(privateKey *ecdsa.PrivateKey) (sig []byte)
+	EncodeRPL() (data []byte, err error)
+}
+
+// LocalSigner signs with a private key held in the process memory.
+type LocalSigner struct {
+	privateKey *ecdsa.PrivateKey
+	address    []byte
+}
+
+// NewLocalSigner creates a signer of the 32-byte secp256k1 private key.
+func NewLocalSigner(privateKey []byte) (signer *LocalSigner, err error) {
+	if len(privateKey) != 32 || new(big.Int).SetBytes(privateKey).Sign() == 0 {
+		return nil, ErrInvalidPrivateKey
+	}
+	priv, pub := ECDSAKeysFromPrivateKeyBytes(privateKey)
+	return &LocalSigner{
+		privateKey: priv,
+		address:    PubKeyToAddressBytes(*pub),
+	}, nil
+}
+
+// Address returns the address of the private key.
+func (s *LocalSigner) Address() []byte {
+	return s.address
+}
+
+// SignTx signs the transaction with the private key.
+func (s *LocalSigner) SignTx(tx *Tx) (signedTx []byte, err error) {
+	to := tx.To
+	var signer txSigner
+	if tx.IsDynamic() {
+		signer = &EthDynamicFeeTxSigner{
+			ChainId:              tx.ChainId,
+			Nonce:                tx.Nonce,
+			MaxPriorityFeePerGas: tx.MaxPriorityFeePerGas,
+			MaxFeePerGas:         tx.MaxFeePerGas,
+			Gas:                  tx.Gas,
+			To:                   &to,
+			Value:                tx.Value,
+			Data:                 tx.Data,
+		}
+	} else {
+		legacyTx := &EthTxSigner{
+			Nonce:    tx.Nonce,
+			GasPrice: tx.GasPrice,
+			Gas:      tx.Gas,
+			To:       &to,
+			Value:    tx.Value,
+			Data:     tx.Data,
+		}
+		legacyTx.SetChainId(tx.ChainId)
+		signer = legacyTx
+	}
+	if len(signer.Sign(s.privateKey)) == 0 {
+		return nil, ErrSignFailed
+	}
+	return signer.EncodeRPL()
+}
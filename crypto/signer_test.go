@@ -0,0 +1,135 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ITProLabDev/ethbacknode/crypto/keyring"
+)
+
+// EIP-155 example transaction signed with the key 0x4646...46.
+const (
+	testPrivateKey = "4646464646464646464646464646464646464646464646464646464646464646"
+	testAddress    = "9d8a62f656a8d1615c1294fd71e9cfb3e4855a4f"
+	testSignedTx   = "f86c098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a76400008025a028ef61340bd939bc2195fe537567866003e1a15d3c71ff63e1590620aa636276a067cbe9d8997f761aecb703304b3800ccf555c9f3dc64214b297fb1966a3b6d83"
+)
+
+// mockSigner signs with a local key and records the signed transactions.
+type mockSigner struct {
+	local  *LocalSigner
+	signed []*Tx
+}
+
+func (m *mockSigner) Address() []byte {
+	return m.local.Address()
+}
+
+func (m *mockSigner) SignTx(tx *Tx) ([]byte, error) {
+	m.signed = append(m.signed, tx)
+	return m.local.SignTx(tx)
+}
+
+func testTx() *Tx {
+	value, _ := new(big.Int).SetString("1000000000000000000", 10)
+	return &Tx{
+		ChainId:  big.NewInt(1),
+		Nonce:    9,
+		To:       bytes.Repeat([]byte{0x35}, 20),
+		Value:    value,
+		Gas:      21000,
+		GasPrice: big.NewInt(20000000000),
+	}
+}
+
+func testLocalSigner(t *testing.T) *LocalSigner {
+	t.Helper()
+	privateKey, _ := hex.DecodeString(testPrivateKey)
+	signer, err := NewLocalSigner(privateKey)
+	if err != nil {
+		t.Fatalf("NewLocalSigner: %v", err)
+	}
+	return signer
+}
+
+func TestLocalSigner_SignsEIP155Example(t *testing.T) {
+	signer := testLocalSigner(t)
+	if got := hex.EncodeToString(signer.Address()); got != testAddress {
+		t.Fatalf("address: got %s, want %s", got, testAddress)
+	}
+	signedTx, err := signer.SignTx(testTx())
+	if err != nil {
+		t.Fatalf("SignTx: %v", err)
+	}
+	if got := hex.EncodeToString(signedTx); got != testSignedTx {
+		t.Fatalf("signed tx:\n got %s\nwant %s", got, testSignedTx)
+	}
+	if _, err = NewLocalSigner(make([]byte, 32)); !errors.Is(err, ErrInvalidPrivateKey) {
+		t.Fatalf("zero key: got %v, want ErrInvalidPrivateKey", err)
+	}
+}
+
+func TestSigner_Implementations(t *testing.T) {
+	var signer Signer = &mockSigner{local: testLocalSigner(t)}
+	mock := signer.(*mockSigner)
+	if _, err := signer.SignTx(testTx()); err != nil || len(mock.signed) != 1 {
+		t.Fatalf("mock SignTx: %v, %d signed", err, len(mock.signed))
+	}
+
+	k, err := keyring.New()
+	if err != nil {
+		t.Fatalf("keyring.New: %v", err)
+	}
+	privateKey, _ := hex.DecodeString(testPrivateKey)
+	signer, err = NewKeyringSigner(k, privateKey)
+	if err != nil {
+		t.Fatalf("NewKeyringSigner: %v", err)
+	}
+	signedTx, err := signer.SignTx(testTx())
+	if err != nil {
+		t.Fatalf("keyring SignTx: %v", err)
+	}
+	if got := hex.EncodeToString(signedTx); got != testSignedTx {
+		t.Fatalf("keyring signed tx: got %s", got)
+	}
+}
+
+func TestDecodeSignedTx(t *testing.T) {
+	local := testLocalSigner(t)
+	legacy, _ := hex.DecodeString(testSignedTx)
+	dynamic := testTx()
+	dynamic.GasPrice = nil
+	dynamic.MaxFeePerGas = big.NewInt(30000000000)
+	dynamic.MaxPriorityFeePerGas = big.NewInt(1000000000)
+	dynamic.Data = []byte{0xa9, 0x05, 0x9c, 0xbb}
+	dynamicSigned, err := local.SignTx(dynamic)
+	if err != nil {
+		t.Fatalf("SignTx: %v", err)
+	}
+	for _, tt := range []struct {
+		name     string
+		signedTx []byte
+		want     *Tx
+	}{
+		{"legacy", legacy, testTx()},
+		{"dynamic fee", dynamicSigned, dynamic},
+	} {
+		tx, from, err := DecodeSignedTx(tt.signedTx)
+		if err != nil {
+			t.Fatalf("%s: DecodeSignedTx: %v", tt.name, err)
+		}
+		if got := hex.EncodeToString(from); got != testAddress {
+			t.Fatalf("%s: sender: got %s, want %s", tt.name, got, testAddress)
+		}
+		if !tx.Equal(tt.want) {
+			t.Fatalf("%s: decoded tx:\n got %+v\nwant %+v", tt.name, tx, tt.want)
+		}
+	}
+	for _, signedTx := range [][]byte{nil, {0x02}, legacy[:len(legacy)-1]} {
+		if _, _, err = DecodeSignedTx(signedTx); !errors.Is(err, ErrSignedTxInvalid) {
+			t.Fatalf("DecodeSignedTx %x: got %v, want ErrSignedTxInvalid", signedTx, err)
+		}
+	}
+}
@@ -21,6 +21,8 @@ func (r *BackRpc) rpcProcessAddressSubscribe(ctx RequestContext, request RpcRequ
 		UserId     int64                   `json:"userId"`
 		InvoiceId  int64                   `json:"invoiceId"`
 		WatchOnly  bool                    `json:"watchOnly"`
+		// ExternalSigner marks an address with the key held by the remote signer
+		ExternalSigner bool `json:"externalSigner,omitempty"`
 	}
 	type addressSubscribeResponse struct {
 		Success bool   `json:"success"`
@@ -80,7 +82,9 @@ func (r *BackRpc) rpcProcessAddressSubscribe(ctx RequestContext, request RpcRequ
 		log.Warning("TODO: Authorization needed")
 	}
 	newAddress = params.Address
-	if params.PrivateKey == "" {
+	if params.PrivateKey != "" {
+		params.ExternalSigner = false
+	} else if !params.ExternalSigner {
 		params.WatchOnly = true
 	}
 	if params.PrivateKey != "" {
@@ -113,6 +117,7 @@ func (r *BackRpc) rpcProcessAddressSubscribe(ctx RequestContext, request RpcRequ
 		a.UserId = params.UserId
 		a.InvoiceId = params.InvoiceId
 		a.WatchOnly = params.WatchOnly
+		a.ExternalSigner = params.ExternalSigner
 		a.Subscribed = true
 		if len(params.Mnemonic) > 0 {
 			a.Bip39Support = true
@@ -183,13 +188,19 @@ func (r *BackRpc) rpcProcessAddressGetNew(ctx RequestContext, request RpcRequest
 		Address: newAddress.Address,
 	}
 	if params.FullInfo {
-		newAddressResponse.PrivateKey = hexnum.BytesToHex(newAddress.PrivateKey)
+		privateKey, err := r.addressPool.AddressPrivateKey(newAddress)
+		if err == nil {
+			newAddressResponse.PrivateKey = hexnum.BytesToHex(privateKey)
+		}
 		newAddressResponse.UserId = newAddress.UserId
 		newAddressResponse.InvoiceId = newAddress.InvoiceId
 		newAddressResponse.WatchOnly = newAddress.WatchOnly
 		if newAddress.Bip39Support {
 			newAddressResponse.Bip39Support = true
-			newAddressResponse.Bip39Mnemonic = newAddress.Bip39Mnemonic
+			mnemonic, err := r.addressPool.AddressMnemonic(newAddress)
+			if err == nil {
+				newAddressResponse.Bip39Mnemonic = mnemonic
+			}
 		}
 	}
 	response.SetResult(newAddressResponse)
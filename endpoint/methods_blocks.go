@@ -0,0 +1,37 @@
+package endpoint
+
+import (
+	"errors"
+
+	"github.com/ITProLabDev/ethbacknode/clients/ethclient"
+	"github.com/ITProLabDev/ethbacknode/tools/log"
+	"github.com/ITProLabDev/ethbacknode/watchdog"
+)
+
+func (r *BackRpc) rpcProcessBlockReprocess(ctx RequestContext, request RpcRequest, response RpcResponse) {
+	type blockReprocessRequest struct {
+		BlockId string `json:"blockId"`
+	}
+	params := &blockReprocessRequest{}
+	err := request.ParseParams(params)
+	if err != nil {
+		response.SetError(ERROR_CODE_PARSE_ERROR, ERROR_MESSAGE_PARSE_ERROR)
+		return
+	}
+	if params.BlockId == "" {
+		response.SetError(ERROR_CODE_INVALID_REQUEST, "blockId required")
+		return
+	}
+	result, err := r.watchdog.PullBlock(params.BlockId)
+	if errors.Is(err, ethclient.ErrBlockNotFound) ||
+		errors.Is(err, watchdog.ErrBlockNotCanonical) ||
+		errors.Is(err, watchdog.ErrBlockNotProcessed) {
+		response.SetError(ERROR_CODE_INVALID_REQUEST, err.Error())
+		return
+	} else if err != nil {
+		log.Error("Can not reprocess block:", params.BlockId, err)
+		response.SetError(ERROR_CODE_SERVER_ERROR, ERROR_MESSAGE_SERVER_ERROR)
+		return
+	}
+	response.SetResult(result)
+}
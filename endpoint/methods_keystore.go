@@ -0,0 +1,127 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/ITProLabDev/ethbacknode/address"
+	"github.com/ITProLabDev/ethbacknode/crypto"
+	"github.com/ITProLabDev/ethbacknode/subscriptions"
+	"github.com/ITProLabDev/ethbacknode/tools/log"
+)
+
+func (r *BackRpc) rpcProcessAddressImportKeystore(ctx RequestContext, request RpcRequest, response RpcResponse) {
+	type addressImportKeystoreRequest struct {
+		ServiceId  subscriptions.ServiceId `json:"serviceId"`
+		Keystore   json.RawMessage         `json:"keystore"`
+		Passphrase string                  `json:"passphrase"`
+		UserId     int64                   `json:"userId"`
+		InvoiceId  int64                   `json:"invoiceId"`
+	}
+	type addressImportKeystoreResponse struct {
+		Success bool   `json:"success"`
+		Address string `json:"address,omitempty"`
+	}
+	params := new(addressImportKeystoreRequest)
+	err := request.ParseParams(params)
+	if err != nil {
+		response.SetError(ERROR_CODE_PARSE_ERROR, ERROR_MESSAGE_PARSE_ERROR)
+		return
+	}
+	if params.ServiceId == 0 {
+		response.SetError(ERROR_CODE_INVALID_REQUEST, "Invalid service id")
+		return
+	}
+	if len(params.Keystore) == 0 || params.Passphrase == "" {
+		response.SetError(ERROR_CODE_INVALID_REQUEST, "keystore and passphrase required")
+		return
+	}
+	// the keystore file may be passed as an object or as a JSON encoded string
+	var keystoreString string
+	if json.Unmarshal(params.Keystore, &keystoreString) == nil {
+		params.Keystore = json.RawMessage(keystoreString)
+	}
+	subscription, err := r.subscriptions.SubscriptionGet(params.ServiceId)
+	if err != nil {
+		response.SetError(ERROR_CODE_SERVER_ERROR, err.Error())
+		return
+	}
+	if subscription.Internal {
+		response.SetError(ERROR_CODE_SERVER_ERROR, "unknown serviceId")
+		return
+	}
+	addressRecord, err := r.addressPool.ImportKeystore(params.Keystore, []byte(params.Passphrase), func(a *address.Address) {
+		a.ServiceId = int(params.ServiceId)
+		a.UserId = params.UserId
+		a.InvoiceId = params.InvoiceId
+		a.Subscribed = true
+	})
+	if err != nil {
+		r.keystoreErrorResponse(err, response)
+		return
+	}
+	response.SetResult(&addressImportKeystoreResponse{
+		Success: true,
+		Address: addressRecord.Address,
+	})
+}
+
+func (r *BackRpc) rpcProcessAddressExportKeystore(ctx RequestContext, request RpcRequest, response RpcResponse) {
+	type addressExportKeystoreRequest struct {
+		ServiceId  int    `json:"serviceId"`
+		Address    string `json:"address"`
+		Passphrase string `json:"passphrase"`
+	}
+	type addressExportKeystoreResponse struct {
+		Success  bool            `json:"success"`
+		Address  string          `json:"address"`
+		Keystore json.RawMessage `json:"keystore"`
+	}
+	params := new(addressExportKeystoreRequest)
+	err := request.ParseParams(params)
+	if err != nil {
+		response.SetError(ERROR_CODE_PARSE_ERROR, ERROR_MESSAGE_PARSE_ERROR)
+		return
+	}
+	if params.Passphrase == "" {
+		response.SetError(ERROR_CODE_INVALID_REQUEST, "passphrase required")
+		return
+	}
+	params.Address, err = r.addressNormalise(params.Address)
+	if err != nil {
+		response.SetError(ERROR_CODE_INVALID_REQUEST, err.Error())
+		return
+	}
+	addressInfo, err := r.addressPool.GetAddress(params.Address)
+	if err != nil || addressInfo.ServiceId != params.ServiceId {
+		response.SetError(ERROR_CODE_INVALID_REQUEST, "address unknown or not owned by service")
+		return
+	}
+	keystore, err := r.addressPool.ExportKeystore(addressInfo.Address, []byte(params.Passphrase))
+	if err != nil {
+		r.keystoreErrorResponse(err, response)
+		return
+	}
+	response.SetResult(&addressExportKeystoreResponse{
+		Success:  true,
+		Address:  addressInfo.Address,
+		Keystore: keystore,
+	})
+}
+
+// keystoreErrorResponse reports the keystore error, errors caused by the request
+// parameters are reported as invalid request.
+func (r *BackRpc) keystoreErrorResponse(err error, response RpcResponse) {
+	if errors.Is(err, crypto.ErrKeystoreInvalid) ||
+		errors.Is(err, crypto.ErrKeystoreUnsupported) ||
+		errors.Is(err, crypto.ErrKeystoreWrongPassphrase) ||
+		errors.Is(err, crypto.ErrKeystoreAddressMismatch) ||
+		errors.Is(err, crypto.ErrInvalidPrivateKey) ||
+		errors.Is(err, address.ErrAddressExists) ||
+		errors.Is(err, address.ErrPrivateKeyEmpty) {
+		response.SetError(ERROR_CODE_INVALID_REQUEST, err.Error())
+		return
+	}
+	log.Error("Can not process keystore request:", err)
+	response.SetError(ERROR_CODE_SERVER_ERROR, ERROR_MESSAGE_SERVER_ERROR)
+}
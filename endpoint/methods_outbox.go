@@ -0,0 +1,74 @@
+package endpoint
+
+import (
+	"errors"
+
+	"github.com/ITProLabDev/ethbacknode/subscriptions"
+	"github.com/ITProLabDev/ethbacknode/tools/log"
+)
+
+func (r *BackRpc) rpcProcessOutboxList(ctx RequestContext, request RpcRequest, response RpcResponse) {
+	params := &subscriptions.OutboxFilter{}
+	err := request.ParseParams(params)
+	if err != nil {
+		response.SetError(ERROR_CODE_PARSE_ERROR, ERROR_MESSAGE_PARSE_ERROR)
+		return
+	}
+	records, err := r.subscriptions.OutboxList(params)
+	if err != nil {
+		r.outboxErrorResponse(err, response)
+		return
+	}
+	if records == nil {
+		records = make([]*subscriptions.OutboxRecord, 0)
+	}
+	response.SetResult(records)
+}
+
+func (r *BackRpc) rpcProcessOutboxRetry(ctx RequestContext, request RpcRequest, response RpcResponse) {
+	params := &subscriptions.OutboxFilter{}
+	err := request.ParseParams(params)
+	if err != nil {
+		response.SetError(ERROR_CODE_PARSE_ERROR, ERROR_MESSAGE_PARSE_ERROR)
+		return
+	}
+	count, err := r.subscriptions.OutboxRetry(params)
+	if err != nil {
+		r.outboxErrorResponse(err, response)
+		return
+	}
+	response.SetResult(&struct {
+		Count int `json:"count"`
+	}{
+		Count: count,
+	})
+}
+
+func (r *BackRpc) rpcProcessOutboxPurge(ctx RequestContext, request RpcRequest, response RpcResponse) {
+	params := &subscriptions.OutboxFilter{}
+	err := request.ParseParams(params)
+	if err != nil {
+		response.SetError(ERROR_CODE_PARSE_ERROR, ERROR_MESSAGE_PARSE_ERROR)
+		return
+	}
+	count, err := r.subscriptions.OutboxPurge(params)
+	if err != nil {
+		r.outboxErrorResponse(err, response)
+		return
+	}
+	response.SetResult(&struct {
+		Count int `json:"count"`
+	}{
+		Count: count,
+	})
+}
+
+// outboxErrorResponse reports the outbox error, a disabled outbox is reported as invalid request.
+func (r *BackRpc) outboxErrorResponse(err error, response RpcResponse) {
+	if errors.Is(err, subscriptions.ErrOutboxDisabled) {
+		response.SetError(ERROR_CODE_INVALID_REQUEST, err.Error())
+		return
+	}
+	log.Error("Can not process outbox request:", err)
+	response.SetError(ERROR_CODE_SERVER_ERROR, ERROR_MESSAGE_SERVER_ERROR)
+}
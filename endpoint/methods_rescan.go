@@ -0,0 +1,127 @@
+package endpoint
+
+import (
+	"errors"
+
+	"github.com/ITProLabDev/ethbacknode/tools/log"
+	"github.com/ITProLabDev/ethbacknode/watchdog"
+)
+
+func (r *BackRpc) rpcProcessRescanBlocks(ctx RequestContext, request RpcRequest, response RpcResponse) {
+	type rescanBlocksRequest struct {
+		ServiceId int   `json:"serviceId"`
+		FromBlock int64 `json:"fromBlock"`
+		ToBlock   int64 `json:"toBlock,omitempty"`
+	}
+	params := &rescanBlocksRequest{}
+	err := request.ParseParams(params)
+	if err != nil {
+		response.SetError(ERROR_CODE_PARSE_ERROR, ERROR_MESSAGE_PARSE_ERROR)
+		return
+	}
+	job, err := r.watchdog.RescanBlocks(params.ServiceId, params.FromBlock, params.ToBlock)
+	r.rescanJobResponse(job, err, response)
+}
+
+func (r *BackRpc) rpcProcessRescanTransaction(ctx RequestContext, request RpcRequest, response RpcResponse) {
+	type rescanTransactionRequest struct {
+		ServiceId int    `json:"serviceId"`
+		TxId      string `json:"txId"`
+	}
+	params := &rescanTransactionRequest{}
+	err := request.ParseParams(params)
+	if err != nil {
+		response.SetError(ERROR_CODE_PARSE_ERROR, ERROR_MESSAGE_PARSE_ERROR)
+		return
+	}
+	if params.TxId == "" {
+		response.SetError(ERROR_CODE_INVALID_REQUEST, "txId required")
+		return
+	}
+	job, err := r.watchdog.RescanTransaction(params.ServiceId, params.TxId)
+	r.rescanJobResponse(job, err, response)
+}
+
+func (r *BackRpc) rpcProcessRescanAddress(ctx RequestContext, request RpcRequest, response RpcResponse) {
+	type rescanAddressRequest struct {
+		ServiceId int    `json:"serviceId"`
+		Address   string `json:"address"`
+		FromBlock int64  `json:"fromBlock"`
+	}
+	params := &rescanAddressRequest{}
+	err := request.ParseParams(params)
+	if err != nil {
+		response.SetError(ERROR_CODE_PARSE_ERROR, ERROR_MESSAGE_PARSE_ERROR)
+		return
+	}
+	address, err := r.addressNormalise(params.Address)
+	if err != nil {
+		response.SetError(ERROR_CODE_INVALID_REQUEST, err.Error())
+		return
+	}
+	addressInfo, err := r.addressPool.GetAddress(address)
+	if err != nil || addressInfo.ServiceId != params.ServiceId {
+		response.SetError(ERROR_CODE_INVALID_REQUEST, "address unknown or not owned by service")
+		return
+	}
+	job, err := r.watchdog.RescanAddress(params.ServiceId, address, params.FromBlock)
+	r.rescanJobResponse(job, err, response)
+}
+
+func (r *BackRpc) rpcProcessRescanStatus(ctx RequestContext, request RpcRequest, response RpcResponse) {
+	type rescanStatusRequest struct {
+		ServiceId int   `json:"serviceId"`
+		JobId     int64 `json:"jobId,omitempty"`
+	}
+	params := &rescanStatusRequest{}
+	err := request.ParseParams(params)
+	if err != nil {
+		response.SetError(ERROR_CODE_PARSE_ERROR, ERROR_MESSAGE_PARSE_ERROR)
+		return
+	}
+	if params.JobId == 0 {
+		response.SetResult(r.watchdog.RescanJobs(params.ServiceId))
+		return
+	}
+	job, err := r.watchdog.RescanJob(params.ServiceId, params.JobId)
+	r.rescanJobResponse(job, err, response)
+}
+
+func (r *BackRpc) rpcProcessRescanCancel(ctx RequestContext, request RpcRequest, response RpcResponse) {
+	type rescanCancelRequest struct {
+		ServiceId int   `json:"serviceId"`
+		JobId     int64 `json:"jobId"`
+	}
+	params := &rescanCancelRequest{}
+	err := request.ParseParams(params)
+	if err != nil {
+		response.SetError(ERROR_CODE_PARSE_ERROR, ERROR_MESSAGE_PARSE_ERROR)
+		return
+	}
+	err = r.watchdog.RescanCancel(params.ServiceId, params.JobId)
+	if err != nil {
+		response.SetError(ERROR_CODE_INVALID_REQUEST, err.Error())
+		return
+	}
+	job, err := r.watchdog.RescanJob(params.ServiceId, params.JobId)
+	r.rescanJobResponse(job, err, response)
+}
+
+// rescanJobResponse sets the job as result, request errors are reported as invalid request.
+func (r *BackRpc) rescanJobResponse(job *watchdog.RescanJob, err error, response RpcResponse) {
+	if errors.Is(err, watchdog.ErrInvalidBlockRange) ||
+		errors.Is(err, watchdog.ErrAddressNotKnown) ||
+		errors.Is(err, watchdog.ErrTransactionNotMined) ||
+		errors.Is(err, watchdog.ErrRescanJobNotFound) ||
+		errors.Is(err, watchdog.ErrRescanQueueFull) {
+		response.SetError(ERROR_CODE_INVALID_REQUEST, err.Error())
+		return
+	} else if err != nil {
+		if r.debugMode {
+			log.Error("Can not process rescan request:", err)
+		}
+		response.SetError(ERROR_CODE_SERVER_ERROR, "unknown transaction or server error")
+		return
+	}
+	response.SetResult(job)
+}
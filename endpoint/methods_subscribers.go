@@ -1,6 +1,9 @@
 package endpoint
 
 import (
+	"encoding/json"
+	"math/big"
+
 	"github.com/ITProLabDev/ethbacknode/subscriptions"
 )
 
@@ -10,16 +13,21 @@ func (r *BackRpc) rpcProcessServiceRegister(ctx RequestContext, request RpcReque
 
 func (r *BackRpc) rpcProcessServiceConfig(ctx RequestContext, request RpcRequest, response RpcResponse) {
 	type serviceConfigRequest struct {
-		ServiceId        subscriptions.ServiceId `json:"serviceId"`
-		EndpointUrl      string                  `json:"eventUrl"`
-		ReportNewBlock   bool                    `json:"reportNewBlock"`
-		ReportIncomingTx bool                    `json:"reportIncomingTx"`
-		ReportOutgoingTx bool                    `json:"reportOutgoingTx"`
-		ReportMainCoin   bool                    `json:"reportMainCoin"`
-		ReportTokens     []string                `json:"reportTokens"`
-		GatherToMaster   bool                    `json:"gatherToMaster"`
-		MasterList       []string                `json:"masterList"`
-		Signature        string                  `json:"signature,omitempty"`
+		ServiceId        subscriptions.ServiceId    `json:"serviceId"`
+		EndpointUrl      string                     `json:"eventUrl"`
+		ReportNewBlock   bool                       `json:"reportNewBlock"`
+		ReportIncomingTx bool                       `json:"reportIncomingTx"`
+		ReportOutgoingTx bool                       `json:"reportOutgoingTx"`
+		ReportMainCoin   bool                       `json:"reportMainCoin"`
+		ReportTokens     []string                   `json:"reportTokens"`
+		MinAmounts       map[string]json.Number     `json:"minAmounts,omitempty"`
+		BalanceChange    bool                       `json:"balanceChange"`
+		Milestones       []int                      `json:"confirmationMilestones,omitempty"`
+		GatherToMaster   bool                       `json:"gatherToMaster"`
+		MasterList       []string                   `json:"masterList"`
+		GasStation       string                     `json:"gasStation,omitempty"`
+		SweepPolicy      *subscriptions.SweepPolicy `json:"sweepPolicy,omitempty"`
+		Signature        string                     `json:"signature,omitempty"`
 	}
 	params := &serviceConfigRequest{
 		ReportMainCoin: true,
@@ -39,6 +47,39 @@ func (r *BackRpc) rpcProcessServiceConfig(ctx RequestContext, request RpcRequest
 		return
 	}
 	tokenListInternal := r.chainClient.TokensList()
+	minAmounts := make(map[string]*big.Int)
+	for symbol, amount := range params.MinAmounts {
+		if symbol != r.chainClient.GetChainSymbol() && !r._isTokenKnown(symbol) {
+			response.SetError(ERROR_CODE_INVALID_REQUEST, "unknown asset symbol: "+symbol)
+			return
+		}
+		minAmount, ok := new(big.Int).SetString(amount.String(), 10)
+		if !ok || minAmount.Sign() < 0 {
+			response.SetError(ERROR_CODE_INVALID_REQUEST, "invalid min amount: "+symbol)
+			return
+		}
+		minAmounts[symbol] = minAmount
+	}
+	if params.GasStation != "" {
+		params.GasStation, err = r.addressNormalise(params.GasStation)
+		if err != nil || !r.addressPool.IsAddressKnown(params.GasStation) {
+			response.SetError(ERROR_CODE_INVALID_REQUEST, "unknown gas station address")
+			return
+		}
+	}
+	if params.SweepPolicy != nil {
+		err = params.SweepPolicy.Validate()
+		if err != nil {
+			response.SetError(ERROR_CODE_INVALID_REQUEST, err.Error())
+			return
+		}
+	}
+	for _, milestone := range params.Milestones {
+		if milestone < 0 {
+			response.SetError(ERROR_CODE_INVALID_REQUEST, "invalid confirmation milestone")
+			return
+		}
+	}
 	//TODO validate url
 	//TODO validate master address
 	//TODO validate tokens
@@ -48,7 +89,7 @@ func (r *BackRpc) rpcProcessServiceConfig(ctx RequestContext, request RpcRequest
 		subscription.ReportNewBlock = params.ReportNewBlock
 		subscription.ReportIncomingTx = params.ReportIncomingTx
 		subscription.ReportOutgoingTx = params.ReportOutgoingTx
-		subscription.ReportMainCoin = params.ReportMainCoin
+		subscription.ReportMainCoin = &params.ReportMainCoin
 		subscription.ReportTokens = make(map[string]bool)
 		for _, token := range tokenListInternal {
 			subscription.ReportTokens[token.Symbol] = false
@@ -56,8 +97,13 @@ func (r *BackRpc) rpcProcessServiceConfig(ctx RequestContext, request RpcRequest
 		for _, token := range params.ReportTokens {
 			subscription.ReportTokens[token] = true
 		}
+		subscription.MinAmounts = minAmounts
+		subscription.ReportBalanceChange = params.BalanceChange
+		subscription.ConfirmationMilestones = params.Milestones
 		subscription.GatherToMaster = params.GatherToMaster
 		subscription.MasterList = params.MasterList
+		subscription.GasStation = params.GasStation
+		subscription.SweepPolicy = params.SweepPolicy
 	})
 	if err != nil {
 		response.SetError(ERROR_CODE_SERVER_ERROR, err.Error())
@@ -0,0 +1,24 @@
+package endpoint
+
+import (
+	"github.com/ITProLabDev/ethbacknode/subscriptions"
+	"github.com/ITProLabDev/ethbacknode/tools/log"
+)
+
+func (r *BackRpc) rpcProcessSweepList(ctx RequestContext, request RpcRequest, response RpcResponse) {
+	serviceId, err := request.GetParamInt("serviceId")
+	if err != nil {
+		response.SetError(ERROR_CODE_INVALID_REQUEST, ERROR_MESSAGE_INVALID_REQUEST)
+		return
+	}
+	tasks, err := r.subscriptions.SweepTasks(subscriptions.ServiceId(serviceId))
+	if err != nil {
+		log.Error("Can not load sweep tasks:", err)
+		response.SetError(ERROR_CODE_SERVER_ERROR, ERROR_MESSAGE_SERVER_ERROR)
+		return
+	}
+	if tasks == nil {
+		tasks = make([]*subscriptions.SweepTask, 0)
+	}
+	response.SetResult(tasks)
+}
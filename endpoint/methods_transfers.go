@@ -2,10 +2,13 @@ package endpoint
 
 import (
 	"encoding/json"
+	"errors"
 	"math/big"
 	"strings"
 
+	"github.com/ITProLabDev/ethbacknode/clients/ethclient"
 	"github.com/ITProLabDev/ethbacknode/common/hexnum"
+	"github.com/ITProLabDev/ethbacknode/crypto"
 	"github.com/ITProLabDev/ethbacknode/tools/log"
 	"github.com/ITProLabDev/ethbacknode/types"
 )
@@ -96,12 +99,12 @@ func (r *BackRpc) rpcProcessTransferAssets(ctx RequestContext, request RpcReques
 		return
 	}
 	transferData := &struct {
-		PrivateKeyBytes []byte   `json:"privateKey,omitempty"`
-		From            string   `json:"from,omitempty"`
-		To              string   `json:"to"`
-		Amount          *big.Int `json:"amount"`
-		Symbol          string   `json:"symbol,omitempty"`
+		From   string   `json:"from,omitempty"`
+		To     string   `json:"to"`
+		Amount *big.Int `json:"amount"`
+		Symbol string   `json:"symbol,omitempty"`
 	}{}
+	var signer crypto.Signer
 	transferData.To = params.To
 	transferData.Amount = amountToTransfer
 	transferData.Symbol = params.Symbol
@@ -131,7 +134,11 @@ func (r *BackRpc) rpcProcessTransferAssets(ctx RequestContext, request RpcReques
 		} else if fromCalculated != params.From {
 			params.From = fromCalculated
 		}
-		transferData.PrivateKeyBytes = pkBytes
+		signer, err = crypto.NewLocalSigner(pkBytes)
+		if err != nil {
+			response.SetError(ERROR_CODE_INVALID_REQUEST, "invalid private key")
+			return
+		}
 	} else if params.From == "" {
 		response.SetError(ERROR_CODE_INVALID_REQUEST, "from address or private key required")
 		return
@@ -153,23 +160,28 @@ func (r *BackRpc) rpcProcessTransferAssets(ctx RequestContext, request RpcReques
 			response.SetError(ERROR_CODE_INVALID_REQUEST, "address unknown or not owned by service")
 			return
 		}
-		if (addressInfo.WatchOnly && !params.Force) || len(addressInfo.PrivateKey) == 0 {
+		if (addressInfo.WatchOnly && !params.Force) || !addressInfo.CanSign() {
 			response.SetError(ERROR_CODE_INVALID_REQUEST, "address is watch only")
 			return
 		}
-		transferData.PrivateKeyBytes = addressInfo.PrivateKey
+		signer, err = r.addressPool.AddressSigner(addressInfo)
+		if err != nil {
+			log.Error("Can not get signer of known address: ", err)
+			response.SetError(ERROR_CODE_SERVER_ERROR, ERROR_MESSAGE_SERVER_ERROR)
+			return
+		}
 	}
 	var txHash string
 	if transferData.Symbol == r.chainClient.GetChainSymbol() {
 		if r.debugMode {
 			log.Debug("Transferring native coin request")
 		}
-		txHash, err = r.chainClient.TransferByPrivateKey(transferData.PrivateKeyBytes, transferData.From, transferData.To, transferData.Amount)
+		txHash, err = r.chainClient.TransferBySigner(signer, transferData.To, transferData.Amount)
 	} else {
 		if r.debugMode {
 			log.Debug("Transferring token request")
 		}
-		txHash, err = r.chainClient.TransferTokenByPrivateKey(transferData.PrivateKeyBytes, transferData.From, transferData.To, transferData.Amount, transferData.Symbol)
+		txHash, err = r.chainClient.TransferTokenBySigner(signer, transferData.To, transferData.Amount, transferData.Symbol)
 	}
 	if err != nil {
 		//TODO check is it possible to get error from chain
@@ -194,12 +206,14 @@ func (r *BackRpc) rpcProcessTransferAssets(ctx RequestContext, request RpcReques
 		}
 	} else {
 		result.fill(transferInfo)
+		feeDecimals := decimals
 		if transferInfo.SmartContract {
 			result.FeeSymbol = r.chainClient.GetChainSymbol()
+			feeDecimals = r.chainClient.Decimals()
 		}
 		if params.AmountFormated {
 			result.Amount = amount(_formatBigIntToString(transferData.Amount, decimals))
-			result.Fee = amount(_formatBigIntToString(transferInfo.Fee, decimals))
+			result.Fee = amount(_formatBigIntToString(transferInfo.Fee, feeDecimals))
 		} else {
 			result.Amount = amount(transferData.Amount.String())
 			result.Fee = amount(transferInfo.Fee.String())
@@ -332,13 +346,121 @@ func (r *BackRpc) rpcProcessTransferGetEstimatedFee(ctx RequestContext, request
 	}
 	var feeResult json.Number
 	if params.AmountFormated {
-		feeResult = json.Number(_formatBigIntToString(fee, decimals))
+		// fee is always paid in native coin, even for token transfers
+		feeResult = json.Number(_formatBigIntToString(fee, r.chainClient.Decimals()))
 	} else {
 		feeResult = json.Number(fee.String())
 	}
 	response.SetResult(feeResult)
 }
 
+func (r *BackRpc) rpcProcessTransferSpeedUp(ctx RequestContext, request RpcRequest, response RpcResponse) {
+	r.transferReplace(request, response, false)
+}
+
+func (r *BackRpc) rpcProcessTransferCancel(ctx RequestContext, request RpcRequest, response RpcResponse) {
+	r.transferReplace(request, response, true)
+}
+
+// transferReplace replaces a pending outgoing transaction with a higher fee one,
+// the same transfer or a 0-value self-send if cancel is set.
+func (r *BackRpc) transferReplace(request RpcRequest, response RpcResponse, cancel bool) {
+	type transferReplaceRequest struct {
+		ServiceID  int    `json:"serviceId,omitempty"`
+		TxID       string `json:"txId"`
+		PrivateKey string `json:"privateKey,omitempty"`
+		Force      bool   `json:"force,omitempty"`
+	}
+	params := new(transferReplaceRequest)
+	err := request.ParseParams(params)
+	if err != nil {
+		response.SetError(ERROR_CODE_PARSE_ERROR, ERROR_MESSAGE_PARSE_ERROR)
+		return
+	}
+	if params.TxID == "" {
+		response.SetError(ERROR_CODE_INVALID_REQUEST, "txId required")
+		return
+	}
+	from, err := r.chainClient.PendingTransferFrom(params.TxID)
+	if errors.Is(err, ethclient.ErrTransactionNotPending) {
+		response.SetError(ERROR_CODE_INVALID_REQUEST, err.Error())
+		return
+	} else if err != nil {
+		log.Error("Can not get pending transfer:", err)
+		response.SetError(ERROR_CODE_SERVER_ERROR, ERROR_MESSAGE_SERVER_ERROR)
+		return
+	}
+	var signer crypto.Signer
+	if params.PrivateKey != "" {
+		privateKey, err := hexnum.ParseHexBytes(params.PrivateKey)
+		if err != nil {
+			response.SetError(ERROR_CODE_INVALID_REQUEST, "invalid private key")
+			return
+		}
+		signer, err = crypto.NewLocalSigner(privateKey)
+		if err != nil {
+			response.SetError(ERROR_CODE_INVALID_REQUEST, "invalid private key")
+			return
+		}
+	} else {
+		if !r.addressPool.IsAddressKnown(from) {
+			response.SetError(ERROR_CODE_INVALID_REQUEST, "private key required")
+			return
+		}
+		addressInfo, err := r.addressPool.GetAddress(from)
+		if err != nil {
+			log.Error("Can not get known address info: ", err)
+			response.SetError(ERROR_CODE_SERVER_ERROR, ERROR_MESSAGE_SERVER_ERROR)
+			return
+		}
+		if addressInfo.ServiceId != params.ServiceID {
+			response.SetError(ERROR_CODE_INVALID_REQUEST, "address unknown or not owned by service")
+			return
+		}
+		if (addressInfo.WatchOnly && !params.Force) || !addressInfo.CanSign() {
+			response.SetError(ERROR_CODE_INVALID_REQUEST, "address is watch only")
+			return
+		}
+		signer, err = r.addressPool.AddressSigner(addressInfo)
+		if err != nil {
+			log.Error("Can not get signer of known address: ", err)
+			response.SetError(ERROR_CODE_SERVER_ERROR, ERROR_MESSAGE_SERVER_ERROR)
+			return
+		}
+	}
+	var txHash string
+	if cancel {
+		txHash, err = r.chainClient.CancelBySigner(signer, params.TxID)
+	} else {
+		txHash, err = r.chainClient.SpeedUpBySigner(signer, params.TxID)
+	}
+	if errors.Is(err, ethclient.ErrTransactionNotPending) ||
+		errors.Is(err, ethclient.ErrPrivateKeyMismatch) ||
+		errors.Is(err, ethclient.ErrFeeCapExceeded) {
+		response.SetError(ERROR_CODE_INVALID_REQUEST, err.Error())
+		return
+	} else if err != nil {
+		if r.debugMode {
+			log.Error("Transfer replace error:", err)
+		}
+		response.SetError(ERROR_CODE_SERVER_ERROR, err.Error())
+		return
+	}
+	response.SetResult(&transferReplaceResult{
+		TxID:     txHash,
+		Replaced: params.TxID,
+		From:     from,
+		Cancel:   cancel,
+	})
+}
+
+type transferReplaceResult struct {
+	TxID     string `json:"tx_id"`
+	Replaced string `json:"replaced"`
+	From     string `json:"from"`
+	Cancel   bool   `json:"cancel,omitempty"`
+}
+
 func _parseAmountToBigInt(amount string, decimals int) (*big.Int, error) {
 	amount = strings.Trim(amount, "\"")
 	amount = strings.ReplaceAll(amount, ",", ".")
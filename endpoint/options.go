@@ -75,6 +75,6 @@ func WithRpcProcessor(method RpcMethod, processor RpcProcessor) BackRpcOption {
 // WithSecurityManager sets the security manager for request authentication.
 func WithSecurityManager(securityManager *security.Manager) BackRpcOption {
 	return func(r *BackRpc) {
-		r.security = securityManager
+		r.securityManager = securityManager
 	}
 }
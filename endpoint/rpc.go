@@ -1,5 +1,7 @@
 package endpoint
 
+import "encoding/json"
+
 // Router defines the interface for registering RPC method handlers.
 type Router interface {
 	Handle(method string, processor Processor)
@@ -29,6 +31,8 @@ type RpcRequest interface {
 	GetParamString(key string) (value string, err error)
 	GetParamInt(key string) (value int64, err error)
 	GetParamBool(key string) (value bool, err error)
+	GetParamsRaw() (params json.RawMessage)
+	GetAuth() (auth *RpcAuth)
 }
 
 // RpcResponse defines the interface for building JSON-RPC responses.
@@ -1,8 +1,8 @@
 package endpoint
 
 import (
-	"encoding/json"
 	"errors"
+	"strconv"
 
 	"github.com/ITProLabDev/ethbacknode/address"
 	"github.com/ITProLabDev/ethbacknode/security"
@@ -30,7 +30,6 @@ type BackRpc struct {
 	chainClient      types.ChainClient
 	knownTokens      map[string]*types.TokenInfo
 	subscriptions    *subscriptions.Manager
-	security         *security.Manager
 	watchdog         *watchdog.Service
 	txCache          types.TxCache
 	fallbackResponse HttpResponse
@@ -108,6 +107,23 @@ func (r *BackRpc) RegisterProcessor(method RpcMethod, processor RpcProcessor) {
 	r.rpcProcessors[method] = processor
 }
 
+// setSignatureError sets the response error matching the signature verification error.
+func (r *BackRpc) setSignatureError(response RpcResponse, err error) {
+	switch {
+	case errors.Is(err, security.ErrSignatureRequired):
+		response.SetErrorWithData(ERROR_CODE_UNAUTHORIZED, ERROR_MESSAGE_UNAUTHORIZED, "request signature required")
+	case errors.Is(err, security.ErrSignatureExpired):
+		response.SetError(ERROR_CODE_SIGNATURE_EXPIRED, ERROR_MESSAGE_SIGNATURE_EXPIRED)
+	case errors.Is(err, security.ErrNonceReused):
+		response.SetError(ERROR_CODE_NONCE_REUSED, ERROR_MESSAGE_NONCE_REUSED)
+	case errors.Is(err, security.ErrInvalidApiKey), errors.Is(err, security.ErrUnsupportedKeyFormat):
+		log.Error("Can not verify request signature:", err)
+		response.SetError(ERROR_CODE_SERVER_ERROR, ERROR_MESSAGE_SERVER_ERROR)
+	default:
+		response.SetErrorWithData(ERROR_CODE_SIGNATURE_INVALID, ERROR_MESSAGE_SIGNATURE_INVALID, err.Error())
+	}
+}
+
 // RegisterSecuredProcessor registers an RPC method processor with authentication.
 // Requires serviceId parameter and validates API token or signature.
 func (r *BackRpc) RegisterSecuredProcessor(method RpcMethod, processor RpcProcessor) {
@@ -145,14 +161,45 @@ func (r *BackRpc) RegisterSecuredProcessor(method RpcMethod, processor RpcProces
 					response.SetError(ERROR_CODE_SERVER_ERROR, ERROR_MESSAGE_SERVER_ERROR)
 					return
 				}
-				paramsPaw := make(map[string]json.RawMessage)
-				if sign, found := paramsPaw["signature"]; !found || sign == nil {
+				auth := request.GetAuth()
+				if auth == nil {
 					response.SetErrorWithData(ERROR_CODE_UNAUTHORIZED, ERROR_MESSAGE_UNAUTHORIZED, "request signature required")
 					return
 				}
-				//todo validate sign
+				err = r.securityManager.VerifyRequest(strconv.FormatInt(serviceId, 10), subscriber.ApiKey, string(request.GetMethod()), request.GetParamsRaw(), auth.TS, auth.Nonce, auth.Alg, auth.Sig)
+				if err != nil {
+					if r.debugMode {
+						log.Debug("request signature rejected for serviceId:", serviceId, ", err:", err)
+					}
+					r.setSignatureError(response, err)
+					return
+				}
+				ctx.Authorized(true)
 			}
 		}
 		processor(ctx, request, response)
 	}
 }
+
+// RegisterCredentialProcessor registers an RPC method processor which requires the
+// request to be authenticated. Unlike RegisterSecuredProcessor a service without an
+// API token or API key is rejected.
+func (r *BackRpc) RegisterCredentialProcessor(method RpcMethod, processor RpcProcessor) {
+	r.RegisterSecuredProcessor(method, func(ctx RequestContext, request RpcRequest, response RpcResponse) {
+		serviceId, err := request.GetParamInt("serviceId")
+		if err != nil {
+			response.SetError(ERROR_CODE_INVALID_REQUEST, ERROR_MESSAGE_INVALID_REQUEST)
+			return
+		}
+		subscriber, err := r.subscriptions.SubscriptionGet(subscriptions.ServiceId(serviceId))
+		if err != nil {
+			response.SetErrorWithData(ERROR_CODE_UNAUTHORIZED, ERROR_MESSAGE_UNAUTHORIZED, "serviceId required")
+			return
+		}
+		if subscriber.ApiToken == "" && subscriber.ApiKey == "" {
+			response.SetErrorWithData(ERROR_CODE_UNAUTHORIZED, ERROR_MESSAGE_UNAUTHORIZED, "api token or api key of the service required")
+			return
+		}
+		processor(ctx, request, response)
+	})
+}
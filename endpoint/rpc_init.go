@@ -25,6 +25,12 @@ func (r *BackRpc) InitProcessors() {
 	r.RegisterSecuredProcessor("address.generate", r.rpcProcessAddressGenerate)
 	r.RegisterSecuredProcessor("addressGenerate", r.rpcProcessAddressGenerate)
 
+	r.RegisterCredentialProcessor("address.import.keystore", r.rpcProcessAddressImportKeystore)
+	r.RegisterCredentialProcessor("addressImportKeystore", r.rpcProcessAddressImportKeystore)
+
+	r.RegisterCredentialProcessor("address.export.keystore", r.rpcProcessAddressExportKeystore)
+	r.RegisterCredentialProcessor("addressExportKeystore", r.rpcProcessAddressExportKeystore)
+
 	r.RegisterProcessor("service.register", r.rpcProcessServiceRegister)
 	r.RegisterProcessor("serviceRegister", r.rpcProcessServiceRegister)
 
@@ -42,4 +48,40 @@ func (r *BackRpc) InitProcessors() {
 
 	r.RegisterSecuredProcessor("transfer.get.estimated.fee", r.rpcProcessTransferGetEstimatedFee)
 	r.RegisterSecuredProcessor("transferGetEstimatedFee", r.rpcProcessTransferGetEstimatedFee)
+
+	r.RegisterSecuredProcessor("transfer.speed.up", r.rpcProcessTransferSpeedUp)
+	r.RegisterSecuredProcessor("transferSpeedUp", r.rpcProcessTransferSpeedUp)
+
+	r.RegisterSecuredProcessor("transfer.cancel", r.rpcProcessTransferCancel)
+	r.RegisterSecuredProcessor("transferCancel", r.rpcProcessTransferCancel)
+
+	r.RegisterSecuredProcessor("block.reprocess", r.rpcProcessBlockReprocess)
+	r.RegisterSecuredProcessor("blockReprocess", r.rpcProcessBlockReprocess)
+
+	r.RegisterSecuredProcessor("rescan.blocks", r.rpcProcessRescanBlocks)
+	r.RegisterSecuredProcessor("rescanBlocks", r.rpcProcessRescanBlocks)
+
+	r.RegisterSecuredProcessor("rescan.transaction", r.rpcProcessRescanTransaction)
+	r.RegisterSecuredProcessor("rescanTransaction", r.rpcProcessRescanTransaction)
+
+	r.RegisterSecuredProcessor("rescan.address", r.rpcProcessRescanAddress)
+	r.RegisterSecuredProcessor("rescanAddress", r.rpcProcessRescanAddress)
+
+	r.RegisterSecuredProcessor("rescan.status", r.rpcProcessRescanStatus)
+	r.RegisterSecuredProcessor("rescanStatus", r.rpcProcessRescanStatus)
+
+	r.RegisterSecuredProcessor("rescan.cancel", r.rpcProcessRescanCancel)
+	r.RegisterSecuredProcessor("rescanCancel", r.rpcProcessRescanCancel)
+
+	r.RegisterSecuredProcessor("outbox.list", r.rpcProcessOutboxList)
+	r.RegisterSecuredProcessor("outboxList", r.rpcProcessOutboxList)
+
+	r.RegisterSecuredProcessor("outbox.retry", r.rpcProcessOutboxRetry)
+	r.RegisterSecuredProcessor("outboxRetry", r.rpcProcessOutboxRetry)
+
+	r.RegisterSecuredProcessor("outbox.purge", r.rpcProcessOutboxPurge)
+	r.RegisterSecuredProcessor("outboxPurge", r.rpcProcessOutboxPurge)
+
+	r.RegisterSecuredProcessor("sweep.list", r.rpcProcessSweepList)
+	r.RegisterSecuredProcessor("sweepList", r.rpcProcessSweepList)
 }
@@ -12,6 +12,8 @@ type RpcMethod string
 // RpcProcessor is a function that handles an RPC request.
 type RpcProcessor func(ctx RequestContext, request RpcRequest, response RpcResponse)
 
+// RpcAuth is the request signature of services configured with an ApiKey.
+// Sig is the hex encoded HMAC of the canonical request, see security.CanonicalRequest.
 type RpcAuth struct {
 	ClientID string `json:"clientId"`
 	TS       int64  `json:"ts,omitempty"`
@@ -36,6 +38,16 @@ func (r *JsonRpcRequest) GetMethod() (method RpcMethod) {
 	return r.Method
 }
 
+// GetParamsRaw returns the request parameters as received.
+func (r *JsonRpcRequest) GetParamsRaw() (params json.RawMessage) {
+	return r.Params
+}
+
+// GetAuth returns the request signature, nil if the request is not signed.
+func (r *JsonRpcRequest) GetAuth() (auth *RpcAuth) {
+	return r.Auth
+}
+
 // ParseParams unmarshals the request parameters into the provided struct.
 func (r *JsonRpcRequest) ParseParams(params interface{}) (err error) {
 	return json.Unmarshal(r.Params, params)
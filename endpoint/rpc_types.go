@@ -19,6 +19,16 @@ const (
 	ERROR_MESSAGE_UNAUTHORIZED     = "unauthorized access"
 )
 
+// Request signature error codes.
+const (
+	ERROR_CODE_SIGNATURE_INVALID    = -32002
+	ERROR_MESSAGE_SIGNATURE_INVALID = "invalid request signature"
+	ERROR_CODE_SIGNATURE_EXPIRED    = -32003
+	ERROR_MESSAGE_SIGNATURE_EXPIRED = "request timestamp out of time window"
+	ERROR_CODE_NONCE_REUSED         = -32004
+	ERROR_MESSAGE_NONCE_REUSED      = "request nonce already used"
+)
+
 // RequestId represents a JSON-RPC request identifier.
 // Handles both string and numeric IDs in JSON.
 type RequestId string
@@ -4,6 +4,7 @@
 package endpoint
 
 import (
+	"context"
 	"net"
 
 	"github.com/valyala/fasthttp"
@@ -47,6 +48,12 @@ func (s *endpointServer) ListenAndServe() error {
 	return s.server.Serve(s.ln)
 }
 
+// Stop stops accepting new connections and waits until the running requests are
+// processed. Returns ctx.Err() if the requests are not finished before the context is done.
+func (s *endpointServer) Stop(ctx context.Context) error {
+	return s.server.ShutdownWithContext(ctx)
+}
+
 // Close stops the server by closing the listener.
 func (s *endpointServer) Close() error {
 	return s.ln.Close()
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ITProLabDev/ethbacknode/crypto/keyring"
+	"github.com/ITProLabDev/ethbacknode/storage"
+	"github.com/ITProLabDev/ethbacknode/tools/log"
+)
+
+// Environment variables the keyring passphrases are read from.
+const (
+	// envKeyPassphrase holds the passphrase unlocking the address keys.
+	envKeyPassphrase = "ETHBACKNODE_KEY_PASSPHRASE"
+	// envNewKeyPassphrase holds the new passphrase set by the rekey command.
+	envNewKeyPassphrase = "ETHBACKNODE_NEW_KEY_PASSPHRASE"
+)
+
+// ErrPassphraseEmpty is returned when no passphrase is provided.
+var ErrPassphraseEmpty = errors.New("key passphrase empty")
+
+// loadKeyring unlocks the keyring the address keys are encrypted with.
+// An existing keyring file is always unlocked. Without the file a new keyring is
+// created if key encryption is enabled, the stored keys are encrypted with it on
+// address manager start. Returns nil keyring if key encryption is not used.
+func loadKeyring(store storage.BinStorage) (k *keyring.Keyring, err error) {
+	if store.IsExists() {
+		data, err := store.Load()
+		if err != nil {
+			return nil, err
+		}
+		passphrase, err := readPassphrase(config.KeyPassphraseFile, envKeyPassphrase, "Key passphrase: ")
+		if err != nil {
+			return nil, err
+		}
+		return keyring.Unlock(data, passphrase)
+	}
+	if !config.EncryptKeys {
+		log.Warning("Address keys are stored unencrypted, set encryptKeys in config to encrypt them")
+		return nil, nil
+	}
+	log.Info("Creating new keyring, stored address keys will be encrypted")
+	passphrase, err := readPassphrase(config.KeyPassphraseFile, envKeyPassphrase, "New key passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	k, err = keyring.New()
+	if err != nil {
+		return nil, err
+	}
+	data, err := k.Lock(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return k, store.Save(data)
+}
+
+// rekeyKeyring changes the passphrase of the keyring. Only the master key is
+// rewrapped, the encrypted address records stay sealed with the same master key,
+// so the master key is not rotated.
+func rekeyKeyring(store storage.BinStorage, newPassphraseFile string) (err error) {
+	if !store.IsExists() {
+		return errors.New("keyring not found, enable encryptKeys first")
+	}
+	data, err := store.Load()
+	if err != nil {
+		return err
+	}
+	passphrase, err := readPassphrase(config.KeyPassphraseFile, envKeyPassphrase, "Current key passphrase: ")
+	if err != nil {
+		return err
+	}
+	k, err := keyring.Unlock(data, passphrase)
+	if err != nil {
+		return err
+	}
+	newPassphrase, err := readPassphrase(newPassphraseFile, envNewKeyPassphrase, "New key passphrase: ")
+	if err != nil {
+		return err
+	}
+	data, err = k.Lock(newPassphrase)
+	if err != nil {
+		return err
+	}
+	return store.Save(data)
+}
+
+// readPassphrase reads a passphrase from the file, the environment variable or,
+// if neither is set, a line of stdin. The trailing newline is trimmed.
+func readPassphrase(file, env, prompt string) (passphrase []byte, err error) {
+	var raw string
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		raw = string(data)
+	} else if value, found := os.LookupEnv(env); found {
+		raw = value
+	} else {
+		fmt.Fprint(os.Stderr, prompt)
+		raw, err = bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && raw == "" {
+			return nil, err
+		}
+	}
+	raw = strings.TrimRight(raw, "\r\n")
+	if raw == "" {
+		return nil, ErrPassphraseEmpty
+	}
+	return []byte(raw), nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ITProLabDev/ethbacknode/address"
+	"github.com/ITProLabDev/ethbacknode/tools/log"
+)
+
+// envKeystorePassphrase holds the passphrase of the imported or exported keystore file.
+const envKeystorePassphrase = "ETHBACKNODE_KEYSTORE_PASSPHRASE"
+
+// ErrKeystoreServiceId is returned when a keystore is imported without the service id.
+var ErrKeystoreServiceId = errors.New("service id required, set -service-id")
+
+// runKeystoreCommand imports the keystore V3 file into the address manager or
+// exports the managed address as a keystore V3 file. The exported file is written
+// to keystoreOut, or to stdout if it is not set.
+func runKeystoreCommand(addressManager *address.Manager, addressCodec address.AddressCodec) (err error) {
+	if importKeystore != "" && keystoreServiceId <= 0 {
+		return ErrKeystoreServiceId
+	}
+	passphrase, err := readPassphrase(keystorePassphraseFile, envKeystorePassphrase, "Keystore passphrase: ")
+	if err != nil {
+		return err
+	}
+	if importKeystore != "" {
+		data, err := os.ReadFile(importKeystore)
+		if err != nil {
+			return err
+		}
+		addressRecord, err := addressManager.ImportKeystore(data, passphrase, func(a *address.Address) {
+			a.ServiceId = keystoreServiceId
+			a.Subscribed = true
+		})
+		if err != nil {
+			return err
+		}
+		log.Info("Address", addressRecord.Address, "imported for service", keystoreServiceId)
+		return nil
+	}
+	addressBytes, err := addressCodec.DecodeAddressToBytes(exportKeystore)
+	if err != nil {
+		return err
+	}
+	addressString, err := addressCodec.EncodeBytesToAddress(addressBytes)
+	if err != nil {
+		return err
+	}
+	data, err := addressManager.ExportKeystore(addressString, passphrase)
+	if err != nil {
+		return err
+	}
+	if keystoreOut == "" {
+		fmt.Println(string(data))
+		return nil
+	}
+	err = os.WriteFile(keystoreOut, data, 0600)
+	if err != nil {
+		return err
+	}
+	log.Info("Address", addressString, "exported to", keystoreOut)
+	return nil
+}
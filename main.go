@@ -4,16 +4,21 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"net/url"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/ITProLabDev/ethbacknode/abi"
 	"github.com/ITProLabDev/ethbacknode/address"
 	"github.com/ITProLabDev/ethbacknode/clients/ethclient"
+	"github.com/ITProLabDev/ethbacknode/clients/remotesigner"
+	"github.com/ITProLabDev/ethbacknode/crypto"
 	"github.com/ITProLabDev/ethbacknode/endpoint"
 	"github.com/ITProLabDev/ethbacknode/security"
 	"github.com/ITProLabDev/ethbacknode/storage"
@@ -33,10 +38,29 @@ const (
 	APP_NAME = "EthBackNode"
 )
 
+// shutdownTimeout limits the time given to services to stop gracefully.
+const shutdownTimeout = 30 * time.Second
+
 // Global variables for application state and configuration.
 var (
 	// globalConfigPath is the path to the configuration file (default: config.hcl).
 	globalConfigPath = "config.hcl"
+	// rekey requests the change of the key passphrase, the application exits after it.
+	rekey bool
+	// newPassphraseFile is the file the new key passphrase is read from by rekey.
+	newPassphraseFile string
+	// showSeed requests the master seed mnemonic to be printed, the application exits after it.
+	showSeed bool
+	// importKeystore is the keystore file imported for keystoreServiceId, the application exits after it.
+	importKeystore string
+	// exportKeystore is the address exported as a keystore file, the application exits after it.
+	exportKeystore string
+	// keystoreOut is the file the exported keystore is written to, stdout if empty.
+	keystoreOut string
+	// keystorePassphraseFile is the file the keystore passphrase is read from.
+	keystorePassphraseFile string
+	// keystoreServiceId is the service the imported keystore address is subscribed for.
+	keystoreServiceId int
 	// config holds the global application configuration.
 	config = &Config{
 		storage: _configDefaultStorage(),
@@ -88,6 +112,67 @@ func main() {
 		log.Error("Can not init storage manager:", err)
 		os.Exit(-1)
 	}
+	// Unlock the keyring the address keys are encrypted with at rest
+	addressStorage := storageManager.GetModuleStorage("Address", "address")
+	keyringStorage := addressStorage.GetBinFileStorage("keyring.json")
+	if rekey {
+		err = rekeyKeyring(keyringStorage, newPassphraseFile)
+		if err != nil {
+			log.Error("Can not change key passphrase:", err)
+			os.Exit(-1)
+		}
+		log.Info("Key passphrase changed")
+		os.Exit(0)
+	}
+	addressKeyring, err := loadKeyring(keyringStorage)
+	if err != nil {
+		log.Error("Can not unlock address keys:", err)
+		os.Exit(-1)
+	}
+	seedStorage := addressStorage.GetBinFileStorage("seed.json")
+	if showSeed {
+		mnemonic, err := address.ReadMasterMnemonic(seedStorage, addressKeyring)
+		if err != nil {
+			log.Error("Can not read master seed:", err)
+			os.Exit(-1)
+		}
+		fmt.Println(strings.Join(mnemonic, " "))
+		os.Exit(0)
+	}
+	// Address Manager options
+	addressOptions := []address.MemPoolOption{
+		address.WithAddressCodec(addressCodec),
+		address.WithConfigStorage(addressStorage.GetBinFileStorage("config.json")),
+		address.WithAddressStorage(addressStorage.GetNewBadgerStorage("addresses.db")),
+		address.WithSeedStorage(seedStorage),
+	}
+	if addressKeyring != nil {
+		addressOptions = append(addressOptions, address.WithKeyring(addressKeyring))
+	}
+	if config.RemoteSignerUrl != "" {
+		// Keys of the external signer addresses are held by the remote signer
+		addressOptions = append(addressOptions, address.WithExternalSigner(func(addressBytes []byte) (crypto.Signer, error) {
+			return remotesigner.NewRemoteSigner(config.RemoteSignerUrl, config.RemoteSignerHeaders, addressBytes), nil
+		}))
+	}
+	if importKeystore != "" || exportKeystore != "" {
+		addressManager, err := address.NewManager(addressOptions...)
+		if err != nil {
+			log.Error("Can not init address manager:", err)
+			os.Exit(-1)
+		}
+		err = runKeystoreCommand(addressManager, addressCodec)
+		closeErr := storageManager.Close()
+		if err != nil {
+			log.Error("Keystore command failed:", err)
+			os.Exit(-1)
+		}
+		if closeErr != nil {
+			log.Error("Can not close storage:", closeErr)
+			os.Exit(-1)
+		}
+		os.Exit(0)
+	}
 	// Get Address Codec
 	// Init Smart Contract ABI manager
 	abiStorage := storageManager.GetModuleStorage("ABI", "abi")
@@ -104,6 +189,7 @@ func main() {
 	var clientOptions = []ethclient.Option{
 		ethclient.WithConfigStorage(clientStorage.GetBinFileStorage("config.json")),
 		ethclient.WithAbiManager(abiManager),
+		ethclient.WithNonceStorage(clientStorage.GetNewBadgerHoldStorage("nonces.db")),
 	}
 	if config.NodeUseIPC {
 		clientOptions = append(clientOptions, ethclient.WithIPCClient(config.NodeIPCSocket))
@@ -130,12 +216,7 @@ func main() {
 		log.Info("- Token:", token.Name, "(", token.Symbol, ")")
 	}
 	// Init Address Manager
-	addressStorage := storageManager.GetModuleStorage("Address", "address")
-	addressManager, err := address.NewManager(
-		address.WithAddressCodec(addressCodec),
-		address.WithConfigStorage(addressStorage.GetBinFileStorage("config.json")),
-		address.WithAddressStorage(addressStorage.GetNewBadgerStorage("addresses.db")),
-	)
+	addressManager, err := address.NewManager(addressOptions...)
 	if err != nil {
 		log.Error("Can not init address manager:", err)
 		os.Exit(-1)
@@ -143,6 +224,14 @@ func main() {
 	if config.DebugMode {
 		addressManager.DevDumpMemPool()
 	}
+	// Stuck outgoing transactions are sped up with the signers of the known addresses
+	chainClient.SetSignerSource(func(address string) (crypto.Signer, error) {
+		addressInfo, err := addressManager.GetAddress(address)
+		if err != nil {
+			return nil, err
+		}
+		return addressManager.AddressSigner(addressInfo)
+	})
 	// Init Watchdog Service
 	watchdogStorage := storageManager.GetModuleStorage("Watchdog", "watchdog")
 	watchDogOptions := []watchdog.ServiceOption{
@@ -155,14 +244,26 @@ func main() {
 		log.Warning("DEBUG MODE: reset watchdog last state to 0 block")
 	}
 	watchdogService := watchdog.NewService(watchDogOptions...)
+	securityMaanger := security.NewManager(
+		security.WithStorageManager(storageManager.GetModuleStorage("Security", "security")),
+	)
+
+	err = securityMaanger.Init()
+	if err != nil {
+		log.Error("Can not start security manager:", err)
+		os.Exit(-1)
+	}
+
 	subscriptionsStorage := storageManager.GetModuleStorage("Subscriptions", "subscriptions")
 	subscriptionsManager, err := subscriptions.NewManager(
 		subscriptions.WithAddressManager(addressManager),
 		subscriptions.WithSubscribersStorage(subscriptionsStorage.GetBinFileStorage("subscribers.json")),
 		subscriptions.WithTransactionStorage(subscriptionsStorage.GetNewBadgerHoldStorage("transactions.db")),
+		subscriptions.WithOutboxStorage(subscriptionsStorage.GetNewBadgerHoldStorage("outbox.db")),
 		subscriptions.WithBlockchainClient(chainClient),
 		subscriptions.WithConfigStorage(subscriptionsStorage.GetBinFileStorage("config.json")),
 		subscriptions.WithGlobalConfig(config),
+		subscriptions.WithKeyDecoder(securityMaanger.DecodeKey),
 	)
 
 	if err != nil {
@@ -184,6 +285,10 @@ func main() {
 	watchdogService.RegisterTransactionEventListen(txCacheManager.TransactionEvent)
 	watchdogService.RegisterBlockEventListen(subscriptionsManager.BlockEvent)
 	watchdogService.RegisterBlockEventListen(txCacheManager.BlockEvent)
+	watchdogService.RegisterBlockEventListen(chainClient.BlockEvent)
+	watchdogService.RegisterBlockRevertedEventListen(subscriptionsManager.BlockRevertedEvent)
+	watchdogService.RegisterBlockRevertedEventListen(txCacheManager.BlockRevertedEvent)
+	chainClient.SetReplacementListener(subscriptionsManager.TransactionReplacedEvent)
 
 	log.Info("Init complete")
 	err = watchdogService.Run()
@@ -192,16 +297,6 @@ func main() {
 		os.Exit(-1)
 	}
 
-	securityMaanger := security.NewManager(
-		security.WithStorageManager(storageManager.GetModuleStorage("Security", "security")),
-	)
-
-	err = securityMaanger.Init()
-	if err != nil {
-		log.Error("Can not start security manager:", err)
-		os.Exit(-1)
-	}
-
 	endpointRpcRouter := endpoint.NewBackRpc(
 		addressManager,
 		chainClient,
@@ -229,7 +324,7 @@ func main() {
 	}
 	log.Info("Start endpoint server on:", endpointUrl.Host)
 	go func() {
-		err = endpointServer.ListenAndServe()
+		err := endpointServer.ListenAndServe()
 		if err != nil {
 			log.Error("Can not start endpoint server:", err)
 			done <- true
@@ -237,9 +332,31 @@ func main() {
 	}()
 	// Start main loop
 	run()
+	// Stop services in reverse init order
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	stopService(ctx, "endpoint server", endpointServer.Stop)
+	stopService(ctx, "watchdog service", watchdogService.Stop)
+	stopService(ctx, "transactions cache manager", txCacheManager.Stop)
+	stopService(ctx, "subscriptions manager", subscriptionsManager.Stop)
+	stopService(ctx, "chain client", chainClient.Stop)
+	stopService(ctx, "address manager", addressManager.Stop)
+	err = storageManager.Close()
+	if err != nil {
+		log.Error("Can not close storage:", err)
+	}
 	log.Info("Application stopped")
 }
 
+// stopService stops a service and logs the failure, shutdown continues anyway.
+func stopService(ctx context.Context, name string, stop func(ctx context.Context) error) {
+	log.Info("Stop", name)
+	err := stop(ctx)
+	if err != nil {
+		log.Error("Can not stop", name, ":", err)
+	}
+}
+
 // run is the main event loop that waits for shutdown signals.
 // It listens on two channels:
 // - done: internal shutdown signal (e.g., server error)
@@ -261,9 +378,23 @@ func run() {
 // Supported flags:
 //   - config: path to configuration file (default: config.hcl)
 //   - help: display usage information
+//   - rekey: change the passphrase of the address keys and exit
+//   - new-passphrase-file: file with the new passphrase for rekey
+//   - show-seed: print the master seed mnemonic of the single seed mode and exit
+//   - import-keystore: import a keystore V3 file for the service-id service and exit
+//   - export-keystore: export a managed address as a keystore V3 file to keystore-out and exit
+//   - keystore-passphrase-file: file with the passphrase of the keystore file
 func init() {
 	var help bool
 	flag.StringVar(&globalConfigPath, "config", "config.hcl", "Path to global config file")
+	flag.BoolVar(&rekey, "rekey", false, "Change the address keys passphrase and exit")
+	flag.StringVar(&newPassphraseFile, "new-passphrase-file", "", "Path to file with the new passphrase for rekey")
+	flag.BoolVar(&showSeed, "show-seed", false, "Print the master seed mnemonic for backup and exit")
+	flag.StringVar(&importKeystore, "import-keystore", "", "Import the keystore V3 file for the service-id service and exit")
+	flag.IntVar(&keystoreServiceId, "service-id", 0, "Service id the imported keystore address is subscribed for")
+	flag.StringVar(&exportKeystore, "export-keystore", "", "Export the managed address as a keystore V3 file and exit")
+	flag.StringVar(&keystoreOut, "keystore-out", "", "Path to the exported keystore file, stdout if empty")
+	flag.StringVar(&keystorePassphraseFile, "keystore-passphrase-file", "", "Path to file with the keystore passphrase")
 	flag.BoolVar(&help, "help", false, "Show help")
 	flag.Parse()
 	if help {
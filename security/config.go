@@ -22,6 +22,9 @@ const (
 	SIGNATURE_TYPE_RIPEMD = "RIPEMD"
 	// SIGNATURE_TYPE_SHA512 indicates SHA-512 hashing.
 	SIGNATURE_TYPE_SHA512 = "SHA512"
+
+	// defaultSignatureTimeWindow is the default accepted clock skew of signed requests, in seconds.
+	defaultSignatureTimeWindow = 300
 )
 
 // Config holds the security manager configuration.
@@ -30,6 +33,8 @@ type Config struct {
 	Debug         bool   `json:"debug"`
 	KeyFormat     string `json:"defaultKeyFormat"`
 	SignatureType string `json:"signatureType"`
+	// SignatureTimeWindow is the accepted difference between the request timestamp and the server time, in seconds.
+	SignatureTimeWindow int `json:"signatureTimeWindow"`
 }
 
 // _configDefaultStorage returns the default file-based storage for configuration.
@@ -86,6 +91,10 @@ func (c *Config) checkDefaults() error {
 		c.SignatureType = SIGNATURE_TYPE_SHA256
 		changed = true
 	}
+	if c.SignatureTimeWindow <= 0 {
+		c.SignatureTimeWindow = defaultSignatureTimeWindow
+		changed = true
+	}
 	if changed {
 		return c.Save()
 	}
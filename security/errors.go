@@ -6,4 +6,18 @@ import "errors"
 var (
 	// ErrConfigStorageEmpty is returned when config storage is not configured.
 	ErrConfigStorageEmpty = errors.New("config storage is empty")
+	// ErrUnsupportedKeyFormat is returned when the configured key format is unknown.
+	ErrUnsupportedKeyFormat = errors.New("unsupported key format")
+	// ErrUnsupportedSignatureType is returned when the signature type is unknown or differs from the configured one.
+	ErrUnsupportedSignatureType = errors.New("unsupported signature type")
+	// ErrInvalidApiKey is returned when the api key can not be decoded in the configured format.
+	ErrInvalidApiKey = errors.New("invalid api key")
+	// ErrSignatureRequired is returned when the request signature, nonce or timestamp is missing.
+	ErrSignatureRequired = errors.New("request signature required")
+	// ErrSignatureInvalid is returned when the request signature does not match.
+	ErrSignatureInvalid = errors.New("invalid request signature")
+	// ErrSignatureExpired is returned when the request timestamp is out of the time window.
+	ErrSignatureExpired = errors.New("request timestamp out of time window")
+	// ErrNonceReused is returned when the request nonce was already used.
+	ErrNonceReused = errors.New("request nonce already used")
 )
@@ -10,14 +10,20 @@ type Option func(*Manager)
 // Manager handles request signing and security configuration.
 type Manager struct {
 	config *Config
+	nonces nonceCache
 
 	storageManager *storage.ModuleManager
 }
 
 // NewManager creates a new security manager with the specified options.
+// The default configuration is used until Init loads the stored one.
 func NewManager(opts ...Option) *Manager {
 	manager := &Manager{
-		config: &Config{},
+		config: &Config{
+			KeyFormat:           KEY_FORMAT_HEX,
+			SignatureType:       SIGNATURE_TYPE_SHA256,
+			SignatureTimeWindow: defaultSignatureTimeWindow,
+		},
 	}
 	for _, opt := range opts {
 		opt(manager)
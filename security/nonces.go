@@ -0,0 +1,58 @@
+package security
+
+import (
+	"container/heap"
+	"sync"
+)
+
+// nonceCache remembers the used request nonces until their timestamp leaves the time window.
+// The nonces are evicted in expiry order, the expiry times are not monotonic as the
+// request timestamps may be anywhere within the window.
+type nonceCache struct {
+	mux    sync.Mutex
+	expire map[string]int64
+	queue  nonceQueue
+}
+
+// use registers the nonce, returns ErrNonceReused if it is already known.
+func (c *nonceCache) use(nonce string, now, expire int64) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.expire == nil {
+		c.expire = make(map[string]int64)
+	}
+	for len(c.queue) > 0 && c.queue[0].expire < now {
+		delete(c.expire, heap.Pop(&c.queue).(nonceExpiry).nonce)
+	}
+	if _, found := c.expire[nonce]; found {
+		return ErrNonceReused
+	}
+	c.expire[nonce] = expire
+	heap.Push(&c.queue, nonceExpiry{nonce: nonce, expire: expire})
+	return nil
+}
+
+// nonceExpiry is the expiry time of a nonce.
+type nonceExpiry struct {
+	nonce  string
+	expire int64
+}
+
+// nonceQueue is a min-heap of the nonces by expiry time.
+type nonceQueue []nonceExpiry
+
+func (q nonceQueue) Len() int           { return len(q) }
+func (q nonceQueue) Less(i, j int) bool { return q[i].expire < q[j].expire }
+func (q nonceQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *nonceQueue) Push(x any) {
+	*q = append(*q, x.(nonceExpiry))
+}
+
+func (q *nonceQueue) Pop() any {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	*q = old[:n-1]
+	return item
+}
@@ -0,0 +1,71 @@
+package security
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+)
+
+func TestNonceCache(t *testing.T) {
+	c := new(nonceCache)
+	if err := c.use("a", 100, 110); err != nil {
+		t.Fatalf("use: %v", err)
+	}
+	if err := c.use("b", 100, 200); err != nil {
+		t.Fatalf("use: %v", err)
+	}
+	if err := c.use("a", 105, 115); !errors.Is(err, ErrNonceReused) {
+		t.Fatalf("reused nonce: got %v, want ErrNonceReused", err)
+	}
+	// a expires after 110, b is kept until 200
+	if err := c.use("c", 111, 121); err != nil {
+		t.Fatalf("use: %v", err)
+	}
+	if _, found := c.expire["a"]; found {
+		t.Fatal("expired nonce not evicted")
+	}
+	if len(c.expire) != 2 || len(c.queue) != 2 {
+		t.Fatalf("cache size: got %d (queue %d), want 2", len(c.expire), len(c.queue))
+	}
+	if err := c.use("a", 112, 122); err != nil {
+		t.Fatalf("evicted nonce: %v", err)
+	}
+	if err := c.use("b", 112, 122); !errors.Is(err, ErrNonceReused) {
+		t.Fatalf("unexpired nonce: got %v, want ErrNonceReused", err)
+	}
+}
+
+// Nonces are evicted in expiry order whatever the order they were used in.
+func TestNonceCache_EvictsByExpiry(t *testing.T) {
+	c := new(nonceCache)
+	for i, expire := range []int64{130, 110, 150, 120, 140} {
+		if err := c.use(fmt.Sprint("n", i), 100, expire); err != nil {
+			t.Fatalf("use: %v", err)
+		}
+	}
+	tests := []struct {
+		now  int64
+		want []string
+	}{
+		{now: 110, want: []string{"n0", "n1", "n2", "n3", "n4"}},
+		{now: 121, want: []string{"n0", "n2", "n4"}},
+		{now: 145, want: []string{"n2"}},
+		{now: 151, want: nil},
+	}
+	for _, tt := range tests {
+		if err := c.use("probe", tt.now, 0); err != nil {
+			t.Fatalf("use probe at %d: %v", tt.now, err)
+		}
+		var kept []string
+		for nonce := range c.expire {
+			if nonce != "probe" {
+				kept = append(kept, nonce)
+			}
+		}
+		sort.Strings(kept)
+		if fmt.Sprint(kept) != fmt.Sprint(tt.want) {
+			t.Fatalf("at %d: kept %v, want %v", tt.now, kept, tt.want)
+		}
+	}
+}
@@ -1,69 +1,150 @@
 package security
 
 import (
+	"bytes"
+	"crypto/hmac"
 	"crypto/sha256"
 	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"hash"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/ITProLabDev/ethbacknode/common/base58"
 	"github.com/ITProLabDev/ethbacknode/common/hexnum"
-	"github.com/ITProLabDev/ethbacknode/tools/log"
 	"golang.org/x/crypto/ripemd160"
 )
 
 // SignRequest generates a signature for an RPC request.
-// Uses the configured key format and signature type.
-func (m *Manager) SignRequest(apiKey string, method string, params json.RawMessage) (sign []byte, err error) {
-	//todo decode api key
-	var keyBytes []byte
+// The signature is the HMAC of the canonical request keyed by the decoded apiKey,
+// using the configured key format and signature type.
+func (m *Manager) SignRequest(apiKey string, method string, params json.RawMessage, timestamp int64, nonce string) (sign []byte, err error) {
+	keyBytes, err := m.DecodeKey(apiKey)
+	if err != nil {
+		return nil, err
+	}
+	hashFunc := _hashFunc(m.config.SignatureType)
+	if hashFunc == nil {
+		return nil, ErrUnsupportedSignatureType
+	}
+	message, err := CanonicalRequest(method, params, timestamp, nonce)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(hashFunc, keyBytes)
+	mac.Write(message)
+	return mac.Sum(nil), nil
+}
+
+// VerifyRequest checks the hex encoded request signature, the timestamp window and
+// the nonce uniqueness. Nonces are tracked per client within the time window.
+// An empty alg means the configured signature type.
+func (m *Manager) VerifyRequest(clientId, apiKey, method string, params json.RawMessage, timestamp int64, nonce, alg, signature string) error {
+	if signature == "" || nonce == "" || timestamp == 0 {
+		return ErrSignatureRequired
+	}
+	if alg != "" && !strings.EqualFold(alg, m.config.SignatureType) {
+		return ErrUnsupportedSignatureType
+	}
+	window := int64(m.config.SignatureTimeWindow)
+	now := time.Now().Unix()
+	if timestamp < now-window || timestamp > now+window {
+		return ErrSignatureExpired
+	}
+	expected, err := m.SignRequest(apiKey, method, params, timestamp, nonce)
+	if err != nil {
+		return err
+	}
+	signBytes, err := hex.DecodeString(strings.TrimPrefix(signature, "0x"))
+	if err != nil || !hmac.Equal(expected, signBytes) {
+		return ErrSignatureInvalid
+	}
+	return m.nonces.use(clientId+":"+nonce, now, timestamp+window)
+}
+
+// CanonicalRequest builds the signed message of an RPC request:
+// "<method>\n<params>\n<nonce>\n<timestamp>", where params is the compact JSON of the
+// request params with sorted object keys and without HTML escaping.
+func CanonicalRequest(method string, params json.RawMessage, timestamp int64, nonce string) ([]byte, error) {
+	canonicalParams, err := canonicalJson(params)
+	if err != nil {
+		return nil, err
+	}
+	message := make([]byte, 0, len(method)+len(canonicalParams)+len(nonce)+24)
+	message = append(message, method...)
+	message = append(message, '\n')
+	message = append(message, canonicalParams...)
+	message = append(message, '\n')
+	message = append(message, nonce...)
+	message = append(message, '\n')
+	message = strconv.AppendInt(message, timestamp, 10)
+	return message, nil
+}
+
+// canonicalJson re-encodes the JSON value with sorted object keys, numbers are kept as is.
+func canonicalJson(data json.RawMessage) ([]byte, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// jsonKey is a symmetric key in JWK format: {"kty":"oct","k":"<base64url key>"}.
+type jsonKey struct {
+	Kty string `json:"kty"`
+	K   string `json:"k"`
+}
+
+// DecodeKey decodes the apiKey according to the configured key format.
+// The decoded key signs both the requests and the notifications of the service.
+func (m *Manager) DecodeKey(apiKey string) (keyBytes []byte, err error) {
 	switch m.config.KeyFormat {
 	case KEY_FORMAT_HEX:
 		keyBytes, err = hexnum.ParseHexBytes(apiKey)
-		if err != nil {
-			return nil, err
-		}
 	case KEY_FORMAT_BASE58:
-		//todo
+		keyBytes = base58.Decode(apiKey)
 	case KEY_FORMAT_JSON:
-		//todo
+		key := new(jsonKey)
+		err = json.Unmarshal([]byte(apiKey), key)
+		if err != nil || key.Kty != "oct" {
+			return nil, ErrInvalidApiKey
+		}
+		keyBytes, err = base64.RawURLEncoding.DecodeString(strings.TrimRight(key.K, "="))
+	default:
+		return nil, ErrUnsupportedKeyFormat
 	}
-
-	requestBytes := append(keyBytes, append([]byte(method), params...)...)
-
-	signBytes := m._signBytes(requestBytes, m.config.SignatureType)
-
-	return signBytes, nil
+	if err != nil || len(keyBytes) == 0 {
+		return nil, ErrInvalidApiKey
+	}
+	return keyBytes, nil
 }
 
-// _signBytes computes a hash signature of the data using the specified algorithm.
-func (m *Manager) _signBytes(data []byte, signType string) []byte {
-	switch signType {
+// _hashFunc returns the hash function of the signature type, nil if unsupported.
+func _hashFunc(signType string) func() hash.Hash {
+	switch strings.ToUpper(signType) {
 	case SIGNATURE_TYPE_SHA256:
-		return _hashBytesSha256(data)
+		return sha256.New
 	case SIGNATURE_TYPE_SHA512:
-		return _hashBytesSha512(data)
+		return sha512.New
 	case SIGNATURE_TYPE_RIPEMD:
-		return _hashBytesRIPEMD(data)
+		return ripemd160.New
 	default:
-		log.Warning("unsupported signType:", signType, "use default")
-		return m._signBytes(data, m.config.SignatureType)
+		return nil
 	}
 }
-
-// _hashBytesSha256 computes the SHA-256 hash of the data.
-func _hashBytesSha256(data []byte) []byte {
-	hash := sha256.Sum256(data)
-	return hash[:]
-}
-
-// _hashBytesSha512 computes the SHA-512 hash of the data.
-func _hashBytesSha512(data []byte) []byte {
-	hash := sha512.Sum512(data)
-	return hash[:]
-}
-
-// _hashBytesRIPEMD computes the RIPEMD-160 hash of the data.
-func _hashBytesRIPEMD(data []byte) []byte {
-	hash := ripemd160.New()
-	hash.Write(data)
-	return hash.Sum(nil)
-}
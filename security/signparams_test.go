@@ -0,0 +1,101 @@
+package security
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+const (
+	testClientId = "1"
+	testApiKey   = "0x000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+	testMethod   = "addressGetNew"
+)
+
+func testSign(t *testing.T, m *Manager, apiKey string, params json.RawMessage, timestamp int64, nonce string) string {
+	t.Helper()
+	sign, err := m.SignRequest(apiKey, testMethod, params, timestamp, nonce)
+	if err != nil {
+		t.Fatalf("SignRequest: %v", err)
+	}
+	return hex.EncodeToString(sign)
+}
+
+func TestVerifyRequest(t *testing.T) {
+	m := NewManager()
+	params := json.RawMessage(`{"serviceId":1,"userId":2}`)
+	now := time.Now().Unix()
+	otherKey := "0x1f1e1d1c1b1a191817161514131211100f0e0d0c0b0a09080706050403020100"
+	tests := []struct {
+		name      string
+		signKey   string
+		timestamp int64
+		nonce     string
+		alg       string
+		want      error
+	}{
+		{name: "valid", signKey: testApiKey, timestamp: now, nonce: "n1"},
+		{name: "valid algorithm", signKey: testApiKey, timestamp: now, nonce: "n2", alg: "sha256"},
+		{name: "wrong key", signKey: otherKey, timestamp: now, nonce: "n3", want: ErrSignatureInvalid},
+		{name: "unsupported algorithm", signKey: testApiKey, timestamp: now, nonce: "n4", alg: "SHA512", want: ErrUnsupportedSignatureType},
+		{name: "skew inside window", signKey: testApiKey, timestamp: now - defaultSignatureTimeWindow + 5, nonce: "n5"},
+		{name: "past timestamp", signKey: testApiKey, timestamp: now - defaultSignatureTimeWindow - 5, nonce: "n6", want: ErrSignatureExpired},
+		{name: "future timestamp", signKey: testApiKey, timestamp: now + defaultSignatureTimeWindow + 5, nonce: "n7", want: ErrSignatureExpired},
+		{name: "replayed nonce", signKey: testApiKey, timestamp: now, nonce: "n1", want: ErrNonceReused},
+	}
+	for _, tt := range tests {
+		signature := testSign(t, m, tt.signKey, params, tt.timestamp, tt.nonce)
+		err := m.VerifyRequest(testClientId, testApiKey, testMethod, params, tt.timestamp, tt.nonce, tt.alg, signature)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+	// nonces are tracked per client
+	signature := testSign(t, m, testApiKey, params, now, "n1")
+	if err := m.VerifyRequest("2", testApiKey, testMethod, params, now, "n1", "", signature); err != nil {
+		t.Errorf("nonce of another client: %v", err)
+	}
+	if err := m.VerifyRequest(testClientId, testApiKey, testMethod, params, now, "n8", "", ""); !errors.Is(err, ErrSignatureRequired) {
+		t.Errorf("missing signature: got %v", err)
+	}
+}
+
+func TestVerifyRequest_ReorderedParams(t *testing.T) {
+	m := NewManager()
+	now := time.Now().Unix()
+	signature := testSign(t, m, testApiKey, json.RawMessage(`{"a":1,"b":{"d":"<x>","c":[2,1]}}`), now, "n1")
+	reordered := json.RawMessage(` { "b" : { "c" : [2, 1], "d" : "<x>" }, "a" : 1 } `)
+	if err := m.VerifyRequest(testClientId, testApiKey, testMethod, reordered, now, "n1", "", signature); err != nil {
+		t.Fatalf("reordered params: %v", err)
+	}
+	changed := json.RawMessage(`{"a":1,"b":{"d":"<x>","c":[1,2]}}`)
+	if err := m.VerifyRequest(testClientId, testApiKey, testMethod, changed, now, "n2", "", signature); !errors.Is(err, ErrSignatureInvalid) {
+		t.Fatalf("changed params: got %v, want ErrSignatureInvalid", err)
+	}
+}
+
+func TestCanonicalRequest(t *testing.T) {
+	tests := []struct {
+		params json.RawMessage
+		want   string
+	}{
+		{params: json.RawMessage(`{"z":1,"a":"<b>&","m":[{"y":true,"x":null}]}`), want: `{"a":"<b>&","m":[{"x":null,"y":true}],"z":1}`},
+		{params: json.RawMessage(`{"amount":1000000000000000000000001, "fee":0.10}`), want: `{"amount":1000000000000000000000001,"fee":0.10}`},
+		{params: json.RawMessage(` [3, "a"] `), want: `[3,"a"]`},
+		{params: nil, want: ``},
+	}
+	for _, tt := range tests {
+		message, err := CanonicalRequest("method", tt.params, 1700000000, "nonce")
+		if err != nil {
+			t.Fatalf("%s: %v", tt.params, err)
+		}
+		if want := "method\n" + tt.want + "\nnonce\n1700000000"; string(message) != want {
+			t.Errorf("%s:\n got %q\nwant %q", tt.params, message, want)
+		}
+	}
+	if _, err := CanonicalRequest("method", json.RawMessage(`{"a":`), 1700000000, "nonce"); err == nil {
+		t.Error("invalid params accepted")
+	}
+}
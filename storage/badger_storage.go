@@ -102,6 +102,14 @@ func (s *BadgerStorage) Do(processor func(db *badger.DB)) {
 	processor(s.db)
 }
 
+// Close flushes and closes the database.
+func (s *BadgerStorage) Close() error {
+	if s.db == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
 // Save persists data to the database using the data's key.
 func (s *BadgerStorage) Save(value Data) (err error) {
 	key := value.GetKey()
@@ -183,6 +191,88 @@ func (s *BadgerStorage) Delete(rowKey []byte) (err error) {
 	})
 }
 
+// Rebuild rewrites the database into a fresh one holding only the current value of
+// every record, then replaces the database files with the new ones. The old versions
+// of the records, kept by Badger in the value log until garbage collection, are dropped
+// with the old files. Must not be called concurrently with other operations.
+func (s *BadgerStorage) Rebuild() (err error) {
+	dbFile := path.Join(s.GlobalDbPath, s.DataPath, s.DataBaseName)
+	rebuildFile := dbFile + ".rebuild"
+	err = os.RemoveAll(rebuildFile)
+	if err != nil {
+		return err
+	}
+	options := badger.DefaultOptions(rebuildFile)
+	options.Dir = rebuildFile
+	options.ValueDir = rebuildFile
+	options.Logger = nil
+	rebuildDb, err := badger.Open(options)
+	if err != nil {
+		return err
+	}
+	batch := rebuildDb.NewWriteBatch()
+	err = s.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			err = batch.Set(item.KeyCopy(nil), value)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		err = batch.Flush()
+	} else {
+		batch.Cancel()
+	}
+	closeErr := rebuildDb.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.RemoveAll(rebuildFile)
+		return err
+	}
+	err = s.db.Close()
+	if err != nil {
+		return err
+	}
+	// the old files are removed only once the new ones are in place
+	oldFile := dbFile + ".old"
+	err = os.RemoveAll(oldFile)
+	if err == nil {
+		err = os.Rename(dbFile, oldFile)
+	}
+	if err != nil {
+		connectErr := s.connect()
+		if connectErr != nil {
+			log.Error("Can not reopen DB:", dbFile, connectErr)
+		}
+		return err
+	}
+	err = os.Rename(rebuildFile, dbFile)
+	if err != nil {
+		os.Rename(oldFile, dbFile)
+		connectErr := s.connect()
+		if connectErr != nil {
+			log.Error("Can not reopen DB:", dbFile, connectErr)
+		}
+		return err
+	}
+	err = s.connect()
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(oldFile)
+}
+
 // Replicator defines an interface for asynchronous data replication.
 type Replicator interface {
 	// Update is called when data is saved.
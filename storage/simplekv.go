@@ -43,3 +43,9 @@ type SimpleKeyStorage interface {
 	// Delete removes a record by key.
 	Delete(rowKey []byte) (err error)
 }
+
+// RebuildableStorage is a storage which can rewrite itself without the old versions of its records.
+type RebuildableStorage interface {
+	// Rebuild rewrites the storage keeping only the current value of every record.
+	Rebuild() (err error)
+}
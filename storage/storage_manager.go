@@ -4,6 +4,7 @@ import (
 	"github.com/ITProLabDev/ethbacknode/tools"
 	"github.com/ITProLabDev/ethbacknode/tools/log"
 	"github.com/timshannon/badgerhold"
+	"io"
 	"os"
 	"path"
 	"sync"
@@ -13,8 +14,9 @@ import (
 // Manager is the central storage manager that coordinates all storage backends.
 // It provides thread-safe access to file storage, Badger KV, and BadgerHold databases.
 type Manager struct {
-	mux          sync.Mutex // Mutex for thread-safe operations
-	globalDbPath string     // Base directory for all data storage
+	mux          sync.Mutex  // Mutex for thread-safe operations
+	globalDbPath string      // Base directory for all data storage
+	databases    []io.Closer // Databases opened by the manager, closed by Close
 }
 
 // NewStorageManager creates a new storage manager with the specified data directory.
@@ -51,11 +53,12 @@ func (m *Manager) GetBinFileStorage(name, moduleDbPath, moduleDbName string) (s
 func (m *Manager) GetNewBadgerStorage(name, moduleDbPath, moduleDbName string) (s SimpleKeyStorage, err error) {
 	m.mux.Lock()
 	defer m.mux.Unlock()
-	s, err = NewBadgerStorage(name, m.globalDbPath, moduleDbPath, moduleDbName)
+	badgerStorage, err := NewBadgerStorage(name, m.globalDbPath, moduleDbPath, moduleDbName)
 	if err != nil {
 		return nil, err
 	}
-	return
+	m.databases = append(m.databases, badgerStorage)
+	return badgerStorage, nil
 }
 
 // GetNewBadgerHoldStorage creates and returns a new BadgerHold structured database.
@@ -67,9 +70,28 @@ func (m *Manager) GetNewBadgerHoldStorage(name, moduleDbPath, moduleDbName strin
 	if err != nil {
 		return nil, err
 	}
+	m.databases = append(m.databases, s)
 	return
 }
 
+// Close flushes and closes all databases opened by the manager, in reverse open order.
+// Services using the databases must be stopped before. Returns the first error.
+func (m *Manager) Close() (err error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	for i := len(m.databases) - 1; i >= 0; i-- {
+		closeErr := m.databases[i].Close()
+		if closeErr != nil {
+			log.Error("Can not close database:", closeErr)
+			if err == nil {
+				err = closeErr
+			}
+		}
+	}
+	m.databases = nil
+	return err
+}
+
 // NewBadgerHoldStorage creates a new BadgerHold storage instance.
 // BadgerHold provides ORM-like functionality on top of Badger.
 func NewBadgerHoldStorage(name, globalDbPath, dbPath, dbFile string) (s *BadgerHoldStorage, err error) {
@@ -143,6 +165,14 @@ func (s *BadgerHoldStorage) Do(processor func(db *badgerhold.Store)) {
 	processor(s.db)
 }
 
+// Close flushes and closes the database.
+func (s *BadgerHoldStorage) Close() error {
+	if s.db == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
 // Insert adds a new record with an auto-incrementing key.
 func (s *BadgerHoldStorage) Insert(value interface{}) (err error) {
 	return s.db.Insert(badgerhold.NextSequence(), value)
@@ -0,0 +1,229 @@
+package subscriptions
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ITProLabDev/ethbacknode/tools/log"
+	"github.com/timshannon/badgerhold"
+)
+
+// BalanceRecord is the last notified balance of an address asset.
+type BalanceRecord struct {
+	Key      string   `json:"key" badgerhold:"key"`
+	Address  string   `json:"address"`
+	Symbol   string   `json:"symbol"`
+	Balance  *big.Int `json:"balance"`
+	BlockNum int      `json:"blockNum"`
+}
+
+// BalanceNotification is the payload sent to subscribers for balance change events.
+// ExpectedBalance is the old balance changed by the tracked transfers of the block,
+// Reconciled reports whether it matches the balance read from the chain.
+// On nodes without the state of the block, OldBalance and ExpectedBalance are nil when
+// the balance before the block is unavailable and Latest reports NewBalance is read
+// at the latest block.
+type BalanceNotification struct {
+	ChainId         string   `json:"chainId"`
+	Address         string   `json:"address"`
+	UserId          int64    `json:"userId,omitempty"`
+	InvoiceId       int64    `json:"invoiceId,omitempty"`
+	NativeCoin      bool     `json:"nativeCoin,omitempty"`
+	Symbol          string   `json:"symbol"`
+	Token           string   `json:"token,omitempty"`
+	BlockNum        int      `json:"blockNum"`
+	OldBalance      *big.Int `json:"oldBalance"`
+	NewBalance      *big.Int `json:"newBalance"`
+	ExpectedBalance *big.Int `json:"expectedBalance"`
+	Reconciled      bool     `json:"reconciled"`
+	Latest          bool     `json:"latest,omitempty"`
+	TxIDs           []string `json:"txIds"`
+}
+
+// balanceChange collects the tracked changes of an address asset in a block.
+type balanceChange struct {
+	serviceId ServiceId
+	address   string
+	symbol    string
+	token     string
+	blockNum  int
+	userId    int64
+	invoiceId int64
+	delta     *big.Int
+	txIds     []string
+}
+
+// balanceRecordKey returns the storage key of the address asset balance.
+func balanceRecordKey(address, symbol string) string {
+	return address + ":" + symbol
+}
+
+// balanceEventProcess sends balance events for the managed addresses of the confirmed
+// transactions to the services with ReportBalanceChange enabled. The new balance is
+// read from the chain at the transaction block, the old one is the last notified balance.
+// Must be called in block order, transactions are expected to be sorted by block. The
+// balances are read by the notify loop, which keeps the block order of balance events.
+func (s *Manager) balanceEventProcess(txList []*TransferInfoRecord) {
+	chainSymbol := s.blockchainClient.GetChainSymbol()
+	var changes []*balanceChange
+	feePaid := make(map[string]bool)
+	change := func(address, symbol, token string, tx *TransferInfoRecord) *balanceChange {
+		for _, c := range changes {
+			if c.address == address && c.symbol == symbol && c.blockNum == tx.BlockNum {
+				return c
+			}
+		}
+		addressInfo, err := s.addressPool.GetAddress(address)
+		if err != nil {
+			return nil
+		}
+		serviceInfo, err := s.SubscriptionGet(ServiceId(addressInfo.ServiceId))
+		if err != nil || !serviceInfo.ReportBalanceChange {
+			return nil
+		}
+		c := &balanceChange{
+			serviceId: serviceInfo.ServiceId,
+			address:   address,
+			symbol:    symbol,
+			token:     token,
+			blockNum:  tx.BlockNum,
+			userId:    addressInfo.UserId,
+			invoiceId: addressInfo.InvoiceId,
+			delta:     new(big.Int),
+		}
+		changes = append(changes, c)
+		return c
+	}
+	for _, tx := range txList {
+		symbol, token := chainSymbol, ""
+		if tx.Token != "" {
+			symbol, token = tx.TokenSymbol, tx.Token
+		}
+		if s.addressPool.IsAddressKnown(tx.From) {
+			if c := change(tx.From, symbol, token, tx); c != nil && tx.Success && tx.Amount != nil {
+				c.delta.Sub(c.delta, tx.Amount)
+				c.txIds = append(c.txIds, tx.TxID)
+			}
+			// the fee is paid once per transaction, in the native coin
+			txHash, _, _ := strings.Cut(tx.TxID, ":")
+			if c := change(tx.From, chainSymbol, "", tx); c != nil && tx.Fee != nil && !feePaid[txHash] {
+				feePaid[txHash] = true
+				c.delta.Sub(c.delta, tx.Fee)
+				if token != "" {
+					c.txIds = append(c.txIds, tx.TxID)
+				}
+			}
+		}
+		if s.addressPool.IsAddressKnown(tx.To) {
+			if c := change(tx.To, symbol, token, tx); c != nil && tx.Success && tx.Amount != nil {
+				c.delta.Add(c.delta, tx.Amount)
+				c.txIds = append(c.txIds, tx.TxID)
+			}
+		}
+	}
+	if len(changes) == 0 {
+		return
+	}
+	s.goNotify(func() {
+		for _, c := range changes {
+			err := s.balanceChangeProcess(c)
+			if err != nil {
+				log.Error("Can not process balance change of", c.address, c.symbol, "at block", c.blockNum, ":", err)
+			}
+		}
+	})
+}
+
+// balanceChangeProcess reads the balance of the address asset at the block, saves it
+// and notifies the service if it differs from the last notified one. The historical
+// state is not kept by non-archive nodes, the balance is then read at the latest block
+// and an unknown balance before the block is reported as unavailable.
+// Runs in the notify loop.
+func (s *Manager) balanceChangeProcess(c *balanceChange) (err error) {
+	record := new(BalanceRecord)
+	key := balanceRecordKey(c.address, c.symbol)
+	s.transactionPool.Do(func(db *badgerhold.Store) {
+		err = db.Get(key, record)
+	})
+	if errors.Is(err, badgerhold.ErrNotFound) {
+		record = &BalanceRecord{Key: key, Address: c.address, Symbol: c.symbol}
+		record.Balance, err = s.balanceAt(c, int64(c.blockNum-1))
+		if err != nil {
+			log.Warning("Balance of", c.address, c.symbol, "before block", c.blockNum, "unavailable:", err)
+			record.Balance, err = nil, nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if record.BlockNum >= c.blockNum {
+		// already notified, e.g. the block is reprocessed
+		return nil
+	}
+	latest := false
+	newBalance, err := s.balanceAt(c, int64(c.blockNum))
+	if err != nil {
+		if s.config.Debug {
+			log.Warning("Balance of", c.address, c.symbol, "at block", c.blockNum, "unavailable, reading the latest one:", err)
+		}
+		latest = true
+		newBalance, err = s.balanceLatest(c)
+		if err != nil {
+			return err
+		}
+	}
+	oldBalance := record.Balance
+	record.Balance = newBalance
+	record.BlockNum = c.blockNum
+	s.transactionPool.Do(func(db *badgerhold.Store) {
+		err = db.Upsert(record.Key, record)
+	})
+	if err != nil {
+		return err
+	}
+	if oldBalance != nil && newBalance.Cmp(oldBalance) == 0 {
+		return nil
+	}
+	var expectedBalance *big.Int
+	if oldBalance != nil {
+		expectedBalance = new(big.Int).Add(oldBalance, c.delta)
+	}
+	balanceNotification := &BalanceNotification{
+		ChainId:         s.blockchainClient.GetChainId(),
+		Address:         c.address,
+		UserId:          c.userId,
+		InvoiceId:       c.invoiceId,
+		NativeCoin:      c.token == "",
+		Symbol:          c.symbol,
+		Token:           c.token,
+		BlockNum:        c.blockNum,
+		OldBalance:      oldBalance,
+		NewBalance:      newBalance,
+		ExpectedBalance: expectedBalance,
+		Reconciled:      expectedBalance != nil && expectedBalance.Cmp(newBalance) == 0,
+		Latest:          latest,
+		TxIDs:           c.txIds,
+	}
+	if !balanceNotification.Reconciled && expectedBalance != nil && s.config.Debug {
+		log.Warning("Balance of", c.address, c.symbol, "at block", c.blockNum, "differs from tracked transfers:", newBalance, "expected", expectedBalance)
+	}
+	s.NotifySubscriber(c.serviceId, "balanceEvent", balanceNotification)
+	return nil
+}
+
+// balanceAt returns the balance of the changed address asset at the block.
+func (s *Manager) balanceAt(c *balanceChange, blockNum int64) (*big.Int, error) {
+	if c.token == "" {
+		return s.blockchainClient.BalanceAt(c.address, blockNum)
+	}
+	return s.blockchainClient.TokensBalanceAt(c.address, c.symbol, blockNum)
+}
+
+// balanceLatest returns the balance of the changed address asset at the latest block.
+func (s *Manager) balanceLatest(c *balanceChange) (*big.Int, error) {
+	if c.token == "" {
+		return s.blockchainClient.BalanceOf(c.address)
+	}
+	return s.blockchainClient.TokensBalanceOf(c.address, c.symbol)
+}
@@ -0,0 +1,118 @@
+package subscriptions
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ITProLabDev/ethbacknode/clients/ethclient"
+	"github.com/ITProLabDev/ethbacknode/types"
+)
+
+func TestBalanceEvent(t *testing.T) {
+	chain := newTestChain()
+	chain.balanceGate = make(chan struct{})
+	s, endpoint := newTestManager(t, chain)
+	s.subscribersMux.Lock()
+	s.subscribers[1].ReportBalanceChange = true
+	s.subscribersMux.Unlock()
+	deposit := testAddress(t, s, 0x11, 1)
+	sender, _ := ethclient.GetAddressCodec().EncodeBytesToAddress(make([]byte, 20))
+	chain.balances[deposit+"@99"] = big.NewInt(1000)
+	chain.balances[deposit+"@100"] = big.NewInt(1500)
+
+	depositId := testTransferId(1)
+	s.TransactionEvent(&types.TransferInfo{
+		TxID:       depositId,
+		BlockNum:   100,
+		Success:    true,
+		Transfer:   true,
+		NativeCoin: true,
+		From:       sender,
+		To:         deposit,
+		Amount:     big.NewInt(500),
+		Fee:        big.NewInt(21000),
+	})
+	s.BlockEvent(101, "0x01")
+	// the event loop is not blocked by the balance reads
+	nextId := testTransferId(2)
+	go s.TransactionEvent(&types.TransferInfo{
+		TxID:       nextId,
+		BlockNum:   101,
+		Success:    true,
+		Transfer:   true,
+		NativeCoin: true,
+		From:       sender,
+		To:         deposit,
+		Amount:     big.NewInt(1),
+		Fee:        big.NewInt(21000),
+	})
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		if _, err := s.getTransactionById(nextId); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			close(chain.balanceGate)
+			t.Fatal("event loop blocked by the balance reads")
+		}
+	}
+	close(chain.balanceGate)
+	stopTestManager(t, s)
+
+	notifications := endpoint.received("balanceEvent")
+	if len(notifications) != 1 {
+		t.Fatalf("balance events: got %d, want 1", len(notifications))
+	}
+	var n BalanceNotification
+	if err := json.Unmarshal(notifications[0], &n); err != nil {
+		t.Fatalf("balance event: %v", err)
+	}
+	if n.Address != deposit || n.BlockNum != 100 || n.OldBalance.Int64() != 1000 || n.NewBalance.Int64() != 1500 ||
+		n.ExpectedBalance.Int64() != 1500 || !n.Reconciled || len(n.TxIDs) != 1 || n.TxIDs[0] != depositId {
+		t.Fatalf("balance event: %s", notifications[0])
+	}
+}
+
+// On a node without the historical state the balance event is still sent, with the
+// latest balance and the balance before the block reported as unavailable.
+func TestBalanceEvent_HistoryUnavailable(t *testing.T) {
+	chain := newTestChain()
+	chain.historyErr = errors.New("missing trie node")
+	s, endpoint := newTestManager(t, chain)
+	s.subscribersMux.Lock()
+	s.subscribers[1].ReportBalanceChange = true
+	s.subscribersMux.Unlock()
+	deposit := testAddress(t, s, 0x11, 1)
+	sender, _ := ethclient.GetAddressCodec().EncodeBytesToAddress(make([]byte, 20))
+	chain.balances[deposit] = big.NewInt(1500)
+
+	depositId := testTransferId(1)
+	s.TransactionEvent(&types.TransferInfo{
+		TxID:       depositId,
+		BlockNum:   100,
+		Success:    true,
+		Transfer:   true,
+		NativeCoin: true,
+		From:       sender,
+		To:         deposit,
+		Amount:     big.NewInt(500),
+		Fee:        big.NewInt(21000),
+	})
+	s.BlockEvent(101, "0x01")
+	stopTestManager(t, s)
+
+	notifications := endpoint.received("balanceEvent")
+	if len(notifications) != 1 {
+		t.Fatalf("balance events: got %d, want 1", len(notifications))
+	}
+	var n BalanceNotification
+	if err := json.Unmarshal(notifications[0], &n); err != nil {
+		t.Fatalf("balance event: %v", err)
+	}
+	if n.BlockNum != 100 || n.OldBalance != nil || n.ExpectedBalance != nil || n.Reconciled ||
+		n.NewBalance.Int64() != 1500 || !n.Latest {
+		t.Fatalf("balance event: %s", notifications[0])
+	}
+}
@@ -9,8 +9,12 @@ import (
 
 // Config holds the subscription manager configuration.
 type Config struct {
-	storage storage.BinStorage
-	Debug   bool `json:"debug"`
+	storage             storage.BinStorage
+	Debug               bool `json:"debug"`
+	OutboxMaxAttempts   int  `json:"outboxMaxAttempts"`
+	OutboxRetryDelay    int  `json:"outboxRetryDelay"`
+	OutboxRetryMaxDelay int  `json:"outboxRetryMaxDelay"`
+	PendingTimeout      int  `json:"pendingTimeout"`
 }
 
 // _configDefaultStorage returns the default file-based storage for configuration.
@@ -53,5 +57,22 @@ func (c *Config) coldStart() (err error) {
 	if c.storage == nil {
 		return ErrConfigStorageEmpty
 	}
+	c.setDefaults()
 	return c.Save()
 }
+
+// setDefaults sets default values of the options missing in the configuration.
+func (c *Config) setDefaults() {
+	if c.OutboxMaxAttempts <= 0 {
+		c.OutboxMaxAttempts = defaultOutboxMaxAttempts
+	}
+	if c.OutboxRetryDelay <= 0 {
+		c.OutboxRetryDelay = defaultOutboxRetryDelay
+	}
+	if c.OutboxRetryMaxDelay <= 0 {
+		c.OutboxRetryMaxDelay = defaultOutboxRetryMaxDelay
+	}
+	if c.PendingTimeout <= 0 {
+		c.PendingTimeout = defaultPendingTimeout
+	}
+}
@@ -10,4 +10,16 @@ var (
 	ErrUnknownTransaction = errors.New("unknown transaction")
 	// ErrUnknownServiceId is returned when a service ID is not recognized.
 	ErrUnknownServiceId = errors.New("unknown serviceId")
+	// ErrOutboxDisabled is returned when the outbox storage is not configured.
+	ErrOutboxDisabled = errors.New("notification outbox disabled")
+	// ErrGasStationNotSet is returned when a token sweep needs gas and the service has no gas station.
+	ErrGasStationNotSet = errors.New("gas station address not set")
+	// ErrSweepTransactionFailed is returned when a token sweep transaction is reverted.
+	ErrSweepTransactionFailed = errors.New("sweep transaction failed")
+	// ErrInvalidSchedule is returned when a sweep schedule expression can not be parsed.
+	ErrInvalidSchedule = errors.New("invalid schedule")
+	// ErrInvalidSweepDistribution is returned when the sweep distribution mode is unknown.
+	ErrInvalidSweepDistribution = errors.New("invalid sweep distribution")
+	// ErrInvalidSweepPolicy is returned when a sweep policy value is out of range.
+	ErrInvalidSweepPolicy = errors.New("invalid sweep policy")
 )
@@ -5,16 +5,19 @@ import "github.com/ITProLabDev/ethbacknode/tools/log"
 // BlockEvent handles a new block event from the watchdog.
 // Queues the event for processing in the event loop.
 func (s *Manager) BlockEvent(blockNum int64, blockId string) {
-	s.eventPipe <- func() {
+	s.pushEvent(func() {
 		blockNumStatic := blockNum
 		blockIdStatic := blockId
 		s.blockEvent(blockNumStatic, blockIdStatic)
-	}
+	})
 }
 // blockEvent processes a block event.
 // Notifies services, checks transaction confirmations, and updates statuses.
 func (s *Manager) blockEvent(blockNum int64, blockId string) {
-	go s.blockNotifyServices(blockNum, blockId)
+	s.lastSeenBlock = int(blockNum)
+	s.goNotify(func() { s.blockNotifyServices(blockNum, blockId) })
+	s.goProcess(s.sweepProcess)
+	s.goProcess(s.pendingProcess)
 	minConfirmations := s.blockchainClient.MinConfirmations() - 1
 	confirmedBlock := int(blockNum) - minConfirmations
 	if confirmedBlock < 1 {
@@ -36,7 +39,7 @@ func (s *Manager) blockEvent(blockNum int64, blockId string) {
 			txNotification := new(TransferNotification).fill(tx)
 			txNotification.Confirmations = int(blockNum) - tx.BlockNum + 1
 			if !tx.Ignore {
-				go s.transactionEventPostProcess(txNotification)
+				s.goNotify(func() { s.transactionEventPostProcess(txNotification) })
 			}
 		}
 
@@ -50,6 +53,7 @@ func (s *Manager) blockEvent(blockNum int64, blockId string) {
 		log.Info("Found", len(txList), "confirmed transactions")
 	}
 	// Send confirmations event
+	var confirmedList []*TransferInfoRecord
 	for _, tx := range txList {
 		tx.Confirmed = true
 		err = s.saveTransaction(tx)
@@ -57,12 +61,16 @@ func (s *Manager) blockEvent(blockNum int64, blockId string) {
 			log.Error("Can not save transaction info:", err)
 			continue
 		}
+		confirmedList = append(confirmedList, tx)
 		txNotification := new(TransferNotification).fill(tx)
 		txNotification.Confirmations = int(blockNum) - tx.BlockNum
 		if !tx.Ignore {
-			go s.transactionEventPostProcess(txNotification)
+			s.goNotify(func() { s.transactionEventPostProcess(txNotification) })
 		}
 	}
+	if len(confirmedList) != 0 {
+		s.balanceEventProcess(confirmedList)
+	}
 }
 
 // blockNotifyServices notifies all subscribers that have ReportNewBlock enabled.
@@ -74,7 +82,7 @@ func (s *Manager) blockNotifyServices(blockNum int64, blockId string) {
 	}
 	s.subscriptionViewAll(func(service *Subscription) {
 		if service.ReportNewBlock {
-			go service.sendNotification("blockEvent", blockNotification, s.config.Debug)
+			s.notify(service, "blockEvent", blockNotification)
 		}
 	})
 }
@@ -1,10 +1,79 @@
 package subscriptions
 
+import "github.com/ITProLabDev/ethbacknode/tools/log"
+
+// notifyPipeSize is the number of notifications queued before the event loop waits for the notify loop.
+const notifyPipeSize = 256
+
 // eventLoop processes events from the event pipe sequentially.
-// Runs as a goroutine, executing event handlers one at a time.
+// Runs as a goroutine, executing event handlers one at a time. Exits when the
+// pipe is closed by Stop and all queued events are processed.
 func (s *Manager) eventLoop() {
-	for {
-		eventProcessor := <-s.eventPipe
+	defer close(s.loopDone)
+	for eventProcessor := range s.eventPipe {
 		eventProcessor()
 	}
 }
+
+// pushEvent queues the event for the event loop. The event is dropped if the manager is stopped.
+func (s *Manager) pushEvent(eventProcessor func()) {
+	s.eventMux.RLock()
+	defer s.eventMux.RUnlock()
+	if s.eventsClosed {
+		log.Warning("Subscriptions manager stopped, event dropped")
+		return
+	}
+	s.eventPipe <- eventProcessor
+}
+
+// eventsClose closes the event pipe once the events being pushed are queued.
+func (s *Manager) eventsClose() {
+	s.eventMux.Lock()
+	defer s.eventMux.Unlock()
+	if !s.eventsClosed {
+		s.eventsClosed = true
+		close(s.eventPipe)
+	}
+}
+
+// goNotify queues the notification for the notify loop. Notifications are sent, or
+// saved to the outbox, in the order they are queued, so the notifications of a
+// service are delivered in the order of the events.
+// The notification is dropped if the manager is stopped.
+func (s *Manager) goNotify(notify func()) {
+	s.notifyPipeMux.RLock()
+	defer s.notifyPipeMux.RUnlock()
+	if s.notifyClosed {
+		log.Warning("Subscriptions manager stopped, notification dropped")
+		return
+	}
+	s.notifyPipe <- notify
+}
+
+// notifyClose closes the notify pipe once the notifications being queued are queued.
+func (s *Manager) notifyClose() {
+	s.notifyPipeMux.Lock()
+	defer s.notifyPipeMux.Unlock()
+	if !s.notifyClosed {
+		s.notifyClosed = true
+		close(s.notifyPipe)
+	}
+}
+
+// notifyLoop runs the queued notifications one by one.
+// Exits when the pipe is closed by Stop and all queued notifications are run.
+func (s *Manager) notifyLoop() {
+	defer close(s.notifyDone)
+	for notify := range s.notifyPipe {
+		notify()
+	}
+}
+
+// goProcess runs the background processing in a goroutine tracked by Stop.
+func (s *Manager) goProcess(process func()) {
+	s.processWg.Add(1)
+	go func() {
+		defer s.processWg.Done()
+		process()
+	}()
+}
@@ -0,0 +1,77 @@
+package subscriptions
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ITProLabDev/ethbacknode/tools/log"
+	"github.com/ITProLabDev/ethbacknode/types"
+	"github.com/timshannon/badgerhold"
+)
+
+// TransactionReplacedEvent handles the replacement of an outgoing transaction sent
+// by the node with the same nonce and a higher fee, on request or by the automatic
+// speed up. Queues the event for processing in the event loop.
+func (s *Manager) TransactionReplacedEvent(txId, newTxId string, cancel bool) {
+	s.pushEvent(func() {
+		s.transactionReplacedEventProcess(txId, newTxId, cancel)
+	})
+}
+
+// transactionReplacedEventProcess marks the replaced transaction, tracks the replacement
+// of an internal transaction as ignored too and moves the sweep tasks to the replacement.
+func (s *Manager) transactionReplacedEventProcess(txId, newTxId string, cancel bool) {
+	tx, err := s.getTransactionById(txId)
+	if err != nil && !errors.Is(err, ErrUnknownTransaction) {
+		log.Error("Can not load transaction info:", err)
+	} else if err == nil && tx.InPool {
+		if tx.Ignore {
+			s.trackInternalTransaction(newTxId, tx.From, tx.To, tx.Amount)
+		}
+		tx.Status = types.TransferStatusReplaced
+		tx.ReplacedBy = newTxId
+		err = s.saveTransaction(tx)
+		if err != nil {
+			log.Error("Can not save transaction info:", err)
+		} else if !tx.Ignore {
+			txNotification := new(TransferNotification).fill(tx)
+			s.goNotify(func() { s.transactionEventPostProcess(txNotification) })
+		}
+	}
+	s.sweepTasksReplace(txId, newTxId, cancel)
+}
+
+// sweepTasksReplace moves the sweep tasks waiting for the replaced transaction to the
+// replacement. A cancelled token sweep is started again.
+func (s *Manager) sweepTasksReplace(txId, newTxId string, cancel bool) {
+	// the sweep process saves the tasks it loaded, wait until it is finished
+	s.sweepMux.Lock()
+	defer s.sweepMux.Unlock()
+	var tasks []*SweepTask
+	var err error
+	s.transactionPool.Do(func(db *badgerhold.Store) {
+		err = db.Find(&tasks, badgerhold.Where("Status").Ne(SweepStatusFailed))
+	})
+	if err != nil {
+		log.Error("Can not load sweep tasks:", err)
+		return
+	}
+	for _, task := range tasks {
+		switch {
+		case strings.EqualFold(task.GasTxID, txId):
+			task.GasTxID = newTxId
+		case strings.EqualFold(task.SweepTxID, txId) && cancel:
+			task.SweepTxID = ""
+			task.setStatus(SweepStatusPending)
+		case strings.EqualFold(task.SweepTxID, txId):
+			task.SweepTxID = newTxId
+		default:
+			continue
+		}
+		log.Info("Sweep of", task.Token, "from", task.Address, "follows transaction", txId, "replaced with", newTxId)
+		err = s.sweepTaskSave(task)
+		if err != nil {
+			log.Error("Can not save sweep task:", err)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package subscriptions
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ITProLabDev/ethbacknode/types"
+)
+
+func TestTransactionReplacedEvent(t *testing.T) {
+	chain := newTestChain()
+	s, endpoint := newTestManager(t, chain)
+	gasStation := testAddress(t, s, 0x33, 1)
+	deposit := testAddress(t, s, 0x11, 1)
+	master := testAddress(t, s, 0x22, 1)
+
+	gasTxId, sweepTxId := testTransferId(1), testTransferId(2)
+	s.trackInternalTransaction(gasTxId, gasStation, deposit, big.NewInt(500))
+	s.trackInternalTransaction(sweepTxId, deposit, master, nil)
+	funding := &SweepTask{Id: sweepTaskId(deposit, "USDT"), ServiceId: 1, Address: deposit, Token: "USDT",
+		Master: master, Status: SweepStatusFunding, GasTxID: gasTxId}
+	sweeping := &SweepTask{Id: sweepTaskId(deposit, "USDC"), ServiceId: 1, Address: deposit, Token: "USDC",
+		Master: master, Status: SweepStatusSweeping, SweepTxID: sweepTxId}
+	for _, task := range []*SweepTask{funding, sweeping} {
+		if err := s.sweepTaskSave(task); err != nil {
+			t.Fatalf("sweepTaskSave: %v", err)
+		}
+	}
+
+	newGasTxId, cancelTxId := testTransferId(3), testTransferId(4)
+	s.TransactionReplacedEvent(gasTxId, newGasTxId, false)
+	s.TransactionReplacedEvent(sweepTxId, cancelTxId, true)
+	stopTestManager(t, s)
+
+	replaced, err := s.getTransactionById(gasTxId)
+	if err != nil || replaced.Status != types.TransferStatusReplaced || replaced.ReplacedBy != newGasTxId {
+		t.Fatalf("replaced transaction: %v, %+v", err, replaced)
+	}
+	replacement, err := s.getTransactionById(newGasTxId)
+	if err != nil || !replacement.Ignore || replacement.From != gasStation || replacement.To != deposit ||
+		replacement.Amount.Int64() != 500 {
+		t.Fatalf("replacement: %v, %+v", err, replacement)
+	}
+	tasks, err := s.SweepTasks(1)
+	if err != nil || len(tasks) != 2 {
+		t.Fatalf("SweepTasks: %v, %d tasks", err, len(tasks))
+	}
+	for _, task := range tasks {
+		switch task.Token {
+		case "USDT":
+			if task.Status != SweepStatusFunding || task.GasTxID != newGasTxId {
+				t.Errorf("funding task: %+v", task)
+			}
+		case "USDC":
+			// the cancelled sweep is sent again
+			if task.Status != SweepStatusPending || task.SweepTxID != "" {
+				t.Errorf("cancelled sweep task: %+v", task)
+			}
+		}
+	}
+	if got := len(endpoint.received("transactionEvent")); got != 0 {
+		t.Fatalf("notifications: got %d, want 0", got)
+	}
+}
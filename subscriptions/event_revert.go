@@ -0,0 +1,79 @@
+package subscriptions
+
+import "github.com/ITProLabDev/ethbacknode/tools/log"
+
+// BlockRevertedEvent handles a block reverted by a chain reorganization.
+// Queues the event for processing in the event loop.
+func (s *Manager) BlockRevertedEvent(blockNum int64, blockId string) {
+	s.pushEvent(func() {
+		blockNumStatic := blockNum
+		blockIdStatic := blockId
+		s.blockRevertedEvent(blockNumStatic, blockIdStatic)
+	})
+}
+
+// blockRevertedEvent drops transactions recorded from the reverted block
+// and notifies subscribers. Transactions re-included in the canonical chain
+// are reported again as new ones by the watchdog.
+func (s *Manager) blockRevertedEvent(blockNum int64, blockId string) {
+	s.goNotify(func() { s.blockRevertedNotifyServices(blockNum, blockId) })
+	txList, err := s.SearchTransactionsInBlock(int(blockNum))
+	if err != nil {
+		log.Error("Can not load transactions:", err)
+		return
+	}
+	if len(txList) != 0 {
+		log.Warning("Found", len(txList), "transactions in reverted block", blockNum)
+	}
+	for _, tx := range txList {
+		err = s.deleteTransaction(tx.TxID)
+		if err != nil {
+			log.Error("Can not delete transaction info:", err)
+			continue
+		}
+		if tx.Ignore {
+			continue
+		}
+		txNotification := new(TransferNotification).fill(tx)
+		txNotification.Confirmations = 0
+		s.goNotify(func() { s.transactionRevertedNotifyServices(txNotification) })
+	}
+}
+
+// transactionRevertedNotifyServices notifies subscribers that previously received
+// a transaction event that the transaction is no longer part of the chain.
+func (s *Manager) transactionRevertedNotifyServices(transactionInfo *TransferNotification) {
+	s.notifyMux.Lock()
+	defer s.notifyMux.Unlock()
+	transactionInfo.ChainId = s.blockchainClient.GetChainId()
+	if s.addressPool.IsAddressKnown(transactionInfo.From) {
+		addressInfo, _ := s.addressPool.GetAddress(transactionInfo.From)
+		serviceInfo, err := s.SubscriptionGet(ServiceId(addressInfo.ServiceId))
+		if err == nil && serviceInfo.ReportOutgoingTx {
+			s.NotifySubscriber(ServiceId(addressInfo.ServiceId), "transactionReverted", transactionInfo)
+		}
+	}
+	if s.addressPool.IsAddressKnown(transactionInfo.To) {
+		addressInfo, _ := s.addressPool.GetAddress(transactionInfo.To)
+		serviceInfo, err := s.SubscriptionGet(ServiceId(addressInfo.ServiceId))
+		if err == nil && serviceInfo.ReportIncomingTx {
+			transactionInfo.UserId = addressInfo.UserId
+			transactionInfo.InvoiceId = addressInfo.InvoiceId
+			s.NotifySubscriber(ServiceId(addressInfo.ServiceId), "transactionReverted", transactionInfo)
+		}
+	}
+}
+
+// blockRevertedNotifyServices notifies all subscribers that have ReportNewBlock enabled.
+func (s *Manager) blockRevertedNotifyServices(blockNum int64, blockId string) {
+	blockNotification := &BlockNotification{
+		ChainId:  s.blockchainClient.GetChainId(),
+		BlockNum: blockNum,
+		BlockId:  blockId,
+	}
+	s.subscriptionViewAll(func(service *Subscription) {
+		if service.ReportNewBlock {
+			s.notify(service, "blockReverted", blockNotification)
+		}
+	})
+}
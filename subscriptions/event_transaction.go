@@ -4,15 +4,16 @@ import (
 	"errors"
 	"github.com/ITProLabDev/ethbacknode/tools/log"
 	"github.com/ITProLabDev/ethbacknode/types"
+	"time"
 )
 
 // TransactionEvent handles a new transaction event from the watchdog.
 // Queues the event for processing in the event loop.
 func (s *Manager) TransactionEvent(transactionInfo *types.TransferInfo) {
-	s.eventPipe <- func() {
+	s.pushEvent(func() {
 		transactionInfoStatic := transactionInfo
 		s.transactionEventProcess(transactionInfoStatic)
-	}
+	})
 }
 
 // transactionEventProcess processes a transaction event.
@@ -33,6 +34,11 @@ func (s *Manager) transactionEventProcess(transactionInfo *types.TransferInfo) {
 	if errors.Is(err, ErrUnknownTransaction) {
 		//seems like new transaction, save it and send event
 		txInfo = new(TransferInfoRecord).fillFromTransferInfo(transactionInfo)
+		s.transactionLinkHashRecord(txInfo)
+		if txInfo.InPool {
+			txInfo.SeenAt = time.Now().Unix()
+			txInfo.SeenBlock = int64(s.lastSeenBlock)
+		}
 		err = s.saveTransaction(txInfo)
 		if err != nil {
 			log.Error("Can not save transaction info:", err)
@@ -41,6 +47,16 @@ func (s *Manager) transactionEventProcess(transactionInfo *types.TransferInfo) {
 	} else if err != nil {
 		log.Error("error getting transaction by id", err)
 		return
+	} else if txInfo.isEqual(transactionInfo) && txInfo.Status != "" {
+		// the dropped transaction is back in the mempool
+		txInfo.Status = ""
+		txInfo.ReplacedBy = ""
+		txInfo.SeenAt = time.Now().Unix()
+		txInfo.SeenBlock = int64(s.lastSeenBlock)
+		err = s.saveTransaction(txInfo)
+		if err != nil {
+			log.Error("Can not save transaction info:", err)
+		}
 	} else if txInfo.isEqual(transactionInfo) {
 		if s.config.Debug {
 			log.Debug("Transaction already known, skip")
@@ -49,7 +65,13 @@ func (s *Manager) transactionEventProcess(transactionInfo *types.TransferInfo) {
 	} else if txInfo.InPool && !transactionInfo.InPool && !txInfo.Ignore {
 		txInfo.BlockNum = transactionInfo.BlockNum
 		txInfo.Timestamp = transactionInfo.Timestamp
+		txInfo.Success = transactionInfo.Success
+		txInfo.Fee = transactionInfo.Fee
+		txInfo.ChainSpecificData = transactionInfo.ChainSpecificData
 		txInfo.InPool = false
+		// a dropped transaction may still be mined if it was rebroadcast
+		txInfo.Status = ""
+		txInfo.ReplacedBy = ""
 		err = s.saveTransaction(txInfo)
 		if err != nil {
 			log.Error("Can not save transaction info:", err)
@@ -67,17 +89,43 @@ func (s *Manager) transactionEventProcess(transactionInfo *types.TransferInfo) {
 	if txInfo.Ignore {
 		return
 	}
-	go s.transactionEventPostProcess(txNotification)
+	s.goNotify(func() { s.transactionEventPostProcess(txNotification) })
+}
+
+// transactionLinkHashRecord links a new transfer detected from a log or by call
+// tracing with the record saved under the hash of its transaction. Transfers of
+// a transaction sent by the node itself are ignored as well, and the mempool
+// record decoded from the call data is replaced by the transfer once mined.
+func (s *Manager) transactionLinkHashRecord(txInfo *TransferInfoRecord) {
+	txHash := types.TransferTxHash(txInfo.TxID)
+	if txHash == txInfo.TxID {
+		return
+	}
+	hashRecord, err := s.getTransactionById(txHash)
+	if err != nil {
+		return
+	}
+	if hashRecord.Ignore {
+		txInfo.Ignore = true
+		return
+	}
+	if hashRecord.InPool && !txInfo.InPool && hashRecord.SmartContract && !hashRecord.NativeCoin {
+		err = s.deleteTransaction(txHash)
+		if err != nil {
+			log.Error("Can not delete mempool transaction info:", err)
+		}
+	}
 }
 
 // transactionEventPostProcess sends notifications to affected subscribers.
-// Checks if sender/recipient addresses are managed and notifies accordingly.
-// Triggers fund gathering if enabled and transaction is confirmed.
+// Checks if sender/recipient addresses are managed and notifies accordingly if the
+// service filters accept the transaction. Filtered transactions are still recorded.
 func (s *Manager) transactionEventPostProcess(transactionInfo *TransferNotification) {
 	s.notifyMux.Lock()
 	defer s.notifyMux.Unlock()
 	from := transactionInfo.From
 	to := transactionInfo.To
+	symbol, token := s.notificationAsset(transactionInfo)
 	if s.addressPool.IsAddressKnown(from) {
 		addressInfo, _ := s.addressPool.GetAddress(from)
 		serviceInfo, err := s.SubscriptionGet(ServiceId(addressInfo.ServiceId))
@@ -85,9 +133,9 @@ func (s *Manager) transactionEventPostProcess(transactionInfo *TransferNotificat
 			return
 		}
 		//TODO move to channels
-		if serviceInfo.ReportOutgoingTx {
+		if serviceInfo.ReportOutgoingTx && serviceInfo.acceptsTransfer(transactionInfo, symbol, token) {
 			transactionInfo.ChainId = s.blockchainClient.GetChainId()
-			go s.NotifySubscriber(ServiceId(addressInfo.ServiceId), "transactionEvent", transactionInfo)
+			s.NotifySubscriber(ServiceId(addressInfo.ServiceId), "transactionEvent", transactionInfo)
 		}
 	}
 	if s.addressPool.IsAddressKnown(to) {
@@ -97,14 +145,11 @@ func (s *Manager) transactionEventPostProcess(transactionInfo *TransferNotificat
 			return
 		}
 		//TODO move to channels
-		if serviceInfo.ReportIncomingTx {
+		if serviceInfo.ReportIncomingTx && serviceInfo.acceptsTransfer(transactionInfo, symbol, token) {
 			transactionInfo.ChainId = s.blockchainClient.GetChainId()
 			transactionInfo.UserId = addressInfo.UserId
 			transactionInfo.InvoiceId = addressInfo.InvoiceId
-			go s.NotifySubscriber(ServiceId(addressInfo.ServiceId), "transactionEvent", transactionInfo)
-		}
-		if transactionInfo.Confirmed && transactionInfo.Success {
-			s.gatherNativeCoinToMaster(addressInfo, transactionInfo)
+			s.NotifySubscriber(ServiceId(addressInfo.ServiceId), "transactionEvent", transactionInfo)
 		}
 	}
 }
@@ -0,0 +1,125 @@
+package subscriptions
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ITProLabDev/ethbacknode/clients/ethclient"
+	"github.com/ITProLabDev/ethbacknode/types"
+)
+
+func testTokenTransfer(txId, from, to string, inPool bool) *types.TransferInfo {
+	return &types.TransferInfo{
+		TxID:          txId,
+		BlockNum:      100,
+		Success:       true,
+		Transfer:      true,
+		SmartContract: true,
+		From:          from,
+		To:            to,
+		Amount:        big.NewInt(1000),
+		Token:         "Tether USD",
+		TokenSymbol:   "USDT",
+		Fee:           big.NewInt(21000),
+		InPool:        inPool,
+		Decimals:      6,
+	}
+}
+
+func TestTransactionEvent_SweepNotNotified(t *testing.T) {
+	chain := newTestChain()
+	s, endpoint := newTestManager(t, chain)
+	deposit := testAddress(t, s, 0x11, 1)
+	master := testAddress(t, s, 0x22, 1)
+	sender, _ := ethclient.GetAddressCodec().EncodeBytesToAddress(make([]byte, 20))
+
+	// the sweep is tracked by the transaction hash, mined transfers have log IDs
+	sweepHash := testTransferId(1)
+	chain.transfers[sweepHash] = testTokenTransfer(sweepHash, deposit, master, true)
+	s.trackInternalTransaction(sweepHash, deposit, master, nil)
+	depositId := ethclient.TransferLogId(testTransferId(2), 0)
+	s.TransactionEvent(testTokenTransfer(depositId, sender, deposit, false))
+	s.TransactionEvent(testTokenTransfer(ethclient.TransferLogId(sweepHash, 3), deposit, master, false))
+	stopTestManager(t, s)
+
+	notifications := endpoint.received("transactionEvent")
+	if len(notifications) != 1 {
+		t.Fatalf("notifications: got %d, want 1", len(notifications))
+	}
+	var n TransferNotification
+	if err := json.Unmarshal(notifications[0], &n); err != nil || n.TxID != depositId {
+		t.Fatalf("notification: %v, %s", err, notifications[0])
+	}
+	sweep, err := s.getTransactionById(ethclient.TransferLogId(sweepHash, 3))
+	if err != nil || !sweep.Ignore {
+		t.Fatalf("sweep transfer: %v, %+v", err, sweep)
+	}
+}
+
+func TestTransactionEvent_MempoolRecordReplaced(t *testing.T) {
+	chain := newTestChain()
+	s, endpoint := newTestManager(t, chain)
+	deposit := testAddress(t, s, 0x11, 1)
+	sender, _ := ethclient.GetAddressCodec().EncodeBytesToAddress(make([]byte, 20))
+
+	txHash := testTransferId(3)
+	s.TransactionEvent(testTokenTransfer(txHash, sender, deposit, true))
+	s.TransactionEvent(testTokenTransfer(ethclient.TransferLogId(txHash, 1), sender, deposit, false))
+	stopTestManager(t, s)
+
+	if got := len(endpoint.received("transactionEvent")); got != 2 {
+		t.Fatalf("notifications: got %d, want mempool and mined", got)
+	}
+	if _, err := s.getTransactionById(txHash); err != ErrUnknownTransaction {
+		t.Fatalf("mempool record: got %v, want ErrUnknownTransaction", err)
+	}
+}
+
+func TestTransactionEvent_InternalPlaceholder(t *testing.T) {
+	chain := newTestChain()
+	s, endpoint := newTestManager(t, chain)
+	gasStation := testAddress(t, s, 0x33, 1)
+	deposit := testAddress(t, s, 0x11, 1)
+
+	// the top-up is not known by the node yet, a placeholder is saved
+	txHash := testTransferId(4)
+	s.trackInternalTransaction(txHash, gasStation, deposit, big.NewInt(500))
+	placeholder, err := s.getTransactionById(txHash)
+	if err != nil || !placeholder.Ignore || placeholder.From != gasStation || placeholder.To != deposit ||
+		placeholder.Amount.Int64() != 500 || placeholder.Fee == nil {
+		t.Fatalf("placeholder: %v, %+v", err, placeholder)
+	}
+	for _, inPool := range []bool{true, false} {
+		s.TransactionEvent(&types.TransferInfo{
+			TxID:       txHash,
+			BlockNum:   100,
+			Success:    true,
+			Transfer:   true,
+			NativeCoin: true,
+			From:       gasStation,
+			To:         deposit,
+			Amount:     big.NewInt(500),
+			Fee:        big.NewInt(21000),
+			InPool:     inPool,
+		})
+	}
+	stopTestManager(t, s)
+	if got := len(endpoint.received("transactionEvent")); got != 0 {
+		t.Fatalf("notifications: got %d, want 0", got)
+	}
+}
+
+func TestTransferInfoRecord_IsEqualNilAmounts(t *testing.T) {
+	record := &TransferInfoRecord{TxID: "0x01", InPool: true}
+	if !record.isEqual(&types.TransferInfo{TxID: "0x01", InPool: true}) {
+		t.Error("nil amounts not equal")
+	}
+	if record.isEqual(&types.TransferInfo{TxID: "0x01", InPool: true, Amount: big.NewInt(0), Fee: big.NewInt(0)}) {
+		t.Error("nil amount equals a zero one")
+	}
+	record.Amount, record.Fee = big.NewInt(1), big.NewInt(2)
+	if record.isEqual(&types.TransferInfo{TxID: "0x01", InPool: true}) {
+		t.Error("amount equals a nil one")
+	}
+}
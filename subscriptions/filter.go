@@ -0,0 +1,40 @@
+package subscriptions
+
+// notificationAsset returns the symbol of the asset transferred by the notification.
+func (s *Manager) notificationAsset(n *TransferNotification) (symbol string, token bool) {
+	if n.Token != "" {
+		return n.TokenSymbol, true
+	}
+	if n.Symbol != "" {
+		return n.Symbol, false
+	}
+	return s.blockchainClient.GetChainSymbol(), false
+}
+
+// acceptsTransfer reports whether the service wants the transaction notification.
+// Checks the asset, the minimum amount of the asset and the confirmation milestones,
+// the direction is checked by the caller. A nil ReportTokens reports all tokens and a
+// nil ReportMainCoin reports the native coin.
+func (s *Subscription) acceptsTransfer(n *TransferNotification, symbol string, token bool) bool {
+	if token {
+		if s.ReportTokens != nil && !s.ReportTokens[symbol] {
+			return false
+		}
+	} else if !s.reportsMainCoin() {
+		return false
+	}
+	if minAmount, found := s.MinAmounts[symbol]; found && minAmount != nil {
+		if n.Amount == nil || n.Amount.Cmp(minAmount) < 0 {
+			return false
+		}
+	}
+	if len(s.ConfirmationMilestones) != 0 && !n.Confirmed {
+		for _, milestone := range s.ConfirmationMilestones {
+			if n.Confirmations == milestone {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,127 @@
+package subscriptions
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ITProLabDev/ethbacknode/clients/ethclient"
+	"github.com/ITProLabDev/ethbacknode/storage"
+	"github.com/ITProLabDev/ethbacknode/types"
+)
+
+func TestSubscription_AcceptsTransfer(t *testing.T) {
+	usdt := map[string]bool{"USDT": true}
+	reportMainCoin, skipMainCoin := true, false
+	minAmounts := map[string]*big.Int{"ETH": big.NewInt(100), "USDT": big.NewInt(10)}
+	tests := []struct {
+		name         string
+		subscription Subscription
+		notification TransferNotification
+		symbol       string
+		token        bool
+		want         bool
+	}{
+		{name: "main coin", subscription: Subscription{ReportMainCoin: &reportMainCoin}, notification: TransferNotification{Amount: big.NewInt(1)}, symbol: "ETH", want: true},
+		{name: "main coin not reported", subscription: Subscription{ReportMainCoin: &skipMainCoin}, notification: TransferNotification{Amount: big.NewInt(1)}, symbol: "ETH"},
+		{name: "main coin by default", subscription: Subscription{}, notification: TransferNotification{Amount: big.NewInt(1)}, symbol: "ETH", want: true},
+		{name: "all tokens", subscription: Subscription{}, notification: TransferNotification{Amount: big.NewInt(1)}, symbol: "DAI", token: true, want: true},
+		{name: "allowed token", subscription: Subscription{ReportTokens: usdt}, notification: TransferNotification{Amount: big.NewInt(1)}, symbol: "USDT", token: true, want: true},
+		{name: "token not allowed", subscription: Subscription{ReportTokens: usdt}, notification: TransferNotification{Amount: big.NewInt(1)}, symbol: "DAI", token: true},
+		{name: "empty allow-list", subscription: Subscription{ReportTokens: map[string]bool{}}, notification: TransferNotification{Amount: big.NewInt(1)}, symbol: "USDT", token: true},
+		{name: "below min amount", subscription: Subscription{MinAmounts: minAmounts}, notification: TransferNotification{Amount: big.NewInt(99)}, symbol: "ETH"},
+		{name: "min amount", subscription: Subscription{MinAmounts: minAmounts}, notification: TransferNotification{Amount: big.NewInt(100)}, symbol: "ETH", want: true},
+		{name: "token min amount", subscription: Subscription{MinAmounts: minAmounts}, notification: TransferNotification{Amount: big.NewInt(9)}, symbol: "USDT", token: true},
+		{name: "no min amount of asset", subscription: Subscription{MinAmounts: minAmounts}, notification: TransferNotification{Amount: big.NewInt(1)}, symbol: "DAI", token: true, want: true},
+		{name: "nil amount with min amount", subscription: Subscription{MinAmounts: minAmounts}, notification: TransferNotification{}, symbol: "ETH"},
+		{name: "nil amount", subscription: Subscription{}, notification: TransferNotification{}, symbol: "ETH", want: true},
+		{name: "milestone", subscription: Subscription{ConfirmationMilestones: []int{1, 6}}, notification: TransferNotification{Confirmations: 6}, symbol: "ETH", want: true},
+		{name: "not a milestone", subscription: Subscription{ConfirmationMilestones: []int{1, 6}}, notification: TransferNotification{Confirmations: 3}, symbol: "ETH"},
+		{name: "confirmed", subscription: Subscription{ConfirmationMilestones: []int{1, 6}}, notification: TransferNotification{Confirmations: 12, Confirmed: true}, symbol: "ETH", want: true},
+	}
+	for _, tt := range tests {
+		if got := tt.subscription.acceptsTransfer(&tt.notification, tt.symbol, tt.token); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSubscription_OldJsonReportsMainCoin(t *testing.T) {
+	// subscribers.json saved before reportMainCoin was added
+	const oldJson = `{"1": {"serviceName": "shop", "serviceId": 1, "apiToken": "", "apiKey": "", "eventUrl": "http://localhost/events", "reportNewBlock": false, "reportIncomingTx": true, "reportOutgoingTx": true, "reportTokens": null, "balanceChange": false, "gatherToMaster": false, "masterList": null}}`
+	subscribersStorage, _ := storage.NewBinFileStorage("Subscribers", t.TempDir(), "subscriptions", "subscribers.json")
+	if err := subscribersStorage.Save([]byte(oldJson)); err != nil {
+		t.Fatalf("subscribers: %v", err)
+	}
+	s := &Manager{subscribersStorage: subscribersStorage}
+	if err := s.subscriptionsLoad(); err != nil {
+		t.Fatalf("subscriptionsLoad: %v", err)
+	}
+	notification := &TransferNotification{Amount: big.NewInt(1)}
+	if !s.subscribers[1].acceptsTransfer(notification, "ETH", false) {
+		t.Fatal("main coin transfer of old subscription not reported")
+	}
+	// the explicit setting is kept after saving
+	reportMainCoin := false
+	s.subscribers[1].ReportMainCoin = &reportMainCoin
+	if err := s.subscriptionsSave(); err != nil {
+		t.Fatalf("subscriptionsSave: %v", err)
+	}
+	if err := s.subscriptionsLoad(); err != nil {
+		t.Fatalf("subscriptionsLoad: %v", err)
+	}
+	if s.subscribers[1].acceptsTransfer(notification, "ETH", false) {
+		t.Fatal("main coin transfer reported after it was disabled")
+	}
+}
+
+func TestTransactionEvent_Direction(t *testing.T) {
+	tests := []struct {
+		name         string
+		incoming     bool
+		outgoing     bool
+		wantIncoming bool
+		wantOutgoing bool
+	}{
+		{name: "both", incoming: true, outgoing: true, wantIncoming: true, wantOutgoing: true},
+		{name: "incoming", incoming: true, wantIncoming: true},
+		{name: "outgoing", outgoing: true, wantOutgoing: true},
+		{name: "none"},
+	}
+	for _, tt := range tests {
+		s, endpoint := newTestManager(t, newTestChain())
+		s.subscribersMux.Lock()
+		s.subscribers[1].ReportIncomingTx = tt.incoming
+		s.subscribers[1].ReportOutgoingTx = tt.outgoing
+		s.subscribersMux.Unlock()
+		deposit := testAddress(t, s, 0x11, 1)
+		external, _ := ethclient.GetAddressCodec().EncodeBytesToAddress(make([]byte, 20))
+		incomingId := testTransferId(1)
+		outgoingId := testTransferId(2)
+		for _, tx := range []*types.TransferInfo{
+			{TxID: incomingId, From: external, To: deposit},
+			{TxID: outgoingId, From: deposit, To: external},
+		} {
+			tx.BlockNum = 100
+			tx.Success = true
+			tx.Transfer = true
+			tx.NativeCoin = true
+			tx.Amount = big.NewInt(1)
+			tx.Fee = big.NewInt(21000)
+			s.TransactionEvent(tx)
+		}
+		stopTestManager(t, s)
+		var gotIncoming, gotOutgoing bool
+		for _, params := range endpoint.received("transactionEvent") {
+			var n TransferNotification
+			if err := json.Unmarshal(params, &n); err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			gotIncoming = gotIncoming || n.TxID == incomingId
+			gotOutgoing = gotOutgoing || n.TxID == outgoingId
+		}
+		if gotIncoming != tt.wantIncoming || gotOutgoing != tt.wantOutgoing {
+			t.Errorf("%s: incoming %v, outgoing %v", tt.name, gotIncoming, gotOutgoing)
+		}
+	}
+}
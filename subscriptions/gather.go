@@ -1,48 +1,293 @@
 package subscriptions
 
 import (
+	"math/big"
+	"strings"
+	"time"
+
 	"github.com/ITProLabDev/ethbacknode/address"
 	"github.com/ITProLabDev/ethbacknode/tools/log"
+	"github.com/timshannon/badgerhold"
 )
 
-// gatherNativeCoinToMaster transfers received funds to the master address.
-// Only executes if the service has GatherToMaster enabled and has master addresses configured.
-func (s *Manager) gatherNativeCoinToMaster(address *address.Address, txInfo *TransferNotification) {
-	serviceInfo, err := s.SubscriptionGet(ServiceId(address.ServiceId))
+// sweepScheduler gathers the funds of the services with GatherToMaster enabled
+// according to their sweep policy schedules until the manager is stopped.
+func (s *Manager) sweepScheduler() {
+	defer close(s.sweepDone)
+	ticker := time.NewTicker(sweepSchedulerInterval)
+	defer ticker.Stop()
+	var lastRun time.Time
+	for {
+		select {
+		case now := <-ticker.C:
+			now = now.Truncate(time.Minute)
+			if !now.After(lastRun) {
+				continue
+			}
+			lastRun = now
+			s.sweepScheduleRun(now)
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// sweepScheduleRun gathers the funds of the services scheduled at the minute.
+func (s *Manager) sweepScheduleRun(now time.Time) {
+	var services []*Subscription
+	s.subscriptionViewAll(func(service *Subscription) {
+		if service.GatherToMaster && len(service.MasterList) != 0 {
+			services = append(services, service)
+		}
+	})
+	for _, serviceInfo := range services {
+		policy := serviceInfo.SweepPolicy
+		if policy == nil {
+			policy = &SweepPolicy{}
+		}
+		scheduleExpr := policy.Schedule
+		if scheduleExpr == "" {
+			scheduleExpr = defaultSweepSchedule
+		}
+		sweepSchedule, err := parseSchedule(scheduleExpr)
+		if err != nil {
+			log.Error("Service", serviceInfo.ServiceId, "invalid sweep schedule:", err)
+			continue
+		}
+		if sweepSchedule.matches(now) {
+			s.gatherToMaster(serviceInfo, policy)
+		}
+	}
+}
+
+// sweepGasPriceAllowed reports whether the current gas price is within the MaxGasPrice
+// of the sweep policy.
+func (s *Manager) sweepGasPriceAllowed(serviceId ServiceId, policy *SweepPolicy) (allowed bool, err error) {
+	if policy == nil || policy.MaxGasPrice == nil {
+		return true, nil
+	}
+	gasPrice, err := s.blockchainClient.GasPrice()
+	if err != nil {
+		return false, err
+	}
+	if gasPrice.Cmp(policy.MaxGasPrice) > 0 {
+		if s.config.Debug {
+			log.Debug("Service", serviceId, "sweep skipped, gas price", gasPrice, "above", policy.MaxGasPrice)
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+// gatherToMaster transfers the funds of the service addresses to the master addresses.
+// Native coins are transferred at once, tokens are swept by sweep tasks. Addresses with
+// a token sweep in progress keep their native coins to pay the token transfer fee.
+func (s *Manager) gatherToMaster(serviceInfo *Subscription, policy *SweepPolicy) {
+	allowed, err := s.sweepGasPriceAllowed(serviceInfo.ServiceId, policy)
 	if err != nil {
+		log.Error("Can not get gas price:", err)
+		return
+	}
+	if !allowed {
 		return
 	}
-	if !serviceInfo.GatherToMaster {
-		//service don't need to gather
+	excluded := map[string]bool{serviceInfo.GasStation: true}
+	for _, master := range serviceInfo.MasterList {
+		excluded[master] = true
+	}
+	var addresses []*address.Address
+	s.addressPool.WalkAllAddresses(func(a *address.Address) {
+		if a.ServiceId == int(serviceInfo.ServiceId) && a.Subscribed && !a.WatchOnly && !a.Master && a.CanSign() && !excluded[a.Address] {
+			addresses = append(addresses, a)
+		}
+	})
+	if len(addresses) == 0 {
+		return
+	}
+	tasks, err := s.SweepTasks(serviceInfo.ServiceId)
+	if err != nil {
+		log.Error("Can not load sweep tasks:", err)
 		return
 	}
-	log.Warning("Service ", address.ServiceId, " need to gather from", address.Address, " to master")
-	if len(serviceInfo.MasterList) == 0 {
-		log.Warning("Service ", address.ServiceId, " don't have master list")
+	sweeping := make(map[string]bool)
+	for _, task := range tasks {
+		if task.Status != SweepStatusFailed {
+			sweeping[task.Address] = true
+		}
+	}
+	tokens := s.blockchainClient.TokensList()
+	chainSymbol := s.blockchainClient.GetChainSymbol()
+	cursor, err := s.sweepCandidates(serviceInfo.ServiceId, chainSymbol)
+	if err != nil {
+		log.Error("Can not load sweep candidates:", err)
 		return
 	}
-	masterAddress := serviceInfo.MasterList[0]
-	var txId string
-	if txInfo.NativeCoin {
-		txId, err = s.blockchainClient.TransferAllByPrivateKey(address.PrivateKey, address.Address, masterAddress)
+	// assets and addresses with funds left, checked again by the next run
+	next := &sweepCursor{candidates: make(map[string]bool), addresses: make(map[string]bool)}
+	left := next.candidates
+	for _, depositAddress := range addresses {
+		if s.isStopping() {
+			return
+		}
+		if sweeping[depositAddress.Address] {
+			// a token sweep is in progress, the address is checked once it is finished
+			cursor.carry(depositAddress.Address, next)
+			continue
+		}
+		for _, token := range tokens {
+			assetId := sweepTaskId(depositAddress.Address, token.Symbol)
+			if !cursor.isCandidate(depositAddress.Address, assetId) {
+				continue
+			}
+			balance, err := s.blockchainClient.TokensBalanceOf(depositAddress.Address, token.Symbol)
+			if err != nil {
+				log.Error("Can not get", token.Symbol, "balance of", depositAddress.Address, ":", err)
+				left[assetId] = true
+				continue
+			}
+			if policy.belowMinBalance(token.Symbol, balance) {
+				continue
+			}
+			if s.gatherTokenToMaster(serviceInfo, depositAddress.Address, token.Symbol, s.sweepMaster(serviceInfo, policy)) {
+				sweeping[depositAddress.Address] = true
+			} else {
+				left[assetId] = true
+			}
+		}
+		assetId := sweepTaskId(depositAddress.Address, chainSymbol)
+		if !cursor.isCandidate(depositAddress.Address, assetId) {
+			continue
+		}
+		if sweeping[depositAddress.Address] {
+			left[assetId] = true
+			continue
+		}
+		balance, err := s.blockchainClient.BalanceOf(depositAddress.Address)
+		if err != nil {
+			log.Error("Can not get balance of", depositAddress.Address, ":", err)
+			left[assetId] = true
+			continue
+		}
+		if policy.belowMinBalance(chainSymbol, balance) {
+			continue
+		}
+		masterAddress := s.sweepMaster(serviceInfo, policy)
+		fee, err := s.blockchainClient.TransferGetEstimatedFee(depositAddress.Address, masterAddress, balance)
+		if err != nil {
+			log.Error("Can not estimate fee of", depositAddress.Address, ":", err)
+			left[assetId] = true
+			continue
+		}
+		if policy.feeTooHigh(fee, balance) {
+			left[assetId] = true
+			continue
+		}
+		log.Warning("Service ", serviceInfo.ServiceId, " need to gather from", depositAddress.Address, " to master", masterAddress)
+		signer, err := s.addressPool.AddressSigner(depositAddress)
+		if err != nil {
+			log.Error("Can not get signer of", depositAddress.Address, ":", err)
+			left[assetId] = true
+			continue
+		}
+		txId, err := s.blockchainClient.TransferAllBySigner(signer, masterAddress)
 		if err != nil {
 			if s.globalConfig.Flag("debug") {
-				log.Warning("Service ", address.ServiceId, "Can not transfer all to master:", err, ", skip")
+				log.Warning("Service ", serviceInfo.ServiceId, "Can not transfer all to master:", err, ", skip")
 			}
-			return
+			left[assetId] = true
+			continue
 		}
+		// the amount is the balance left after the fee, known once the transaction is loaded
+		s.trackInternalTransaction(txId, depositAddress.Address, masterAddress, nil)
 	}
-	if txId != "" {
-		sendTxInfo, err := s.blockchainClient.TransferInfoByHash(txId)
-		if err != nil {
-			log.Error("Service ", address.ServiceId, "Can not get transfer info:", err)
-			return
+	next.blockNum = cursor.blockNum
+	s.sweepCursors[serviceInfo.ServiceId] = next
+}
+
+// sweepCursor selects the assets checked by a scheduled sweep of a service: the
+// assets of the addresses which received a deposit after the block of the last run,
+// and the assets and addresses left by the last run. The first run after the start
+// checks all addresses.
+type sweepCursor struct {
+	full       bool
+	blockNum   int
+	candidates map[string]bool // by sweepTaskId of the address and the asset symbol
+	addresses  map[string]bool // addresses with all assets checked
+}
+
+// isCandidate reports whether the asset of the address is checked by the run.
+func (c *sweepCursor) isCandidate(address, assetId string) bool {
+	return c.full || c.addresses[address] || c.candidates[assetId]
+}
+
+// carry keeps the candidate assets of the skipped address for the next run.
+func (c *sweepCursor) carry(address string, next *sweepCursor) {
+	if c.full || c.addresses[address] {
+		next.addresses[address] = true
+		return
+	}
+	for assetId := range c.candidates {
+		if strings.HasPrefix(assetId, address+":") {
+			next.candidates[assetId] = true
 		}
-		txRecord := new(TransferInfoRecord).fillFromTransferInfo(sendTxInfo)
-		txRecord.Ignore = true
-		err = s.saveTransaction(txRecord)
-		if err != nil {
-			log.Error("Service ", address.ServiceId, "Can not save transfer info:", err)
+	}
+}
+
+// sweepCandidates returns the cursor of the sweep of the service, the candidates left
+// by the last run extended by the deposits received since.
+func (s *Manager) sweepCandidates(serviceId ServiceId, chainSymbol string) (cursor *sweepCursor, err error) {
+	cursor, found := s.sweepCursors[serviceId]
+	if !found {
+		cursor = &sweepCursor{full: true, candidates: make(map[string]bool), addresses: make(map[string]bool)}
+	}
+	var txList []*TransferInfoRecord
+	s.transactionPool.Do(func(db *badgerhold.Store) {
+		err = db.Find(&txList, badgerhold.Where("BlockNum").Gt(cursor.blockNum).And("InPool").Eq(false))
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, tx := range txList {
+		if tx.BlockNum > cursor.blockNum {
+			cursor.blockNum = tx.BlockNum
+		}
+		if tx.Ignore || !tx.Success {
+			continue
 		}
+		symbol := chainSymbol
+		if tx.Token != "" {
+			symbol = tx.TokenSymbol
+		}
+		cursor.candidates[sweepTaskId(tx.To, symbol)] = true
+	}
+	return cursor, nil
+}
+
+// trackInternalTransaction saves a transaction sent by the node itself as ignored,
+// so it is not reported to the services. If the transaction can not be loaded yet,
+// a placeholder record of the transfer known at send time is saved, a nil amount
+// is saved as zero.
+func (s *Manager) trackInternalTransaction(txId, from, to string, amount *big.Int) {
+	if amount == nil {
+		amount = new(big.Int)
+	}
+	txRecord := &TransferInfoRecord{
+		TxID:   txId,
+		From:   from,
+		To:     to,
+		Amount: amount,
+		Fee:    new(big.Int),
+		InPool: true,
+	}
+	sendTxInfo, err := s.blockchainClient.TransferInfoByHash(txId)
+	if err != nil {
+		log.Error("Can not get transfer info:", txId, err)
+	} else {
+		txRecord.fillFromTransferInfo(sendTxInfo)
+	}
+	txRecord.Ignore = true
+	err = s.saveTransaction(txRecord)
+	if err != nil {
+		log.Error("Can not save transfer info:", txId, err)
 	}
 }
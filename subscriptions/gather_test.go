@@ -0,0 +1,120 @@
+package subscriptions
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ITProLabDev/ethbacknode/address"
+	"github.com/ITProLabDev/ethbacknode/clients/ethclient"
+	"github.com/ITProLabDev/ethbacknode/types"
+)
+
+// testSigningAddress adds an address of the service with a private key to the address pool.
+func testSigningAddress(t *testing.T, s *Manager, n byte, serviceId ServiceId) string {
+	t.Helper()
+	addressString, _ := ethclient.GetAddressCodec().EncodeBytesToAddress(bytes.Repeat([]byte{n}, 20))
+	_, err := s.addressPool.AddAddressFill(addressString, func(a *address.Address) {
+		a.ServiceId = int(serviceId)
+		a.Subscribed = true
+		a.PrivateKey = bytes.Repeat([]byte{n}, 32)
+	})
+	if err != nil {
+		t.Fatalf("AddAddressFill: %v", err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); !s.addressPool.IsAddressKnown(addressString); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("address %s not indexed", addressString)
+		}
+	}
+	return addressString
+}
+
+func TestGatherToMaster_Selection(t *testing.T) {
+	chain := newTestChain()
+	chain.tokens = []*types.TokenInfo{{Symbol: "USDT"}}
+	s, _ := newTestManager(t, chain)
+	depositA := testSigningAddress(t, s, 0x11, 1)
+	depositB := testSigningAddress(t, s, 0x12, 1)
+	master := testAddress(t, s, 0x22, 1)
+	sender, _ := ethclient.GetAddressCodec().EncodeBytesToAddress(make([]byte, 20))
+	s.subscribersMux.Lock()
+	serviceInfo := s.subscribers[1]
+	serviceInfo.GatherToMaster = true
+	serviceInfo.MasterList = []string{master}
+	s.subscribersMux.Unlock()
+
+	deposit := func(txId string, blockNum int, to, token string) {
+		t.Helper()
+		tx := &types.TransferInfo{
+			TxID:       txId,
+			BlockNum:   blockNum,
+			Success:    true,
+			Transfer:   true,
+			NativeCoin: token == "",
+			From:       sender,
+			To:         to,
+			Amount:     big.NewInt(1),
+			Fee:        big.NewInt(21000),
+		}
+		if token != "" {
+			tx.SmartContract = true
+			tx.Token = token
+			tx.TokenSymbol = token
+		}
+		s.TransactionEvent(tx)
+		for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+			if _, err := s.getTransactionById(txId); err == nil {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("deposit %s not saved", txId)
+			}
+		}
+	}
+	run := func(name string, wantCalls int) {
+		t.Helper()
+		chain.mux.Lock()
+		chain.balanceCalls = 0
+		chain.mux.Unlock()
+		s.gatherToMaster(serviceInfo, &SweepPolicy{})
+		chain.mux.Lock()
+		defer chain.mux.Unlock()
+		if chain.balanceCalls != wantCalls {
+			t.Fatalf("%s: got %d balance calls, want %d", name, chain.balanceCalls, wantCalls)
+		}
+	}
+
+	// the native balance of A can not be swept, the fee estimation fails
+	chain.balances[depositA] = big.NewInt(1000)
+	run("first run checks all assets", 4)
+	run("left native balance is checked again", 1)
+	chain.mux.Lock()
+	delete(chain.balances, depositA)
+	chain.mux.Unlock()
+	run("empty balance is not left", 1)
+	run("no deposits", 0)
+
+	deposit(testTransferId(1), 100, depositB, "USDT")
+	run("token deposit", 1)
+
+	task := &SweepTask{
+		Id:        sweepTaskId(depositB, "USDT"),
+		ServiceId: 1,
+		Address:   depositB,
+		Token:     "USDT",
+		Master:    master,
+		Status:    SweepStatusPending,
+	}
+	if err := s.sweepTaskSave(task); err != nil {
+		t.Fatalf("sweepTaskSave: %v", err)
+	}
+	deposit(testTransferId(2), 101, depositB, "")
+	run("address with an active sweep task", 0)
+	if err := s.sweepTaskDelete(task); err != nil {
+		t.Fatalf("sweepTaskDelete: %v", err)
+	}
+	run("deposit kept while the sweep task was active", 1)
+	run("no deposits after the sweep", 0)
+}
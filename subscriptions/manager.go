@@ -3,9 +3,11 @@
 package subscriptions
 
 import (
+	"context"
 	"github.com/ITProLabDev/ethbacknode/address"
 	"github.com/ITProLabDev/ethbacknode/storage"
 	"github.com/ITProLabDev/ethbacknode/types"
+	"github.com/dgraph-io/badger"
 	"sync"
 )
 
@@ -24,6 +26,15 @@ func WithTransactionStorage(storage *storage.BadgerHoldStorage) Option {
 	}
 }
 
+// WithOutboxStorage sets the storage backend for undelivered notifications.
+// Without it, notifications are sent once and lost if the delivery fails.
+func WithOutboxStorage(storage *storage.BadgerHoldStorage) Option {
+	return func(w *Manager) error {
+		w.outbox = storage
+		return nil
+	}
+}
+
 // WithBlockchainClient sets the blockchain client for chain queries and transfers.
 func WithBlockchainClient(client types.ChainClient) Option {
 	return func(w *Manager) error {
@@ -56,6 +67,15 @@ func WithConfigStorage(storage storage.BinStorage) Option {
 	}
 }
 
+// WithKeyDecoder sets the decoder of the service ApiKey used to sign notifications,
+// the security manager DecodeKey, so requests and notifications share the key.
+func WithKeyDecoder(decoder KeyDecoder) Option {
+	return func(s *Manager) error {
+		s.keyDecoder = decoder
+		return nil
+	}
+}
+
 // NewManager creates a new subscription manager with the specified options.
 // Loads existing subscriptions and starts the event processing loop.
 func NewManager(options ...Option) (*Manager, error) {
@@ -64,6 +84,16 @@ func NewManager(options ...Option) (*Manager, error) {
 			storage: _configDefaultStorage(),
 		},
 		eventPipe: make(chan func()),
+		notifyPipe: make(chan func(), notifyPipeSize),
+		notifyDone: make(chan struct{}),
+		quit:       make(chan struct{}),
+		loopDone:   make(chan struct{}),
+		outboxWake: make(chan struct{}, 1),
+		outboxDone: make(chan struct{}),
+
+		sweepDone:      make(chan struct{}),
+		sweepSelectors: make(map[ServiceId]*masterSelector),
+		sweepCursors:   make(map[ServiceId]*sweepCursor),
 	}
 	for _, opt := range options {
 		err := opt(s)
@@ -75,11 +105,22 @@ func NewManager(options ...Option) (*Manager, error) {
 	if err != nil {
 		return nil, err
 	}
+	s.config.setDefaults()
 	err = s.subscriptionsLoad()
 	if err != nil {
 		return nil, err
 	}
+	if s.outbox != nil {
+		err = s.outboxInit()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		close(s.outboxDone)
+	}
 	go s.eventLoop()
+	go s.notifyLoop()
+	go s.sweepScheduler()
 	return s, nil
 }
 
@@ -97,11 +138,77 @@ type Manager struct {
 	transactionPool *storage.BadgerHoldStorage
 
 	blockchainClient types.ChainClient
+	keyDecoder       KeyDecoder
 
 	subscribersMux     sync.RWMutex
 	subscribersStorage storage.BinStorage
 	subscribers        map[ServiceId]*Subscription
 
-	eventPipe chan func()
-	notifyMux sync.RWMutex
+	eventPipe    chan func()
+	eventMux     sync.RWMutex
+	eventsClosed bool
+	notifyMux    sync.RWMutex
+
+	notifyPipe    chan func()
+	notifyPipeMux sync.RWMutex
+	notifyClosed  bool
+	notifyDone    chan struct{}
+
+	quit      chan struct{}
+	stopOnce  sync.Once
+	loopDone  chan struct{}
+	notifyWg  sync.WaitGroup
+	processWg sync.WaitGroup
+
+	outbox     *storage.BadgerHoldStorage
+	outboxSeq  *badger.Sequence
+	outboxWake chan struct{}
+	outboxDone chan struct{}
+
+	sweepMux       sync.Mutex
+	sweepDone      chan struct{}
+	sweepSelectors map[ServiceId]*masterSelector
+	sweepCursors   map[ServiceId]*sweepCursor
+
+	pendingMux sync.Mutex
+}
+
+// Stop stops the event loop once the queued events are processed and waits until
+// the queued notifications are delivered or saved to the outbox, and the outbox
+// delivery, the scheduled sweep and the background processing in progress are finished.
+// Events and notifications received after Stop are dropped.
+// Returns ctx.Err() if the manager does not stop before the context is done.
+func (s *Manager) Stop(ctx context.Context) (err error) {
+	s.stopOnce.Do(func() {
+		close(s.quit)
+	})
+	done := make(chan struct{})
+	go func() {
+		s.eventsClose()
+		<-s.loopDone
+		<-s.sweepDone
+		s.processWg.Wait()
+		s.notifyClose()
+		<-s.notifyDone
+		s.notifyWg.Wait()
+		<-s.outboxDone
+		s.outboxRelease()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// isStopping reports whether Stop was called.
+func (s *Manager) isStopping() bool {
+	select {
+	case <-s.quit:
+		return true
+	default:
+		return false
+	}
 }
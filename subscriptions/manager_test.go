@@ -0,0 +1,245 @@
+package subscriptions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ITProLabDev/ethbacknode/address"
+	"github.com/ITProLabDev/ethbacknode/clients/ethclient"
+	"github.com/ITProLabDev/ethbacknode/storage"
+	"github.com/ITProLabDev/ethbacknode/types"
+)
+
+// addressConfig disables the address generation of the test address pool.
+const addressConfig = `{"enableAddressGenerate": false, "bip44CoinType": "Ether"}`
+
+// testChain is a chain client serving the balances and transfers set by the test.
+// Methods not overridden panic on the nil embedded interface.
+type testChain struct {
+	types.ChainClient
+	mux          sync.Mutex
+	transfers    map[string]*types.TransferInfo
+	balances     map[string]*big.Int
+	balanceCalls int
+	tokens       []*types.TokenInfo
+	// balanceGate blocks the historical balance reads until closed, if set
+	balanceGate chan struct{}
+	// historyErr fails the historical balance reads, if set
+	historyErr error
+	gasPrice    *big.Int
+	// sent records the transfers sent, "<to> <asset>"
+	sent []string
+}
+
+func newTestChain() *testChain {
+	return &testChain{
+		transfers: make(map[string]*types.TransferInfo),
+		balances:  make(map[string]*big.Int),
+	}
+}
+
+func (c *testChain) GetChainId() string {
+	return "ethereum"
+}
+
+func (c *testChain) GetChainSymbol() string {
+	return "ETH"
+}
+
+func (c *testChain) TokensList() []*types.TokenInfo {
+	return c.tokens
+}
+
+func (c *testChain) TransferInfoByHash(txHash string) (*types.TransferInfo, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	tx, found := c.transfers[txHash]
+	if !found {
+		return nil, ErrUnknownTransaction
+	}
+	return tx, nil
+}
+
+func (c *testChain) BalanceOf(address string) (*big.Int, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.balanceCalls++
+	if balance, found := c.balances[address]; found {
+		return balance, nil
+	}
+	return new(big.Int), nil
+}
+
+func (c *testChain) TokensBalanceOf(address, token string) (*big.Int, error) {
+	return c.BalanceOf(address + ":" + token)
+}
+
+// TransferGetEstimatedFee fails, so no transfer is sent.
+func (c *testChain) TransferGetEstimatedFee(from, to string, amount *big.Int) (*big.Int, error) {
+	return nil, ErrUnknownTransaction
+}
+
+func (c *testChain) MinConfirmations() int {
+	return 1
+}
+
+// BalanceAt returns the balance set for "<address>@<blockNum>".
+func (c *testChain) BalanceAt(address string, blockNum int64) (*big.Int, error) {
+	if c.balanceGate != nil {
+		<-c.balanceGate
+	}
+	if c.historyErr != nil {
+		return nil, c.historyErr
+	}
+	return c.BalanceOf(address + "@" + strconv.FormatInt(blockNum, 10))
+}
+
+func (c *testChain) TokensBalanceAt(address, token string, blockNum int64) (*big.Int, error) {
+	return c.BalanceAt(address+":"+token, blockNum)
+}
+
+// testEndpoint is a service endpoint recording the received notifications.
+// The first fail requests are answered with an error and not recorded.
+type testEndpoint struct {
+	mux           sync.Mutex
+	fail          int
+	deliveryIds   []string
+	notifications []testNotification
+}
+
+// testNotification is a notification received by the test endpoint.
+type testNotification struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+func (e *testEndpoint) received(method string) (params []json.RawMessage) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	for _, n := range e.notifications {
+		if n.Method == method {
+			params = append(params, n.Params)
+		}
+	}
+	return params
+}
+
+// newTestManager starts a manager with a service (ID 1) subscribed to all events
+// at the test endpoint. The manager is stopped when the test ends.
+func newTestManager(t *testing.T, chain *testChain, options ...Option) (*Manager, *testEndpoint) {
+	t.Helper()
+	endpoint := new(testEndpoint)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n testNotification
+		if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		endpoint.mux.Lock()
+		defer endpoint.mux.Unlock()
+		endpoint.deliveryIds = append(endpoint.deliveryIds, r.Header.Get(HeaderDeliveryId))
+		if endpoint.fail > 0 {
+			endpoint.fail--
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		endpoint.notifications = append(endpoint.notifications, n)
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	addressStorage, err := storage.NewBadgerStorage("Address", dir, "address", "addresses.db")
+	if err != nil {
+		t.Fatalf("address storage: %v", err)
+	}
+	t.Cleanup(func() { addressStorage.Close() })
+	addressConfigStorage, _ := storage.NewBinFileStorage("Config", dir, "address", "config.json")
+	if err = addressConfigStorage.Save([]byte(addressConfig)); err != nil {
+		t.Fatalf("address config: %v", err)
+	}
+	addressPool, err := address.NewManager(
+		address.WithAddressStorage(addressStorage),
+		address.WithConfigStorage(addressConfigStorage),
+		address.WithAddressCodec(ethclient.GetAddressCodec()),
+	)
+	if err != nil {
+		t.Fatalf("address.NewManager: %v", err)
+	}
+	transactionStorage, err := storage.NewBadgerHoldStorage("Transactions", dir, "subscriptions", "transactions.db")
+	if err != nil {
+		t.Fatalf("transaction storage: %v", err)
+	}
+	t.Cleanup(func() { transactionStorage.Close() })
+	subscribers := map[ServiceId]*Subscription{
+		1: {
+			ServiceId:        1,
+			EndpointUrl:      server.URL,
+			ReportIncomingTx: true,
+			ReportOutgoingTx: true,
+		},
+	}
+	subscribersJson, _ := json.Marshal(subscribers)
+	subscribersStorage, _ := storage.NewBinFileStorage("Subscribers", dir, "subscriptions", "subscribers.json")
+	if err = subscribersStorage.Save(subscribersJson); err != nil {
+		t.Fatalf("subscribers: %v", err)
+	}
+	configStorage, _ := storage.NewBinFileStorage("Config", dir, "subscriptions", "config.json")
+	options = append([]Option{
+		WithAddressManager(addressPool),
+		WithTransactionStorage(transactionStorage),
+		WithBlockchainClient(chain),
+		WithSubscribersStorage(subscribersStorage),
+		WithConfigStorage(configStorage),
+	}, options...)
+	s, err := NewManager(options...)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	t.Cleanup(func() { stopTestManager(t, s) })
+	return s, endpoint
+}
+
+// stopTestManager stops the manager, so all started notifications are delivered.
+func stopTestManager(t *testing.T, s *Manager) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.Stop(ctx); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+}
+
+// testAddress adds a watch-only address of the service to the address pool and
+// waits until it is known.
+func testAddress(t *testing.T, s *Manager, n byte, serviceId ServiceId) string {
+	t.Helper()
+	addressString, _ := ethclient.GetAddressCodec().EncodeBytesToAddress(bytes.Repeat([]byte{n}, 20))
+	_, err := s.addressPool.AddAddressFill(addressString, func(a *address.Address) {
+		a.ServiceId = int(serviceId)
+		a.Subscribed = true
+		a.WatchOnly = true
+	})
+	if err != nil {
+		t.Fatalf("AddAddressFill: %v", err)
+	}
+	// the lookup index is rebuilt in background
+	for deadline := time.Now().Add(5 * time.Second); !s.addressPool.IsAddressKnown(addressString); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("address %s not indexed", addressString)
+		}
+	}
+	return addressString
+}
+
+// testTransferId returns a transaction hash for the test transfer n.
+func testTransferId(n byte) string {
+	return "0x" + string(bytes.Repeat([]byte{'a' + n%6}, 64))
+}
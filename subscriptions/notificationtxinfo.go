@@ -1,14 +1,11 @@
 package subscriptions
 
 import (
-	"crypto/sha256"
-	"fmt"
 	"math/big"
-	"strings"
 )
 
 // TransferNotification is the payload sent to subscribers for transaction events.
-// Includes confirmation count, user/invoice IDs, and optional signature for verification.
+// Includes confirmation count and user/invoice IDs.
 type TransferNotification struct {
 	ChainId       string   `json:"chainId"`
 	TxID          string   `json:"tx_id"`
@@ -30,32 +27,10 @@ type TransferNotification struct {
 	Confirmations int      `json:"confirmations"`
 	UserId        int64    `json:"userId,omitempty"`
 	InvoiceId     int64    `json:"invoiceId,omitempty"`
-	Signature     string   `json:"sign,omitempty"`
+	Status        string   `json:"status,omitempty"`
+	ReplacedBy    string   `json:"replacedBy,omitempty"`
 }
 
-// Sign generates an HMAC-SHA256 signature for the notification using the API key.
-// The signature covers all critical fields to prevent tampering.
-func (n *TransferNotification) Sign(apiKey string) {
-	bodyParts := []string{
-		n.TxID,
-		fmt.Sprintf("%d", n.Timestamp),
-		fmt.Sprintf("%d", n.BlockNum),
-		fmt.Sprintf("%t", n.Success),
-		fmt.Sprintf("%t", n.NativeCoin),
-		n.Symbol,
-		n.From,
-		n.To,
-		n.Amount.String(),
-		n.Token,
-		n.TokenSymbol,
-		n.Fee.String(),
-		fmt.Sprintf("%t", n.InPool),
-		fmt.Sprintf("%t", n.Confirmed),
-		fmt.Sprintf("%d", n.Confirmations),
-		apiKey,
-	}
-	n.Signature = fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(bodyParts, ":"))))
-}
 // fill populates the notification from a TransferInfoRecord.
 func (n *TransferNotification) fill(tx *TransferInfoRecord) *TransferNotification {
 	n.TxID = tx.TxID
@@ -74,5 +49,7 @@ func (n *TransferNotification) fill(tx *TransferInfoRecord) *TransferNotificatio
 	n.Fee = tx.Fee
 	n.InPool = tx.InPool
 	n.Confirmed = tx.Confirmed
+	n.Status = tx.Status
+	n.ReplacedBy = tx.ReplacedBy
 	return n
 }
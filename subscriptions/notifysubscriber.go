@@ -1,16 +1,46 @@
 package subscriptions
 
-import "github.com/ITProLabDev/ethbacknode/tools/log"
+import (
+	"encoding/json"
+
+	"github.com/ITProLabDev/ethbacknode/tools/log"
+)
 
 // NotifySubscriber sends a notification to a specific subscriber.
 // Looks up the subscriber by ID and sends the data to their endpoint.
-func (s *Manager) NotifySubscriber(serviceId ServiceId, subject string, data Signer) {
+func (s *Manager) NotifySubscriber(serviceId ServiceId, subject string, data interface{}) {
 	s.subscribersMux.RLock()
-	defer s.subscribersMux.RUnlock()
 	subscriber, found := s.subscribers[serviceId]
+	s.subscribersMux.RUnlock()
 	if !found {
 		log.Error("Unknown serviceId: ", serviceId)
 		return
 	}
-	subscriber.sendNotification(subject, data, s.config.Debug)
+	s.notify(subscriber, subject, data)
+}
+
+// notify queues the notification in the outbox, so it is retried until delivered.
+// Without outbox storage, or for internal subscriptions, the notification is sent once
+// in a goroutine tracked by Stop.
+func (s *Manager) notify(subscriber *Subscription, subject string, data interface{}) {
+	if s.outbox != nil && !subscriber.Internal && subscriber.EndpointUrl != "" {
+		err := s.outboxPush(subscriber.ServiceId, subject, data)
+		if err == nil {
+			return
+		}
+		log.Error("Can not save notification to outbox, send once:", err)
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Error("Can not encode service notification:", err)
+		return
+	}
+	s.notifyWg.Add(1)
+	go func() {
+		defer s.notifyWg.Done()
+		err := subscriber.sendNotification(subject, payload, newDeliveryId(), s.keyDecoder)
+		if err != nil && s.config.Debug {
+			log.Error("Can not send service notification:", err)
+		}
+	}()
 }
@@ -0,0 +1,346 @@
+package subscriptions
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ITProLabDev/ethbacknode/tools/log"
+	"github.com/dgraph-io/badger"
+	"github.com/timshannon/badgerhold"
+)
+
+// Outbox record statuses.
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusDead    = "dead"
+)
+
+const (
+	// outboxPollInterval is the interval between delivery attempts of due notifications.
+	outboxPollInterval = 1 * time.Second
+	// defaultOutboxMaxAttempts is the number of delivery attempts before a notification is dead-lettered.
+	defaultOutboxMaxAttempts = 20
+	// defaultOutboxRetryDelay is the delay before the first retry, in seconds. It doubles with each attempt.
+	defaultOutboxRetryDelay = 5
+	// defaultOutboxRetryMaxDelay is the maximum delay between retries, in seconds.
+	defaultOutboxRetryMaxDelay = 3600
+	// outboxSequenceBandwidth is the number of record ids leased from the database at once.
+	outboxSequenceBandwidth = 100
+	// outboxConflictRetries is the number of attempts of an outbox write conflicting with a concurrent one.
+	outboxConflictRetries = 10
+)
+
+// outboxSequenceKey is the database key of the record id sequence.
+var outboxSequenceKey = []byte("outboxSequence")
+
+// outboxInit opens the record id sequence, schedules the notifications restored
+// from the previous run for immediate delivery and starts the delivery loop.
+func (s *Manager) outboxInit() (err error) {
+	s.outbox.Do(func(db *badgerhold.Store) {
+		s.outboxSeq, err = db.Badger().GetSequence(outboxSequenceKey, outboxSequenceBandwidth)
+	})
+	if err != nil {
+		return err
+	}
+	restored, err := s.outboxRestore()
+	if err != nil {
+		return err
+	}
+	if restored != 0 {
+		log.Info("Restored", restored, "undelivered notifications")
+	}
+	go s.outboxLoop()
+	s.outboxWakeUp()
+	return nil
+}
+
+// outboxRestore makes the pending notifications due now. The backoff delay of a
+// notification retried before the restart is not waited again, the attempts made
+// are kept.
+func (s *Manager) outboxRestore() (count int, err error) {
+	now := time.Now().Unix()
+	s.outbox.Do(func(db *badgerhold.Store) {
+		err = db.UpdateMatching(&OutboxRecord{}, badgerhold.Where("Status").Eq(OutboxStatusPending), func(record interface{}) error {
+			r := record.(*OutboxRecord)
+			if r.NextAttempt > now {
+				r.NextAttempt = now
+			}
+			count++
+			return nil
+		})
+	})
+	return count, err
+}
+
+// OutboxRecord is a notification waiting for delivery to a service endpoint.
+// Notifications of a service are delivered one by one in creation order. A failed
+// notification is retried with exponential backoff and blocks the next ones until it
+// is delivered or dead-lettered after the maximum number of attempts.
+type OutboxRecord struct {
+	Id          uint64          `json:"id" badgerhold:"key"`
+	DeliveryId  string          `json:"deliveryId"`
+	ServiceId   ServiceId       `json:"serviceId" badgerhold:"index"`
+	Method      string          `json:"method"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status" badgerhold:"index"`
+	Attempts    int             `json:"attempts"`
+	NextAttempt int64           `json:"nextAttempt"`
+	LastError   string          `json:"lastError,omitempty"`
+	CreatedAt   int64           `json:"createdAt"`
+}
+
+// OutboxFilter selects outbox records. Zero fields match any record.
+type OutboxFilter struct {
+	Id        uint64    `json:"id,omitempty"`
+	ServiceId ServiceId `json:"serviceId,omitempty"`
+	Status    string    `json:"status,omitempty"`
+}
+
+// query builds the badgerhold query of the filter, nil matches all records.
+func (f *OutboxFilter) query() (query *badgerhold.Query) {
+	where := func(field string, value interface{}) {
+		if query == nil {
+			query = badgerhold.Where(field).Eq(value)
+		} else {
+			query = query.And(field).Eq(value)
+		}
+	}
+	if f.Id != 0 {
+		where("Id", f.Id)
+	}
+	if f.ServiceId != 0 {
+		where("ServiceId", f.ServiceId)
+	}
+	if f.Status != "" {
+		where("Status", f.Status)
+	}
+	return query
+}
+
+// OutboxList returns the undelivered notifications matching the filter, oldest first.
+func (s *Manager) OutboxList(filter *OutboxFilter) (records []*OutboxRecord, err error) {
+	if s.outbox == nil {
+		return nil, ErrOutboxDisabled
+	}
+	query := filter.query()
+	if query == nil {
+		query = &badgerhold.Query{}
+	}
+	s.outbox.Do(func(db *badgerhold.Store) {
+		err = db.Find(&records, query.SortBy("Id"))
+	})
+	return records, err
+}
+
+// OutboxRetry resets the attempts of the notifications matching the filter,
+// including dead-lettered ones, and schedules them for immediate delivery.
+func (s *Manager) OutboxRetry(filter *OutboxFilter) (count int, err error) {
+	if s.outbox == nil {
+		return 0, ErrOutboxDisabled
+	}
+	now := time.Now().Unix()
+	err = s.outboxWrite(func(db *badgerhold.Store) error {
+		count = 0
+		return db.UpdateMatching(&OutboxRecord{}, filter.query(), func(record interface{}) error {
+			r := record.(*OutboxRecord)
+			r.Status = OutboxStatusPending
+			r.Attempts = 0
+			r.NextAttempt = now
+			count++
+			return nil
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	s.outboxWakeUp()
+	return count, nil
+}
+
+// OutboxPurge deletes the notifications matching the filter without delivery.
+func (s *Manager) OutboxPurge(filter *OutboxFilter) (count int, err error) {
+	records, err := s.OutboxList(filter)
+	if err != nil {
+		return 0, err
+	}
+	for _, record := range records {
+		err = s.outboxWrite(func(db *badgerhold.Store) error {
+			return db.Delete(record.Id, new(OutboxRecord))
+		})
+		if errors.Is(err, badgerhold.ErrNotFound) {
+			err = nil
+		}
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// outboxPush stores the notification for delivery to the service endpoint.
+func (s *Manager) outboxPush(serviceId ServiceId, method string, message interface{}) (err error) {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	id, err := s.outboxSeq.Next()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	record := &OutboxRecord{
+		Id:          id + 1, // zero id matches any record in filters
+		DeliveryId:  newDeliveryId(),
+		ServiceId:   serviceId,
+		Method:      method,
+		Payload:     payload,
+		Status:      OutboxStatusPending,
+		NextAttempt: now.Unix(),
+		CreatedAt:   now.Unix(),
+	}
+	err = s.outboxWrite(func(db *badgerhold.Store) error {
+		return db.Insert(record.Id, record)
+	})
+	if err != nil {
+		return err
+	}
+	s.outboxWakeUp()
+	return nil
+}
+
+// outboxWrite runs the write transaction, retried while it conflicts with a
+// concurrent write of the notify loop or the delivery loop.
+func (s *Manager) outboxWrite(write func(db *badgerhold.Store) error) (err error) {
+	for i := 0; i < outboxConflictRetries; i++ {
+		s.outbox.Do(func(db *badgerhold.Store) {
+			err = write(db)
+		})
+		if !errors.Is(err, badger.ErrConflict) {
+			return err
+		}
+	}
+	return err
+}
+
+// outboxRelease returns the unused leased record ids, once no more notifications are saved.
+func (s *Manager) outboxRelease() {
+	if s.outboxSeq == nil {
+		return
+	}
+	err := s.outboxSeq.Release()
+	if err != nil {
+		log.Error("Can not release outbox sequence:", err)
+	}
+}
+
+// outboxWakeUp makes the outbox loop check for due notifications without waiting for the poll interval.
+func (s *Manager) outboxWakeUp() {
+	select {
+	case s.outboxWake <- struct{}{}:
+	default:
+	}
+}
+
+// outboxLoop delivers due notifications until the manager is stopped.
+// Services are processed in parallel, notifications of a service one by one.
+func (s *Manager) outboxLoop() {
+	defer close(s.outboxDone)
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.outboxWake:
+		case <-s.quit:
+			return
+		}
+		var services []*Subscription
+		s.subscriptionViewAll(func(service *Subscription) {
+			services = append(services, service)
+		})
+		var wg sync.WaitGroup
+		for _, service := range services {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.outboxDeliverService(service)
+			}()
+		}
+		wg.Wait()
+	}
+}
+
+// outboxDeliverService delivers the due notifications of the service in order.
+// Stops at the first failed notification, it is retried after the backoff delay.
+func (s *Manager) outboxDeliverService(service *Subscription) {
+	for !s.isStopping() {
+		var records []*OutboxRecord
+		var err error
+		s.outbox.Do(func(db *badgerhold.Store) {
+			err = db.Find(&records, badgerhold.Where("ServiceId").Eq(service.ServiceId).
+				And("Status").Eq(OutboxStatusPending).
+				SortBy("Id").Limit(1))
+		})
+		if err != nil {
+			log.Error("Can not load outbox:", err)
+			return
+		}
+		if len(records) == 0 || records[0].NextAttempt > time.Now().Unix() {
+			return
+		}
+		record := records[0]
+		if record.DeliveryId == "" {
+			// saved by a version without delivery ids, kept with the next attempt
+			record.DeliveryId = newDeliveryId()
+		}
+		err = service.sendNotification(record.Method, record.Payload, record.DeliveryId, s.keyDecoder)
+		delivered := err == nil
+		if delivered {
+			err = s.outboxWrite(func(db *badgerhold.Store) error {
+				return db.Delete(record.Id, new(OutboxRecord))
+			})
+		} else {
+			record.Attempts++
+			record.LastError = err.Error()
+			if record.Attempts >= s.config.OutboxMaxAttempts {
+				log.Error("Service", service.ServiceId, "notification", record.Id, record.Method, "dead-lettered after", record.Attempts, "attempts:", err)
+				record.Status = OutboxStatusDead
+			} else {
+				if s.config.Debug {
+					log.Error("Can not send service notification:", record.Id, record.Method, err)
+				}
+				record.NextAttempt = time.Now().Unix() + s.outboxRetryDelay(record.Attempts)
+			}
+			err = s.outboxWrite(func(db *badgerhold.Store) error {
+				return db.Update(record.Id, record)
+			})
+		}
+		if errors.Is(err, badgerhold.ErrNotFound) {
+			// purged during the delivery
+			err = nil
+		}
+		if err != nil {
+			log.Error("Can not update outbox:", err)
+			return
+		}
+		if !delivered && record.Status == OutboxStatusPending {
+			// the next notifications wait for the retry
+			return
+		}
+	}
+}
+
+// outboxRetryDelay returns the delay in seconds before the next attempt.
+func (s *Manager) outboxRetryDelay(attempts int) int64 {
+	delay := int64(s.config.OutboxRetryDelay)
+	for i := 1; i < attempts && delay < int64(s.config.OutboxRetryMaxDelay); i++ {
+		delay *= 2
+	}
+	if delay > int64(s.config.OutboxRetryMaxDelay) {
+		delay = int64(s.config.OutboxRetryMaxDelay)
+	}
+	return delay
+}
@@ -0,0 +1,120 @@
+package subscriptions
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ITProLabDev/ethbacknode/clients/ethclient"
+	"github.com/ITProLabDev/ethbacknode/storage"
+	"github.com/ITProLabDev/ethbacknode/types"
+	"github.com/timshannon/badgerhold"
+)
+
+func testOutboxStorage(t *testing.T) *storage.BadgerHoldStorage {
+	t.Helper()
+	outbox, err := storage.NewBadgerHoldStorage("Outbox", t.TempDir(), "subscriptions", "outbox.db")
+	if err != nil {
+		t.Fatalf("outbox storage: %v", err)
+	}
+	t.Cleanup(func() { outbox.Close() })
+	return outbox
+}
+
+// waitReceived waits until the endpoint received count notifications of the method.
+func waitReceived(t *testing.T, endpoint *testEndpoint, method string, count int) []json.RawMessage {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		received := endpoint.received(method)
+		if len(received) >= count || time.Now().After(deadline) {
+			return received
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestOutbox_ServiceOrder(t *testing.T) {
+	chain := newTestChain()
+	s, endpoint := newTestManager(t, chain, WithOutboxStorage(testOutboxStorage(t)))
+	deposit := testAddress(t, s, 0x11, 1)
+	sender, _ := ethclient.GetAddressCodec().EncodeBytesToAddress(make([]byte, 20))
+
+	const count = 50
+	for i := 0; i < count; i++ {
+		s.TransactionEvent(&types.TransferInfo{
+			TxID:       ethclient.TransferLogId(testTransferId(1), int64(i)),
+			BlockNum:   100,
+			Success:    true,
+			Transfer:   true,
+			NativeCoin: true,
+			From:       sender,
+			To:         deposit,
+			Amount:     big.NewInt(int64(i + 1)),
+			Fee:        big.NewInt(21000),
+		})
+	}
+	received := waitReceived(t, endpoint, "transactionEvent", count)
+	if len(received) != count {
+		t.Fatalf("notifications: got %d, want %d", len(received), count)
+	}
+	for i, params := range received {
+		var n TransferNotification
+		if err := json.Unmarshal(params, &n); err != nil {
+			t.Fatalf("notification %d: %v", i, err)
+		}
+		if want := ethclient.TransferLogId(testTransferId(1), int64(i)); n.TxID != want {
+			t.Fatalf("notification %d: got %s, want %s", i, n.TxID, want)
+		}
+	}
+}
+
+func TestOutbox_RestoredDelivered(t *testing.T) {
+	outbox := testOutboxStorage(t)
+	retryAt := time.Now().Add(time.Hour).Unix()
+	var err error
+	outbox.Do(func(db *badgerhold.Store) {
+		for id := uint64(1); id <= 2 && err == nil; id++ {
+			err = db.Insert(id, &OutboxRecord{
+				Id:          id,
+				ServiceId:   1,
+				Method:      "restored",
+				Payload:     json.RawMessage(`{}`),
+				Status:      OutboxStatusPending,
+				Attempts:    3,
+				NextAttempt: retryAt,
+			})
+		}
+	})
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	s, endpoint := newTestManager(t, newTestChain(), WithOutboxStorage(outbox))
+	if got := len(waitReceived(t, endpoint, "restored", 2)); got != 2 {
+		t.Fatalf("restored notifications: got %d, want 2", got)
+	}
+	stopTestManager(t, s)
+	records, err := s.OutboxList(&OutboxFilter{})
+	if err != nil || len(records) != 0 {
+		t.Fatalf("outbox: %v, %d records left", err, len(records))
+	}
+}
+
+func TestOutbox_RetryKeepsDeliveryId(t *testing.T) {
+	s, endpoint := newTestManager(t, newTestChain(), WithOutboxStorage(testOutboxStorage(t)))
+	s.config.OutboxRetryDelay = 0
+	endpoint.mux.Lock()
+	endpoint.fail = 2
+	endpoint.mux.Unlock()
+	s.goNotify(func() { s.NotifySubscriber(1, "retried", struct{}{}) })
+	s.goNotify(func() { s.NotifySubscriber(1, "next", struct{}{}) })
+	if got := len(waitReceived(t, endpoint, "next", 1)); got != 1 {
+		t.Fatalf("notifications: got %d, want 1", got)
+	}
+	stopTestManager(t, s)
+	ids := endpoint.deliveryIds
+	if len(ids) != 4 || ids[0] == "" || ids[1] != ids[0] || ids[2] != ids[0] || ids[3] == ids[0] {
+		t.Fatalf("delivery ids: %q, want 3 attempts with the same id and a new one", ids)
+	}
+}
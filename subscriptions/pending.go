@@ -0,0 +1,70 @@
+package subscriptions
+
+import (
+	"time"
+
+	"github.com/ITProLabDev/ethbacknode/tools/log"
+	"github.com/ITProLabDev/ethbacknode/types"
+	"github.com/timshannon/badgerhold"
+)
+
+// defaultPendingTimeout is the time in seconds a transaction may stay in the mempool
+// before it is checked for being dropped or replaced.
+const defaultPendingTimeout = 900
+
+// pendingProcess checks the mempool transactions pending for longer than the
+// pending timeout. Transactions which are no longer known by the node are marked
+// dropped or replaced and reported with the new status. Runs on every block,
+// skipped if the previous run is not finished yet.
+func (s *Manager) pendingProcess() {
+	if !s.pendingMux.TryLock() {
+		return
+	}
+	defer s.pendingMux.Unlock()
+	var txList []*TransferInfoRecord
+	var err error
+	s.transactionPool.Do(func(db *badgerhold.Store) {
+		err = db.Find(&txList, badgerhold.Where(
+			"BlockNum").
+			Le(0).
+			And("InPool").Eq(true).
+			And("Status").Eq(""))
+	})
+	if err != nil {
+		log.Error("Can not load pending transactions:", err)
+		return
+	}
+	now := time.Now().Unix()
+	timeout := int64(s.config.PendingTimeout)
+	for _, tx := range txList {
+		if s.isStopping() {
+			return
+		}
+		if now-tx.SeenAt < timeout || now-tx.CheckedAt < timeout {
+			continue
+		}
+		transferInfo := new(types.TransferInfo)
+		tx.toTransferInfo(transferInfo)
+		status, replacedBy, err := s.blockchainClient.PendingTransferStatus(transferInfo, tx.SeenBlock)
+		if err != nil {
+			log.Error("Can not check pending transaction", tx.TxID, ":", err)
+			continue
+		}
+		tx.CheckedAt = now
+		if status == types.TransferStatusDropped || status == types.TransferStatusReplaced {
+			log.Warning("Transaction", tx.TxID, status, replacedBy)
+			tx.Status = status
+			tx.ReplacedBy = replacedBy
+		}
+		err = s.saveTransaction(tx)
+		if err != nil {
+			log.Error("Can not save transaction info:", err)
+			continue
+		}
+		if tx.Status == "" || tx.Ignore {
+			continue
+		}
+		txNotification := new(TransferNotification).fill(tx)
+		s.goNotify(func() { s.transactionEventPostProcess(txNotification) })
+	}
+}
@@ -0,0 +1,95 @@
+package subscriptions
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schedule is a parsed cron-like schedule "minute hour day-of-month month day-of-week".
+// Fields accept "*", numbers, ranges "1-5", lists "1,3" and steps "*/15" or "8-18/2".
+// Day of week is 0-6 starting from Sunday. As in cron, if both day fields are
+// restricted the schedule matches either of them.
+type schedule struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+}
+
+// scheduleFieldBounds are the allowed values of the schedule fields.
+var scheduleFieldBounds = [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 6}}
+
+// parseSchedule parses the cron-like schedule expression.
+func parseSchedule(expr string) (*schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, ErrInvalidSchedule
+	}
+	var bits [5]uint64
+	for i, field := range fields {
+		var err error
+		bits[i], err = parseScheduleField(field, scheduleFieldBounds[i][0], scheduleFieldBounds[i][1])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &schedule{
+		minute: bits[0],
+		hour:   bits[1],
+		dom:    bits[2],
+		month:  bits[3],
+		dow:    bits[4],
+		domAny: fields[2] == "*",
+		dowAny: fields[4] == "*",
+	}, nil
+}
+
+// parseScheduleField returns the bit set of the values matched by the field.
+func parseScheduleField(field string, min, max int) (bits uint64, err error) {
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step <= 0 {
+				return 0, ErrInvalidSchedule
+			}
+		}
+		from, to := min, max
+		if rangePart != "*" {
+			fromPart, toPart, isRange := strings.Cut(rangePart, "-")
+			from, err = strconv.Atoi(fromPart)
+			if err != nil {
+				return 0, ErrInvalidSchedule
+			}
+			to = from
+			if isRange {
+				to, err = strconv.Atoi(toPart)
+				if err != nil {
+					return 0, ErrInvalidSchedule
+				}
+			} else if hasStep {
+				to = max
+			}
+		}
+		if from < min || to > max || from > to {
+			return 0, ErrInvalidSchedule
+		}
+		for v := from; v <= to; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// matches reports whether the schedule matches the minute of t.
+func (s *schedule) matches(t time.Time) bool {
+	if s.minute&(1<<uint(t.Minute())) == 0 || s.hour&(1<<uint(t.Hour())) == 0 || s.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
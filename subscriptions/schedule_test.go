@@ -0,0 +1,66 @@
+package subscriptions
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	invalid := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 7",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1- * * * *",
+		"*/x * * * *",
+	}
+	for _, expr := range invalid {
+		if _, err := parseSchedule(expr); !errors.Is(err, ErrInvalidSchedule) {
+			t.Errorf("%q: got %v, want ErrInvalidSchedule", expr, err)
+		}
+	}
+	// 2024-01-01 is a Monday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, time.January, day, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		expr string
+		at   time.Time
+		want bool
+	}{
+		{expr: "* * * * *", at: at(1, 0, 0), want: true},
+		{expr: "*/10 * * * *", at: at(1, 3, 20), want: true},
+		{expr: "*/10 * * * *", at: at(1, 3, 25)},
+		{expr: "5,35 * * * *", at: at(1, 3, 35), want: true},
+		{expr: "0 8-18/2 * * *", at: at(1, 10, 0), want: true},
+		{expr: "0 8-18/2 * * *", at: at(1, 11, 0)},
+		{expr: "0 8-18/2 * * *", at: at(1, 20, 0)},
+		{expr: "30 2 * * 1-5", at: at(1, 2, 30), want: true},
+		{expr: "30 2 * * 1-5", at: at(7, 2, 30)},
+		{expr: "0 0 1 * *", at: at(1, 0, 0), want: true},
+		{expr: "0 0 1 2 *", at: at(1, 0, 0)},
+		// both day fields restricted: either matches
+		{expr: "0 0 15 * 0", at: at(7, 0, 0), want: true},
+		{expr: "0 0 15 * 0", at: at(15, 0, 0), want: true},
+		{expr: "0 0 15 * 0", at: at(8, 0, 0)},
+		// one day field restricted: both must match
+		{expr: "0 0 * * 0", at: at(8, 0, 0)},
+	}
+	for _, tt := range tests {
+		s, err := parseSchedule(tt.expr)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.expr, err)
+		}
+		if got := s.matches(tt.at); got != tt.want {
+			t.Errorf("%q at %s: got %v, want %v", tt.expr, tt.at.Format(time.DateTime), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package subscriptions
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Notification delivery headers. The signature is the hex encoded HMAC-SHA256 of
+// "<timestamp>.<deliveryId>.<body>" keyed by the service ApiKey decoded by the KeyDecoder.
+const (
+	HeaderSignature          = "X-Signature"
+	HeaderSignatureTimestamp = "X-Signature-Timestamp"
+	HeaderDeliveryId         = "X-Delivery-Id"
+)
+
+// KeyDecoder decodes the service ApiKey into the signing key. The security manager
+// decodes it according to the configured key format, the same key verifies the
+// requests of the service.
+type KeyDecoder func(apiKey string) (key []byte, err error)
+
+// newDeliveryId returns a random identifier of a notification. The outbox keeps it
+// with the notification, so all delivery attempts of the notification share it.
+func newDeliveryId() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// NotificationSignature returns the signature of the notification body sent with
+// the given timestamp and delivery id.
+func NotificationSignature(key []byte, timestamp, deliveryId string, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write([]byte(deliveryId))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// signNotification sets the delivery headers of the request with the exact body to be sent.
+// The signature header is set only if the subscription has an ApiKey. Without a key
+// decoder the raw ApiKey bytes are the key.
+func (s *Subscription) signNotification(header http.Header, body []byte, deliveryId string, decodeKey KeyDecoder) error {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	header.Set(HeaderSignatureTimestamp, timestamp)
+	header.Set(HeaderDeliveryId, deliveryId)
+	if s.ApiKey == "" {
+		return nil
+	}
+	key := []byte(s.ApiKey)
+	if decodeKey != nil {
+		var err error
+		key, err = decodeKey(s.ApiKey)
+		if err != nil {
+			return err
+		}
+	}
+	header.Set(HeaderSignature, NotificationSignature(key, timestamp, deliveryId, body))
+	return nil
+}
@@ -0,0 +1,44 @@
+package subscriptions
+
+import (
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ITProLabDev/ethbacknode/security"
+	"github.com/ITProLabDev/ethbacknode/uniclient"
+)
+
+func TestSignNotification_DecodedKey(t *testing.T) {
+	const apiKey = "0x8f2b6c1d5e4a3f90718263544536271809a0b1c2d3e4f5061728394a5b6c7d8e"
+	body := []byte(`{"jsonrpc":"2.0","method":"transactionEvent","params":{}}`)
+	decodeKey := security.NewManager().DecodeKey
+	subscription := &Subscription{ApiKey: apiKey}
+	header := make(http.Header)
+	if err := subscription.signNotification(header, body, "d1", decodeKey); err != nil {
+		t.Fatalf("signNotification: %v", err)
+	}
+	// the key of the request signatures verifies the notification, the raw ApiKey does not
+	key, _ := hex.DecodeString(apiKey[2:])
+	timestamp := header.Get(HeaderSignatureTimestamp)
+	signature := header.Get(HeaderSignature)
+	if !uniclient.VerifyNotificationSignature(key, timestamp, "d1", body, signature) {
+		t.Fatal("signature does not match the decoded key")
+	}
+	if uniclient.VerifyNotificationSignature([]byte(apiKey), timestamp, "d1", body, signature) {
+		t.Fatal("signature matches the raw ApiKey")
+	}
+}
+
+func TestSignNotification_InvalidKey(t *testing.T) {
+	subscription := &Subscription{ApiKey: "not a hex key"}
+	header := make(http.Header)
+	err := subscription.signNotification(header, []byte("{}"), "d1", security.NewManager().DecodeKey)
+	if !errors.Is(err, security.ErrInvalidApiKey) {
+		t.Fatalf("got %v, want ErrInvalidApiKey", err)
+	}
+	if header.Get(HeaderSignature) != "" {
+		t.Fatal("signature set with an invalid key")
+	}
+}
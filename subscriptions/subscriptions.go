@@ -1,12 +1,24 @@
 package subscriptions
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"time"
 
 	"github.com/ITProLabDev/ethbacknode/clients/urpc"
 	"github.com/ITProLabDev/ethbacknode/tools/log"
 )
 
+// notificationTimeout limits the time of a single notification delivery.
+const notificationTimeout = 10 * time.Second
+
+// notificationHttpClient is the HTTP client used to deliver notifications to service endpoints.
+var notificationHttpClient = &http.Client{Timeout: notificationTimeout}
+
 // ServiceId is a unique identifier for a service subscription.
 type ServiceId int
 
@@ -29,6 +41,7 @@ func (s *Manager) subscriptionsSave() error {
 	}
 	return s.subscribersStorage.Save(b)
 }
+
 // subscriptionsLoad loads subscriptions from storage.
 func (s *Manager) subscriptionsLoad() error {
 	s.subscribers = make(map[ServiceId]*Subscription)
@@ -92,28 +105,40 @@ func (s *Manager) SubscriptionEdit(serviceId ServiceId, edit func(subscription *
 
 // Subscription represents a service's subscription configuration.
 // Controls what events to report and where to send notifications.
+// MinAmounts holds the minimum reported amount per asset symbol in base units.
+// ConfirmationMilestones limits transaction notifications to the listed confirmation
+// counts (0 is the mempool notification), the final confirmed notification is always sent.
+// A nil ReportMainCoin reports the native coin, as subscriptions saved before the field.
 type Subscription struct {
-	rpc                  *urpc.Client
-	ServiceName          string          `json:"serviceName"`
-	ServiceId            ServiceId       `json:"serviceId"`
-	Internal             bool            `json:"internal,omitempty"`
-	ApiToken             string          `json:"apiToken"`
-	ApiKey               string          `json:"apiKey"`
-	EndpointUrl          string          `json:"eventUrl"`
-	ReportNewBlock       bool            `json:"reportNewBlock"`
-	ReportIncomingTx     bool            `json:"reportIncomingTx"`
-	ReportOutgoingTx     bool            `json:"reportOutgoingTx"`
-	ReportMainCoin       bool            `json:"reportMainCoin"`
-	ReportTokens         map[string]bool `json:"reportTokens"`
-	ReportBalanceChange  bool            `json:"balanceChange"`
-	GatherToMaster       bool            `json:"gatherToMaster"`
-	MasterList           []string        `json:"masterList"`
-	SecuritySignRequests bool            `json:"securitySignRequests,omitempty"`
-	SecuritySignResponse bool            `json:"securitySignResponse,omitempty"`
+	ServiceName            string              `json:"serviceName"`
+	ServiceId              ServiceId           `json:"serviceId"`
+	Internal               bool                `json:"internal,omitempty"`
+	ApiToken               string              `json:"apiToken"`
+	ApiKey                 string              `json:"apiKey"`
+	EndpointUrl            string              `json:"eventUrl"`
+	ReportNewBlock         bool                `json:"reportNewBlock"`
+	ReportIncomingTx       bool                `json:"reportIncomingTx"`
+	ReportOutgoingTx       bool                `json:"reportOutgoingTx"`
+	ReportMainCoin         *bool               `json:"reportMainCoin,omitempty"`
+	ReportTokens           map[string]bool     `json:"reportTokens"`
+	MinAmounts             map[string]*big.Int `json:"minAmounts,omitempty"`
+	ConfirmationMilestones []int               `json:"confirmationMilestones,omitempty"`
+	ReportBalanceChange    bool                `json:"balanceChange"`
+	GatherToMaster         bool                `json:"gatherToMaster"`
+	MasterList             []string            `json:"masterList"`
+	GasStation             string              `json:"gasStation,omitempty"`
+	SweepPolicy            *SweepPolicy        `json:"sweepPolicy,omitempty"`
+	SecuritySignRequests   bool                `json:"securitySignRequests,omitempty"`
+	SecuritySignResponse   bool                `json:"securitySignResponse,omitempty"`
 	//Reserved for future use
 	SecurityUseEncryption bool `json:"securityUseEncryption,omitempty"`
 }
 
+// reportsMainCoin reports whether the native coin transfers are notified.
+func (s *Subscription) reportsMainCoin() bool {
+	return s.ReportMainCoin == nil || *s.ReportMainCoin
+}
+
 // equal compares two subscriptions for equality.
 func (s *Subscription) equal(with *Subscription) bool {
 	if s.ServiceName != with.ServiceName {
@@ -140,7 +165,7 @@ func (s *Subscription) equal(with *Subscription) bool {
 	if s.GatherToMaster != with.GatherToMaster {
 		return false
 	}
-	if s.ReportMainCoin != with.ReportMainCoin {
+	if s.reportsMainCoin() != with.reportsMainCoin() {
 		return false
 	}
 	if len(s.MasterList) != len(with.MasterList) {
@@ -162,32 +187,38 @@ func (s *Subscription) equal(with *Subscription) bool {
 	return true
 }
 
-// sendNotification sends an RPC notification to the subscriber's endpoint.
+// sendNotification sends an RPC notification with the JSON encoded payload to the
+// subscriber's endpoint. Every attempt is signed with a fresh timestamp, the delivery id
+// is the same for all attempts of the notification, the ApiKey is decoded by decodeKey.
+// The notification is delivered only if the endpoint responds with HTTP 200 OK.
 // For internal subscriptions, logs the notification instead.
-func (s *Subscription) sendNotification(method string, message interface{}, debug bool) {
+func (s *Subscription) sendNotification(method string, payload json.RawMessage, deliveryId string, decodeKey KeyDecoder) (err error) {
 	if s.Internal || s.EndpointUrl == "" {
 		log.Debug("Internal notification:", method)
-		log.Dump(message)
-		return
-	}
-	if s.rpc == nil {
-		s.rpc = urpc.NewClient(
-			urpc.WithHTTPRpc(s.EndpointUrl, nil),
-		)
-	}
-	req := urpc.NewRequestWithObject(method, message)
-	//log.Debug("Send service notification to", s.EndpointUrl)
-	//log.Dump(message)
-	response, err := s.rpc.Call(req)
-	//_, _ = s.rpc.Call(req)
+		log.Dump(payload)
+		return nil
+	}
+	body, err := json.Marshal(urpc.NewRequestWithObject(method, payload))
 	if err != nil {
-		if debug {
-			log.Error("Can not send service notification:", err, response)
-		}
+		return err
 	}
-}
-
-// Signer defines an interface for signing notification payloads.
-type Signer interface {
-	Sign(apiKey string)
+	req, err := http.NewRequest(http.MethodPost, s.EndpointUrl, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	err = s.signNotification(req.Header, body, deliveryId, decodeKey)
+	if err != nil {
+		return err
+	}
+	httpResponse, err := notificationHttpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer httpResponse.Body.Close()
+	_, _ = io.Copy(io.Discard, httpResponse.Body)
+	if httpResponse.StatusCode != http.StatusOK {
+		return errors.New("invalid server response: " + httpResponse.Status)
+	}
+	return nil
 }
@@ -0,0 +1,267 @@
+package subscriptions
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ITProLabDev/ethbacknode/tools/log"
+	"github.com/timshannon/badgerhold"
+)
+
+// Token sweep task statuses.
+const (
+	SweepStatusPending  = "pending"
+	SweepStatusFunding  = "funding"
+	SweepStatusSweeping = "sweeping"
+	SweepStatusFailed   = "failed"
+)
+
+// sweepTaskTimeout is the time a sweep task may wait in the same status before it fails.
+const sweepTaskTimeout = 1 * time.Hour
+
+// SweepTask is a token sweep of a deposit address to the master address.
+// The deposit address is first funded from the service gas station with the native
+// coin required to pay the token transfer fee, then after the funding transaction is
+// confirmed the whole token balance is transferred to master. The task is deleted
+// when the token transfer is confirmed.
+type SweepTask struct {
+	Id        string    `json:"id" badgerhold:"key"`
+	ServiceId ServiceId `json:"serviceId"`
+	Address   string    `json:"address"`
+	Token     string    `json:"token"`
+	Master    string    `json:"master"`
+	Status    string    `json:"status" badgerhold:"index"`
+	GasTxID   string    `json:"gasTxId,omitempty"`
+	SweepTxID string    `json:"sweepTxId,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt int64     `json:"createdAt"`
+	UpdatedAt int64     `json:"updatedAt"`
+}
+
+// setStatus moves the task to the status and resets its timeout.
+func (t *SweepTask) setStatus(status string) {
+	t.Status = status
+	t.UpdatedAt = time.Now().Unix()
+}
+
+// sweepTaskId returns the id of the token sweep task of the address.
+func sweepTaskId(address, token string) string {
+	return address + ":" + token
+}
+
+// gatherTokenToMaster creates the token sweep task of the deposit address,
+// unless the address is already being swept. Reports whether the sweep is in progress.
+func (s *Manager) gatherTokenToMaster(serviceInfo *Subscription, address, token, master string) bool {
+	task := new(SweepTask)
+	var err error
+	s.transactionPool.Do(func(db *badgerhold.Store) {
+		err = db.Get(sweepTaskId(address, token), task)
+	})
+	if err == nil && task.Status != SweepStatusFailed {
+		return true
+	}
+	if err != nil && !errors.Is(err, badgerhold.ErrNotFound) {
+		log.Error("Can not load sweep task:", err)
+		return false
+	}
+	now := time.Now().Unix()
+	task = &SweepTask{
+		Id:        sweepTaskId(address, token),
+		ServiceId: serviceInfo.ServiceId,
+		Address:   address,
+		Token:     token,
+		Master:    master,
+		Status:    SweepStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err = s.sweepTaskSave(task)
+	if err != nil {
+		log.Error("Can not save sweep task:", err)
+		return false
+	}
+	s.goProcess(s.sweepProcess)
+	return true
+}
+
+// SweepTasks returns the token sweep tasks of the service in progress and the failed ones.
+func (s *Manager) SweepTasks(serviceId ServiceId) (tasks []*SweepTask, err error) {
+	s.transactionPool.Do(func(db *badgerhold.Store) {
+		err = db.Find(&tasks, badgerhold.Where("ServiceId").Eq(serviceId))
+	})
+	return tasks, err
+}
+
+// sweepProcess advances the token sweep tasks. Runs on every block, skipped if
+// the previous run is not finished yet.
+func (s *Manager) sweepProcess() {
+	if !s.sweepMux.TryLock() {
+		return
+	}
+	defer s.sweepMux.Unlock()
+	var tasks []*SweepTask
+	var err error
+	s.transactionPool.Do(func(db *badgerhold.Store) {
+		err = db.Find(&tasks, badgerhold.Where("Status").Ne(SweepStatusFailed))
+	})
+	if err != nil {
+		log.Error("Can not load sweep tasks:", err)
+		return
+	}
+	for _, task := range tasks {
+		if s.isStopping() {
+			return
+		}
+		done, err := s.sweepTaskStep(task)
+		if err != nil {
+			task.Error = err.Error()
+			if time.Now().Unix()-task.UpdatedAt > int64(sweepTaskTimeout/time.Second) {
+				log.Error("Sweep of", task.Token, "from", task.Address, "failed:", err)
+				task.setStatus(SweepStatusFailed)
+			}
+		}
+		if done {
+			err = s.sweepTaskDelete(task)
+		} else {
+			err = s.sweepTaskSave(task)
+		}
+		if err != nil {
+			log.Error("Can not save sweep task:", err)
+		}
+	}
+}
+
+// sweepTaskStep advances the task by one step. Returns done if the task is finished.
+// An error leaves the task in its status, it is retried on the next block.
+func (s *Manager) sweepTaskStep(task *SweepTask) (done bool, err error) {
+	switch task.Status {
+	case SweepStatusPending:
+		return s.sweepTaskStart(task)
+	case SweepStatusFunding:
+		confirmed, err := s.sweepTxConfirmed(task.GasTxID)
+		if err != nil || !confirmed {
+			return false, err
+		}
+		task.setStatus(SweepStatusPending)
+		return s.sweepTaskStart(task)
+	case SweepStatusSweeping:
+		confirmed, err := s.sweepTxConfirmed(task.SweepTxID)
+		if err != nil || !confirmed {
+			return false, err
+		}
+		log.Info("Service", task.ServiceId, "swept", task.Token, "from", task.Address, "to", task.Master, "tx", task.SweepTxID)
+		return true, nil
+	}
+	return false, nil
+}
+
+// sweepTaskStart sends the token transfer to master if the deposit address can pay
+// the fee, otherwise funds the missing fee from the gas station. While the gas price
+// is above the MaxGasPrice of the sweep policy nothing is sent and the task stays pending.
+func (s *Manager) sweepTaskStart(task *SweepTask) (done bool, err error) {
+	depositAddress, err := s.addressPool.GetAddress(task.Address)
+	if err != nil {
+		return false, err
+	}
+	tokenBalance, err := s.blockchainClient.TokensBalanceOf(task.Address, task.Token)
+	if err != nil {
+		return false, err
+	}
+	if tokenBalance.Sign() <= 0 {
+		return true, nil
+	}
+	serviceInfo, err := s.SubscriptionGet(task.ServiceId)
+	if err != nil {
+		return false, err
+	}
+	allowed, err := s.sweepGasPriceAllowed(task.ServiceId, serviceInfo.SweepPolicy)
+	if err != nil {
+		return false, err
+	}
+	if !allowed {
+		// waiting for the gas price is not a failure, the timeout restarts
+		task.Error = ""
+		task.setStatus(SweepStatusPending)
+		return false, nil
+	}
+	fee, err := s.blockchainClient.TransferTokenGetMaxFee(task.Address, task.Master, tokenBalance, task.Token)
+	if err != nil {
+		return false, err
+	}
+	balance, err := s.blockchainClient.BalanceOf(task.Address)
+	if err != nil {
+		return false, err
+	}
+	if balance.Cmp(fee) >= 0 {
+		signer, err := s.addressPool.AddressSigner(depositAddress)
+		if err != nil {
+			return false, err
+		}
+		txId, err := s.blockchainClient.TransferAllTokenBySigner(signer, task.Master, task.Token)
+		if err != nil {
+			return false, err
+		}
+		s.trackInternalTransaction(txId, task.Address, task.Master, nil)
+		task.SweepTxID = txId
+		task.Error = ""
+		task.setStatus(SweepStatusSweeping)
+		return false, nil
+	}
+	if serviceInfo.GasStation == "" {
+		return false, ErrGasStationNotSet
+	}
+	gasStation, err := s.addressPool.GetAddress(serviceInfo.GasStation)
+	if err != nil {
+		return false, err
+	}
+	gasStationSigner, err := s.addressPool.AddressSigner(gasStation)
+	if err != nil {
+		return false, err
+	}
+	topUp := new(big.Int).Sub(fee, balance)
+	txId, err := s.blockchainClient.TransferBySigner(gasStationSigner, task.Address, topUp)
+	if err != nil {
+		return false, err
+	}
+	s.trackInternalTransaction(txId, gasStation.Address, task.Address, topUp)
+	task.GasTxID = txId
+	task.Error = ""
+	task.setStatus(SweepStatusFunding)
+	return false, nil
+}
+
+// sweepTxConfirmed reports whether the transaction is confirmed.
+// Returns ErrSweepTransactionFailed if it is reverted.
+func (s *Manager) sweepTxConfirmed(txId string) (confirmed bool, err error) {
+	tx, err := s.blockchainClient.TransferInfoByHash(txId)
+	if err != nil {
+		return false, err
+	}
+	if tx.InPool || !tx.Confirmed {
+		return false, nil
+	}
+	if !tx.Success {
+		return false, ErrSweepTransactionFailed
+	}
+	return true, nil
+}
+
+// sweepTaskSave persists the sweep task.
+func (s *Manager) sweepTaskSave(task *SweepTask) (err error) {
+	s.transactionPool.Do(func(db *badgerhold.Store) {
+		err = db.Upsert(task.Id, task)
+	})
+	return err
+}
+
+// sweepTaskDelete removes the finished sweep task.
+func (s *Manager) sweepTaskDelete(task *SweepTask) (err error) {
+	s.transactionPool.Do(func(db *badgerhold.Store) {
+		err = db.Delete(task.Id, new(SweepTask))
+		if errors.Is(err, badgerhold.ErrNotFound) {
+			err = nil
+		}
+	})
+	return err
+}
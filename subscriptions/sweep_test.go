@@ -0,0 +1,105 @@
+package subscriptions
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ITProLabDev/ethbacknode/crypto"
+	"github.com/ITProLabDev/ethbacknode/types"
+)
+
+func (c *testChain) GasPrice() (*big.Int, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.gasPrice, nil
+}
+
+func (c *testChain) TransferTokenGetMaxFee(from, to string, amount *big.Int, token string) (*big.Int, error) {
+	return big.NewInt(21000), nil
+}
+
+func (c *testChain) TransferBySigner(signer crypto.Signer, to string, amount *big.Int) (string, error) {
+	return c.send(to, "ETH")
+}
+
+func (c *testChain) TransferAllTokenBySigner(signer crypto.Signer, to string, token string) (string, error) {
+	return c.send(to, token)
+}
+
+// send records the transfer and returns its id.
+func (c *testChain) send(to, asset string) (string, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.sent = append(c.sent, to+" "+asset)
+	return testTransferId(byte(100 + len(c.sent))), nil
+}
+
+// The funding and the token transfer of a sweep task are sent only while the gas
+// price is within the MaxGasPrice of the policy, otherwise the task stays pending.
+func TestSweepTaskStart_MaxGasPrice(t *testing.T) {
+	tests := []struct {
+		name       string
+		gasPrice   int64
+		balance    int64
+		status     string
+		wantStatus string
+		// wantSent is the recipient of the transfer sent, "deposit" or "master"
+		wantSent string
+	}{
+		{"funding above the cap", 101, 0, SweepStatusPending, SweepStatusPending, ""},
+		{"funding within the cap", 100, 0, SweepStatusPending, SweepStatusFunding, "deposit"},
+		{"transfer above the cap after funding", 101, 21000, SweepStatusFunding, SweepStatusPending, ""},
+		{"transfer within the cap after funding", 100, 21000, SweepStatusFunding, SweepStatusSweeping, "master"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := newTestChain()
+			s, _ := newTestManager(t, chain)
+			deposit := testSigningAddress(t, s, 0x11, 1)
+			gasStation := testSigningAddress(t, s, 0x12, 1)
+			master := testAddress(t, s, 0x22, 1)
+			s.subscribersMux.Lock()
+			serviceInfo := s.subscribers[1]
+			serviceInfo.GasStation = gasStation
+			serviceInfo.MasterList = []string{master}
+			serviceInfo.SweepPolicy = &SweepPolicy{MaxGasPrice: big.NewInt(100)}
+			s.subscribersMux.Unlock()
+			chain.mux.Lock()
+			chain.gasPrice = big.NewInt(tt.gasPrice)
+			chain.balances[deposit+":USDT"] = big.NewInt(5)
+			chain.balances[deposit] = big.NewInt(tt.balance)
+			chain.transfers[testTransferId(1)] = &types.TransferInfo{TxID: testTransferId(1), Confirmed: true, Success: true}
+			chain.mux.Unlock()
+
+			task := &SweepTask{
+				Id:        sweepTaskId(deposit, "USDT"),
+				ServiceId: 1,
+				Address:   deposit,
+				Token:     "USDT",
+				Master:    master,
+				Status:    tt.status,
+				GasTxID:   testTransferId(1),
+			}
+			done, err := s.sweepTaskStep(task)
+			if err != nil || done {
+				t.Fatalf("sweepTaskStep: done %v, %v", done, err)
+			}
+			if task.Status != tt.wantStatus {
+				t.Fatalf("status: got %s, want %s", task.Status, tt.wantStatus)
+			}
+			chain.mux.Lock()
+			defer chain.mux.Unlock()
+			var want []string
+			switch tt.wantSent {
+			case "deposit":
+				want = []string{deposit + " ETH"}
+			case "master":
+				want = []string{master + " USDT"}
+			}
+			if fmt.Sprint(chain.sent) != fmt.Sprint(want) {
+				t.Fatalf("sent: got %v, want %v", chain.sent, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package subscriptions
+
+import (
+	"math/big"
+	"time"
+)
+
+// Distribution modes of swept funds across the service master addresses.
+const (
+	SweepDistributionFirst      = "first"
+	SweepDistributionRoundRobin = "roundRobin"
+	SweepDistributionWeighted   = "weighted"
+)
+
+const (
+	// defaultSweepSchedule is the sweep schedule of services without a policy schedule.
+	defaultSweepSchedule = "*/10 * * * *"
+	// sweepSchedulerInterval is the interval of the sweep schedules check.
+	sweepSchedulerInterval = time.Minute
+)
+
+// SweepPolicy controls when and where the funds of the service addresses are gathered.
+// MinBalance holds the minimum balance to sweep per asset symbol, in base units.
+// MaxGasPrice skips the sweep while the network gas price is higher, the token sweep
+// tasks stay pending until it drops.
+// MaxFeePercent skips native coin sweeps with a fee above the percent of the amount.
+// Schedule is a cron-like expression of the sweep times, see parseSchedule.
+// MasterWeights are the weights of the master addresses for the weighted distribution.
+type SweepPolicy struct {
+	MinBalance    map[string]*big.Int `json:"minBalance,omitempty"`
+	MaxGasPrice   *big.Int            `json:"maxGasPrice,omitempty"`
+	MaxFeePercent float64             `json:"maxFeePercent,omitempty"`
+	Schedule      string              `json:"schedule,omitempty"`
+	Distribution  string              `json:"distribution,omitempty"`
+	MasterWeights map[string]int      `json:"masterWeights,omitempty"`
+}
+
+// Validate checks the schedule and the distribution mode of the policy.
+func (p *SweepPolicy) Validate() error {
+	if p.Schedule != "" {
+		_, err := parseSchedule(p.Schedule)
+		if err != nil {
+			return err
+		}
+	}
+	switch p.Distribution {
+	case "", SweepDistributionFirst, SweepDistributionRoundRobin, SweepDistributionWeighted:
+	default:
+		return ErrInvalidSweepDistribution
+	}
+	if p.MaxFeePercent < 0 {
+		return ErrInvalidSweepPolicy
+	}
+	for _, weight := range p.MasterWeights {
+		if weight < 0 {
+			return ErrInvalidSweepPolicy
+		}
+	}
+	return nil
+}
+
+// belowMinBalance reports whether the balance of the asset is too small to sweep.
+func (p *SweepPolicy) belowMinBalance(symbol string, balance *big.Int) bool {
+	if balance == nil || balance.Sign() <= 0 {
+		return true
+	}
+	minBalance, found := p.MinBalance[symbol]
+	return found && minBalance != nil && balance.Cmp(minBalance) < 0
+}
+
+// feeTooHigh reports whether the fee exceeds MaxFeePercent of the amount.
+func (p *SweepPolicy) feeTooHigh(fee, amount *big.Int) bool {
+	if p.MaxFeePercent <= 0 {
+		return false
+	}
+	if amount.Sign() <= 0 {
+		return true
+	}
+	percent := new(big.Float).Quo(new(big.Float).SetInt(fee), new(big.Float).SetInt(amount))
+	percent.Mul(percent, big.NewFloat(100))
+	return percent.Cmp(big.NewFloat(p.MaxFeePercent)) > 0
+}
+
+// masterSelector keeps the state of the master address distribution of a service.
+type masterSelector struct {
+	next    int
+	current map[string]int
+}
+
+// sweepMaster returns the master address to sweep the next address to.
+// Weighted distribution uses the smooth weighted round-robin, masters without
+// a positive weight are skipped.
+func (s *Manager) sweepMaster(serviceInfo *Subscription, policy *SweepPolicy) string {
+	masters := serviceInfo.MasterList
+	selector, found := s.sweepSelectors[serviceInfo.ServiceId]
+	if !found {
+		selector = &masterSelector{current: make(map[string]int)}
+		s.sweepSelectors[serviceInfo.ServiceId] = selector
+	}
+	switch policy.Distribution {
+	case SweepDistributionRoundRobin:
+		master := masters[selector.next%len(masters)]
+		selector.next++
+		return master
+	case SweepDistributionWeighted:
+		total := 0
+		best := ""
+		for _, master := range masters {
+			weight := policy.MasterWeights[master]
+			if weight <= 0 {
+				continue
+			}
+			selector.current[master] += weight
+			total += weight
+			if best == "" || selector.current[master] > selector.current[best] {
+				best = master
+			}
+		}
+		if best == "" {
+			return masters[0]
+		}
+		selector.current[best] -= total
+		return best
+	default:
+		return masters[0]
+	}
+}
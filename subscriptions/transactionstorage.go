@@ -29,6 +29,32 @@ func (s *Manager) saveTransaction(tx *TransferInfoRecord) (err error) {
 	})
 	return err
 }
+// deleteTransaction removes a transaction record from storage.
+func (s *Manager) deleteTransaction(txId string) (err error) {
+	s.transactionPool.Do(func(db *badgerhold.Store) {
+		err = db.Delete(txId, new(TransferInfoRecord))
+		if errors.Is(err, badgerhold.ErrNotFound) {
+			err = nil
+		}
+	})
+	return err
+}
+
+// SearchTransactionsInBlock finds transactions included in the given block.
+// Used to drop transactions of blocks reverted by a chain reorganization.
+func (s *Manager) SearchTransactionsInBlock(blockNum int) (txList []*TransferInfoRecord, err error) {
+	s.transactionPool.Do(func(db *badgerhold.Store) {
+		err = db.Find(&txList, badgerhold.Where(
+			"BlockNum").
+			Eq(blockNum).
+			And("InPool").Eq(false))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return txList, nil
+}
+
 // SearchTransactionsBeforeBlock finds unconfirmed transactions in blocks before the given number.
 // Used to detect transactions that have reached confirmation threshold.
 func (s *Manager) SearchTransactionsBeforeBlock(blockNum int) (txList []*TransferInfoRecord, err error) {
@@ -64,6 +90,8 @@ func (s *Manager) SearchTransactionsAfterBlock(blockNum int) (txList []*Transfer
 
 // TransferInfoRecord is the persistent storage format for transaction data.
 // Stored in BadgerHold with indexed fields for efficient queries.
+// Status is set when a mempool transaction is dropped or replaced, SeenAt and
+// SeenBlock are the time and the block the transaction was first seen in the mempool.
 type TransferInfoRecord struct {
 	TxID              string   `json:"tx_id" badgerhold:"key"`
 	Timestamp         int64    `json:"timestamp"`
@@ -83,6 +111,11 @@ type TransferInfoRecord struct {
 	InPool            bool     `json:"inPool"`
 	Confirmed         bool     `json:"confirmed" badgerhold:"index"`
 	ChainSpecificData []byte   `json:"chainSpecificData,omitempty"`
+	Status            string   `json:"status,omitempty"`
+	ReplacedBy        string   `json:"replacedBy,omitempty"`
+	SeenAt            int64    `json:"seenAt,omitempty"`
+	SeenBlock         int64    `json:"seenBlock,omitempty"`
+	CheckedAt         int64    `json:"checkedAt,omitempty"`
 }
 
 // fillFromTransferInfo populates the record from a TransferInfo struct.
@@ -158,7 +191,7 @@ func (t *TransferInfoRecord) isEqual(tx *types.TransferInfo) bool {
 	if t.To != tx.To {
 		return false
 	}
-	if t.Amount.Cmp(tx.Amount) != 0 {
+	if !bigEqual(t.Amount, tx.Amount) {
 		return false
 	}
 	if t.Token != tx.Token {
@@ -167,7 +200,7 @@ func (t *TransferInfoRecord) isEqual(tx *types.TransferInfo) bool {
 	if t.TokenSymbol != tx.TokenSymbol {
 		return false
 	}
-	if t.Fee.Cmp(tx.Fee) != 0 {
+	if !bigEqual(t.Fee, tx.Fee) {
 		return false
 	}
 	if t.InPool != tx.InPool {
@@ -175,3 +208,11 @@ func (t *TransferInfoRecord) isEqual(tx *types.TransferInfo) bool {
 	}
 	return true
 }
+
+// bigEqual reports whether the amounts are equal, a nil amount equals only a nil one.
+func bigEqual(a, b *big.Int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Cmp(b) == 0
+}
@@ -1,18 +1,23 @@
 package txcache
 
 import (
+	"errors"
 	"github.com/ITProLabDev/ethbacknode/tools/log"
 	"github.com/ITProLabDev/ethbacknode/types"
 	"github.com/timshannon/badgerhold"
 	"sort"
 )
 
-// GetTransferInfo retrieves a cached transaction by its hash.
+// GetTransferInfo retrieves a cached transaction by its transfer ID or hash.
+// For a transaction hash carrying several transfers the first one is returned.
 // Returns ErrUnknownTransaction if not found.
 func (m *Manager) GetTransferInfo(txHash string) (tx *types.TransferInfo, err error) {
 	m.mux.RLock()
 	defer m.mux.RUnlock()
 	txRecord, err := m.getTransactionById(txHash)
+	if errors.Is(err, ErrUnknownTransaction) {
+		txRecord, err = m.getTransactionByHash(txHash)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -46,7 +51,7 @@ func (m *Manager) TransactionEvent(transactionInfo *types.TransferInfo) {
 		log.Debug("TxCache: transaction event", transactionInfo.TxID)
 		log.Dump(transactionInfo)
 	}
-	m.eventPipe <- func() {
+	m.pushEvent(func() {
 		m.mux.Lock()
 		defer m.mux.Unlock()
 		transactionInfoStatic := new(TransferInfoCachedRecord)
@@ -58,6 +63,22 @@ func (m *Manager) TransactionEvent(transactionInfo *types.TransferInfo) {
 		if err != nil {
 			log.Error("TxCache: can not save transaction info:", err)
 		}
+		if transactionInfoStatic.TxHash != transactionInfoStatic.TxID && !transactionInfoStatic.InPool {
+			m.replaceMempoolRecord(transactionInfoStatic.TxHash)
+		}
+	})
+}
+
+// replaceMempoolRecord drops the mempool token transfer decoded from the call data
+// of the transaction, the mined transfers are cached from logs under their own IDs.
+func (m *Manager) replaceMempoolRecord(txHash string) {
+	txRecord, err := m.getTransactionById(txHash)
+	if err != nil || !txRecord.InPool || !txRecord.SmartContract || txRecord.NativeCoin {
+		return
+	}
+	err = m.deleteTransaction(txHash)
+	if err != nil {
+		log.Error("TxCache: can not delete mempool transaction info:", err)
 	}
 }
 
@@ -66,9 +87,20 @@ func (m *Manager) BlockEvent(blockNum int64, blockId string) {
 	if m.config.Debug {
 		log.Debug("TxCache: block event", blockNum)
 	}
-	m.eventPipe <- func() {
+	m.pushEvent(func() {
 		m.blockUpdateEvent(blockNum)
+	})
+}
+
+// BlockRevertedEvent handles a block reverted by a chain reorganization.
+// Drops cached transactions of the reverted block.
+func (m *Manager) BlockRevertedEvent(blockNum int64, blockId string) {
+	if m.config.Debug {
+		log.Debug("TxCache: block reverted event", blockNum, blockId)
 	}
+	m.pushEvent(func() {
+		m.blockRevertEvent(blockNum)
+	})
 }
 
 // sortTransferInfo implements sort.Interface for TransferInfo slices by timestamp.
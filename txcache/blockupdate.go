@@ -34,3 +34,18 @@ func (m *Manager) blockUpdateEvent(blockNum int64) {
 		}
 	}
 }
+
+// blockRevertEvent removes transactions included in a block orphaned by a reorg.
+// Transactions re-included in the canonical chain are cached again by transaction events.
+func (m *Manager) blockRevertEvent(blockNum int64) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	var err error
+	m.txCache.Do(func(db *badgerhold.Store) {
+		query := badgerhold.Where("BlockNum").Eq(int(blockNum)).And("InPool").Eq(false)
+		err = db.DeleteMatching(new(TransferInfoCachedRecord), query)
+	})
+	if err != nil {
+		log.Error("TxCache: can not drop reverted transactions:", err)
+	}
+}
@@ -4,7 +4,9 @@
 package txcache
 
 import (
+	"context"
 	"github.com/ITProLabDev/ethbacknode/storage"
+	"github.com/ITProLabDev/ethbacknode/tools/log"
 	"sync"
 )
 
@@ -17,6 +19,8 @@ func NewManager(options ...ManagerOption) (*Manager, error) {
 	manager := &Manager{
 		config:    NewConfig(),
 		eventPipe: make(chan func(), 16),
+		quit:      make(chan struct{}),
+		loopDone:  make(chan struct{}),
 	}
 	for _, opt := range options {
 		err := opt(manager)
@@ -39,14 +43,51 @@ type Manager struct {
 	txCache   *storage.BadgerHoldStorage
 	eventPipe chan func()
 	mux       sync.RWMutex
+	quit      chan struct{}
+	stopOnce  sync.Once
+	loopDone  chan struct{}
 }
 
 // eventLoop processes events from the event pipe sequentially.
+// When the manager is stopped, the queued events are processed before exit.
 func (m *Manager) eventLoop() {
+	defer close(m.loopDone)
 	for {
 		select {
 		case event := <-m.eventPipe:
 			event()
+		case <-m.quit:
+			for {
+				select {
+				case event := <-m.eventPipe:
+					event()
+				default:
+					return
+				}
+			}
 		}
 	}
 }
+
+// pushEvent queues the event for the event loop. The event is dropped if the manager is stopped.
+func (m *Manager) pushEvent(event func()) {
+	select {
+	case m.eventPipe <- event:
+	case <-m.quit:
+		log.Warning("TxCache: manager stopped, event dropped")
+	}
+}
+
+// Stop stops the event loop after the queued events are processed.
+// Returns ctx.Err() if the manager does not stop before the context is done.
+func (m *Manager) Stop(ctx context.Context) (err error) {
+	m.stopOnce.Do(func() {
+		close(m.quit)
+	})
+	select {
+	case <-m.loopDone:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -9,6 +9,7 @@ import (
 // Stored in BadgerHold with indexed fields for efficient queries.
 type TransferInfoCachedRecord struct {
 	TxID              string   `json:"txId" badgerhold:"key"`
+	TxHash            string   `json:"txHash,omitempty" badgerhold:"index"`
 	Timestamp         int64    `json:"timestamp"`
 	BlockNum          int      `json:"blockNum"`
 	Success           bool     `json:"success"`
@@ -32,6 +33,7 @@ type TransferInfoCachedRecord struct {
 // loadFromTransferInfo populates the record from a TransferInfo struct.
 func (r *TransferInfoCachedRecord) loadFromTransferInfo(info *types.TransferInfo) {
 	r.TxID = info.TxID
+	r.TxHash = info.TxHash()
 	r.Timestamp = info.Timestamp
 	r.BlockNum = info.BlockNum
 	r.Success = info.Success
@@ -20,6 +20,33 @@ func (m *Manager) getTransactionById(txId string) (tx *TransferInfoCachedRecord,
 	return tx, nil
 }
 
+// getTransactionByHash retrieves the first transfer of the transaction with the hash,
+// for transfers detected from logs or by call tracing the ID differs from the hash.
+func (m *Manager) getTransactionByHash(txHash string) (tx *TransferInfoCachedRecord, err error) {
+	var txs []*TransferInfoCachedRecord
+	m.txCache.Do(func(db *badgerhold.Store) {
+		err = db.Find(&txs, badgerhold.Where("TxHash").Eq(txHash).Index("TxHash").SortBy("TxID").Limit(1))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(txs) == 0 {
+		return nil, ErrUnknownTransaction
+	}
+	return txs[0], nil
+}
+
+// deleteTransaction removes a transaction record from storage.
+func (m *Manager) deleteTransaction(txId string) (err error) {
+	m.txCache.Do(func(db *badgerhold.Store) {
+		err = db.Delete(txId, new(TransferInfoCachedRecord))
+		if errors.Is(err, badgerhold.ErrNotFound) {
+			err = nil
+		}
+	})
+	return err
+}
+
 // getTransactionsByAddress retrieves all transactions for an address.
 func (m *Manager) getTransactionsByAddress(address string) (txs []*TransferInfoCachedRecord, err error) {
 	m.txCache.Do(func(db *badgerhold.Store) {
@@ -4,6 +4,7 @@ package types
 
 import (
 	"github.com/ITProLabDev/ethbacknode/address"
+	"github.com/ITProLabDev/ethbacknode/crypto"
 	"math/big"
 )
 
@@ -43,6 +44,9 @@ type ChainClientTransactions interface {
 	TransferInfoByHash(txHash string) (tx *TransferInfo, err error)
 	// TransferInfoByNum retrieves a transaction by block number and transaction index.
 	TransferInfoByNum(blockNum int64, txIndex int) (tx *TransferInfo, err error)
+	// TransactionBlockNum returns the number of the block including the transaction
+	// of any kind, 0 if the transaction is in the mempool.
+	TransactionBlockNum(txHash string) (blockNum int64, err error)
 }
 
 // ChainClientBalances provides balance query operations for addresses.
@@ -51,6 +55,16 @@ type ChainClientBalances interface {
 	BalanceOf(address string) (balance *big.Int, err error)
 	// TokensBalanceOf returns the token balance of an address for a specific token.
 	TokensBalanceOf(address string, token string) (balance *big.Int, err error)
+	// BalanceAt returns the native coin balance of an address at the given block.
+	BalanceAt(address string, blockNum int64) (balance *big.Int, err error)
+	// TokensBalanceAt returns the token balance of an address at the given block.
+	TokensBalanceAt(address string, token string, blockNum int64) (balance *big.Int, err error)
+}
+
+// ChainClientFees provides network fee information.
+type ChainClientFees interface {
+	// GasPrice returns the current price per gas unit in the smallest coin units.
+	GasPrice() (gasPrice *big.Int, err error)
 }
 
 // ChainClientCoinTransfer provides native coin transfer operations.
@@ -71,6 +85,43 @@ type ChainClientTokenTransfer interface {
 	TransferAllTokenByPrivateKey(fromPrivateKey []byte, from, to string, token string) (txHash string, err error)
 	// TransferTokenGetEstimatedFee estimates the fee for a token transfer.
 	TransferTokenGetEstimatedFee(from, to string, amount *big.Int, token string) (fee *big.Int, err error)
+	// TransferTokenGetMaxFee returns the maximum fee of a token transfer, the native coin balance required to send it.
+	TransferTokenGetMaxFee(from, to string, amount *big.Int, token string) (fee *big.Int, err error)
+}
+
+// ChainClientTransferReplace provides replacement of pending outgoing transactions.
+type ChainClientTransferReplace interface {
+	// PendingTransferFrom returns the sender of a pending outgoing transaction.
+	PendingTransferFrom(txHash string) (from string, err error)
+	// SpeedUpByPrivateKey replaces a pending transaction with the same one sent with a higher fee.
+	SpeedUpByPrivateKey(fromPrivateKey []byte, txHash string) (newTxHash string, err error)
+	// CancelByPrivateKey replaces a pending transaction with a 0-value self-send with a higher fee.
+	CancelByPrivateKey(fromPrivateKey []byte, txHash string) (newTxHash string, err error)
+}
+
+// ChainClientSignerTransfer provides transfers signed by a crypto.Signer, so the
+// sending key may be held outside the process. The sender is the signer address.
+type ChainClientSignerTransfer interface {
+	// TransferBySigner sends native coins to another address.
+	TransferBySigner(signer crypto.Signer, to string, amount *big.Int) (txHash string, err error)
+	// TransferAllBySigner sends the entire balance (minus fees) to another address.
+	TransferAllBySigner(signer crypto.Signer, to string) (txHash string, err error)
+	// TransferTokenBySigner sends tokens to another address.
+	TransferTokenBySigner(signer crypto.Signer, to string, amount *big.Int, token string) (txHash string, err error)
+	// TransferAllTokenBySigner sends the entire token balance to another address.
+	TransferAllTokenBySigner(signer crypto.Signer, to string, token string) (txHash string, err error)
+	// SpeedUpBySigner replaces a pending transaction with the same one sent with a higher fee.
+	SpeedUpBySigner(signer crypto.Signer, txHash string) (newTxHash string, err error)
+	// CancelBySigner replaces a pending transaction with a 0-value self-send with a higher fee.
+	CancelBySigner(signer crypto.Signer, txHash string) (newTxHash string, err error)
+}
+
+// ChainClientTransferStatus provides the status of transactions seen in the mempool.
+type ChainClientTransferStatus interface {
+	// PendingTransferStatus reports whether a transaction seen in the mempool is still pending,
+	// mined, dropped or replaced. For a replaced transaction the hash of the replacing one is
+	// returned if it can be found. sinceBlock is the block the transaction was first seen at, 0 if unknown.
+	PendingTransferStatus(tx *TransferInfo, sinceBlock int64) (status string, replacedBy string, err error)
 }
 
 // ChainClient is the main interface for blockchain interaction.
@@ -81,8 +132,12 @@ type ChainClient interface {
 	ChainClientBlocks
 	ChainClientTransactions
 	ChainClientBalances
+	ChainClientFees
 	ChainClientCoinTransfer
 	ChainClientTokenTransfer
+	ChainClientTransferReplace
+	ChainClientSignerTransfer
+	ChainClientTransferStatus
 }
 
 // TxCache provides cached transaction lookups.
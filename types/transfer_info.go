@@ -2,12 +2,27 @@ package types
 
 import (
 	"math/big"
+	"strings"
+)
+
+// Statuses of a transaction seen in the mempool, reported by ChainClientTransferStatus.
+const (
+	// TransferStatusPending means the transaction is still in the mempool.
+	TransferStatusPending = "pending"
+	// TransferStatusMined means the transaction is included in a block.
+	TransferStatusMined = "mined"
+	// TransferStatusDropped means the transaction left the mempool without being mined.
+	TransferStatusDropped = "dropped"
+	// TransferStatusReplaced means another transaction of the sender with the same nonce
+	// was mined or replaced the transaction in the mempool.
+	TransferStatusReplaced = "replaced"
 )
 
 // TransferInfo represents a blockchain transaction/transfer with all relevant details.
 // It supports both native coin transfers and ERC-20 token transfers.
 type TransferInfo struct {
-	// TxID is the transaction hash (e.g., 0x...).
+	// TxID is the transfer ID: the transaction hash (e.g., 0x...), followed by
+	// the log or call index for transfers detected from logs or call tracing.
 	TxID string `json:"txId"`
 	// Timestamp is the Unix timestamp of the transaction.
 	Timestamp int64 `json:"timestamp"`
@@ -56,3 +71,14 @@ type DataDecoder func([]byte) error
 func (t *TransferInfo) DecodeChainSpecificData(decoder DataDecoder) error {
 	return decoder(t.ChainSpecificData)
 }
+
+// TxHash returns the hash of the transaction carrying the transfer.
+// Several transfers of a transaction share it.
+func (t *TransferInfo) TxHash() string {
+	return TransferTxHash(t.TxID)
+}
+
+// TransferTxHash returns the transaction hash part of the transfer ID.
+func TransferTxHash(txId string) string {
+	return strings.SplitN(txId, ":", 2)[0]
+}
@@ -6,4 +6,12 @@ import "errors"
 var (
 	// ErrInvalidBalanceResponse is returned when balance response format is invalid.
 	ErrInvalidBalanceResponse = errors.New("invalid balance response")
+	// ErrNotificationNotSigned is returned when the notification signature headers are missing.
+	ErrNotificationNotSigned = errors.New("notification is not signed")
+	// ErrNotificationSignature is returned when the notification signature does not match.
+	ErrNotificationSignature = errors.New("invalid notification signature")
+	// ErrNotificationExpired is returned when the notification timestamp is out of the accepted window.
+	ErrNotificationExpired = errors.New("notification signature expired")
+	// ErrNotificationReplayed is returned when the same signed notification request was already accepted.
+	ErrNotificationReplayed = errors.New("notification replayed")
 )
@@ -0,0 +1,116 @@
+package uniclient
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Notification delivery headers set by the node on every webhook request.
+const (
+	HeaderSignature          = "X-Signature"
+	HeaderSignatureTimestamp = "X-Signature-Timestamp"
+	HeaderDeliveryId         = "X-Delivery-Id"
+)
+
+// DefaultNotificationMaxAge is the default accepted age of a notification signature.
+const DefaultNotificationMaxAge = 5 * time.Minute
+
+// NotificationVerifier checks the signature of webhook notifications sent by the node
+// and rejects expired and replayed requests.
+// It remembers the signed requests seen within the max age window.
+type NotificationVerifier struct {
+	key    []byte
+	maxAge time.Duration
+	mux    sync.Mutex
+	seen   map[string]int64
+}
+
+// NewNotificationVerifier creates a verifier for the service key, the ApiKey decoded
+// according to the key format of the node (hex by default), the same key signs the
+// requests of the service. A zero maxAge uses DefaultNotificationMaxAge.
+func NewNotificationVerifier(key []byte, maxAge time.Duration) *NotificationVerifier {
+	if maxAge <= 0 {
+		maxAge = DefaultNotificationMaxAge
+	}
+	return &NotificationVerifier{
+		key:    key,
+		maxAge: maxAge,
+		seen:   make(map[string]int64),
+	}
+}
+
+// VerifyRequest verifies the webhook request and returns its body.
+// The request body is restored, so it can be decoded after the check.
+func (v *NotificationVerifier) VerifyRequest(r *http.Request) (body []byte, err error) {
+	body, err = io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	_ = r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, v.Verify(r.Header, body)
+}
+
+// Verify checks the signature headers against the exact request body.
+// Returns ErrNotificationReplayed if the same signed request was already accepted.
+// Retries of a notification are signed again and keep the delivery id, which the
+// caller uses to detect notifications already processed.
+func (v *NotificationVerifier) Verify(header http.Header, body []byte) error {
+	timestamp := header.Get(HeaderSignatureTimestamp)
+	deliveryId := header.Get(HeaderDeliveryId)
+	signature := header.Get(HeaderSignature)
+	if timestamp == "" || deliveryId == "" || signature == "" {
+		return ErrNotificationNotSigned
+	}
+	signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrNotificationSignature
+	}
+	if !VerifyNotificationSignature(v.key, timestamp, deliveryId, body, signature) {
+		return ErrNotificationSignature
+	}
+	now := time.Now()
+	age := now.Sub(time.Unix(signedAt, 0))
+	if age > v.maxAge || age < -v.maxAge {
+		return ErrNotificationExpired
+	}
+	v.mux.Lock()
+	defer v.mux.Unlock()
+	for id, expire := range v.seen {
+		if expire < now.Unix() {
+			delete(v.seen, id)
+		}
+	}
+	request := timestamp + "." + deliveryId
+	if _, found := v.seen[request]; found {
+		return ErrNotificationReplayed
+	}
+	v.seen[request] = signedAt + int64(v.maxAge/time.Second) + 1
+	return nil
+}
+
+// NotificationSignature returns the hex encoded HMAC-SHA256 of "<timestamp>.<deliveryId>.<body>"
+// keyed by the decoded service ApiKey.
+func NotificationSignature(key []byte, timestamp, deliveryId string, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write([]byte(deliveryId))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyNotificationSignature reports whether the signature matches the notification
+// using a constant time comparison. It does not check the timestamp or replays.
+func VerifyNotificationSignature(key []byte, timestamp, deliveryId string, body []byte, signature string) bool {
+	expected := NotificationSignature(key, timestamp, deliveryId, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
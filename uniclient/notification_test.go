@@ -0,0 +1,56 @@
+package uniclient
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func signedNotification(key []byte, signedAt time.Time, deliveryId string, body []byte) *http.Request {
+	timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+	r, _ := http.NewRequest(http.MethodPost, "http://localhost/callback", bytes.NewReader(body))
+	r.Header.Set(HeaderSignatureTimestamp, timestamp)
+	r.Header.Set(HeaderDeliveryId, deliveryId)
+	r.Header.Set(HeaderSignature, NotificationSignature(key, timestamp, deliveryId, body))
+	return r
+}
+
+func TestNotificationVerifier(t *testing.T) {
+	body := []byte(`{"jsonrpc":"2.0","method":"transactionEvent","params":{"tx_id":"0x01"}}`)
+	verifier := NewNotificationVerifier([]byte("secret"), time.Minute)
+
+	r := signedNotification([]byte("secret"), time.Now(), "d1", body)
+	got, err := verifier.VerifyRequest(r)
+	if err != nil {
+		t.Fatal("valid notification rejected:", err)
+	}
+	if !bytes.Equal(got, body) {
+		t.Error("body not returned")
+	}
+	if err = verifier.Verify(r.Header, body); !errors.Is(err, ErrNotificationReplayed) {
+		t.Error("replay not detected:", err)
+	}
+	r = signedNotification([]byte("secret"), time.Now().Add(time.Second), "d1", body)
+	if err = verifier.Verify(r.Header, body); err != nil {
+		t.Error("retry with the same delivery id rejected:", err)
+	}
+	r = signedNotification([]byte("other"), time.Now(), "d2", body)
+	if err = verifier.Verify(r.Header, body); !errors.Is(err, ErrNotificationSignature) {
+		t.Error("wrong key accepted:", err)
+	}
+	r = signedNotification([]byte("secret"), time.Now(), "d3", body)
+	if err = verifier.Verify(r.Header, append(body, ' ')); !errors.Is(err, ErrNotificationSignature) {
+		t.Error("modified body accepted:", err)
+	}
+	r = signedNotification([]byte("secret"), time.Now().Add(-2*time.Minute), "d4", body)
+	if err = verifier.Verify(r.Header, body); !errors.Is(err, ErrNotificationExpired) {
+		t.Error("expired notification accepted:", err)
+	}
+	r.Header.Del(HeaderSignature)
+	if err = verifier.Verify(r.Header, body); !errors.Is(err, ErrNotificationNotSigned) {
+		t.Error("unsigned notification accepted:", err)
+	}
+}
@@ -15,6 +15,7 @@ type Config struct {
 	PullByExternalEvent bool  `json:"pullByExternalEvent"`
 	PullByTimer         bool  `json:"pullByTimer"`
 	Confirmations       int64 `json:"confirmations"`
+	ReorgWindow         int   `json:"reorgWindow"`
 	Debug               bool  `json:"debug"`
 }
 
@@ -55,7 +56,7 @@ func (c *Config) Save() (err error) {
 }
 
 // coldStart initializes the configuration with default values.
-// Sets Run=true, PullInterval=5 seconds, Confirmations=7.
+// Sets Run=true, PullInterval=5 seconds, Confirmations=7, ReorgWindow=64.
 func (c *Config) coldStart() (err error) {
 	if c.storage == nil {
 		return ErrConfigStorageEmpty
@@ -63,5 +64,6 @@ func (c *Config) coldStart() (err error) {
 	c.Run = true
 	c.PullInterval = 5
 	c.Confirmations = 7
+	c.ReorgWindow = defaultReorgWindow
 	return c.Save()
 }
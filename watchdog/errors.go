@@ -10,4 +10,24 @@ var (
 	ErrAddressPoolNotSet = errors.New("address pool not set")
 	// ErrChainClientNotSet is returned when the blockchain client is not configured.
 	ErrChainClientNotSet = errors.New("blockchain client not set")
+	// ErrChainReorg is returned when a block does not extend the last processed block.
+	ErrChainReorg = errors.New("chain reorganization detected")
+	// ErrServiceNotRunning is returned when a request is made before the service is started.
+	ErrServiceNotRunning = errors.New("watchdog service not running")
+	// ErrUnsupportedPullEvent is returned for pull event requests that are not supported.
+	ErrUnsupportedPullEvent = errors.New("unsupported pull event")
+	// ErrBlockNotCanonical is returned when a requested block is not in the canonical chain.
+	ErrBlockNotCanonical = errors.New("block is not in canonical chain")
+	// ErrBlockNotProcessed is returned when a requested block is not processed yet.
+	ErrBlockNotProcessed = errors.New("block not processed yet")
+	// ErrTransactionNotMined is returned when a rescan is requested for a transaction in the mempool.
+	ErrTransactionNotMined = errors.New("transaction not mined yet")
+	// ErrAddressNotKnown is returned when a rescan is requested for an address not managed by the service.
+	ErrAddressNotKnown = errors.New("address not known")
+	// ErrInvalidBlockRange is returned when a rescan range is empty or not processed yet.
+	ErrInvalidBlockRange = errors.New("invalid block range")
+	// ErrRescanJobNotFound is returned when a rescan job does not exist.
+	ErrRescanJobNotFound = errors.New("rescan job not found")
+	// ErrRescanQueueFull is returned when too many rescan jobs are waiting.
+	ErrRescanQueueFull = errors.New("rescan queue is full")
 )
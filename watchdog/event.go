@@ -5,18 +5,23 @@ import "github.com/ITProLabDev/ethbacknode/types"
 // BlockEvent is a callback function invoked when a new block is detected.
 type BlockEvent func(blockNum int64, blockId string)
 
+// BlockRevertedEvent is a callback function invoked when a previously reported
+// block has been orphaned by a chain reorganization.
+type BlockRevertedEvent func(blockNum int64, blockId string)
+
 // TransactionEvent is a callback function invoked when a transaction
 // involving a managed address is detected.
 type TransactionEvent func(transactionInfo *types.TransferInfo)
 
 // event is an internal event structure for the event queue.
 type event struct {
-	blockEvent       bool
-	transactionEvent bool
-	blockNum         int64
-	blockId          string
-	blockTime        int64
-	transaction      *types.TransferInfo
+	blockEvent         bool
+	blockRevertedEvent bool
+	transactionEvent   bool
+	blockNum           int64
+	blockId            string
+	blockTime          int64
+	transaction        *types.TransferInfo
 }
 
 // RegisterBlockEventListen adds a handler to be called when new blocks are detected.
@@ -24,23 +29,35 @@ func (w *Service) RegisterBlockEventListen(handler BlockEvent) {
 	w.blockEventHandlers = append(w.blockEventHandlers, handler)
 }
 
+// RegisterBlockRevertedEventListen adds a handler to be called when blocks are reverted by a reorg.
+func (w *Service) RegisterBlockRevertedEventListen(handler BlockRevertedEvent) {
+	w.blockRevertedHandlers = append(w.blockRevertedHandlers, handler)
+}
+
 // RegisterTransactionEventListen adds a handler to be called for transaction events.
 func (w *Service) RegisterTransactionEventListen(handler TransactionEvent) {
 	w.transactionHandlers = append(w.transactionHandlers, handler)
 }
 
 // eventLoop processes events from the internal queue and dispatches to handlers.
-// Runs as a goroutine, invoking handlers asynchronously.
+// Runs as a goroutine. Handlers of all event types are invoked in queue order, so
+// a block reverted event is never delivered before the transactions it reverts;
+// handlers must not block and should queue slow work to their own goroutines.
+// Exits when the queue is closed by Stop and all queued events are dispatched.
 func (w *Service) eventLoop() {
-	for {
-		event := <-w.events
+	defer w.handlers.Done()
+	for event := range w.events {
 		if event.blockEvent {
 			for _, h := range w.blockEventHandlers {
-				go h(event.blockNum, event.blockId)
+				h(event.blockNum, event.blockId)
+			}
+		} else if event.blockRevertedEvent {
+			for _, h := range w.blockRevertedHandlers {
+				h(event.blockNum, event.blockId)
 			}
 		} else if event.transactionEvent {
 			for _, h := range w.transactionHandlers {
-				go h(event.transaction)
+				h(event.transaction)
 			}
 		}
 	}
@@ -1,9 +1,15 @@
 package watchdog
 
-import "github.com/ITProLabDev/ethbacknode/tools/log"
+import (
+	"strings"
+
+	"github.com/ITProLabDev/ethbacknode/tools/log"
+)
 
 // processBlock retrieves a block by number and processes all its transactions.
-// Emits a block event and processes each transaction for managed addresses.
+// Verifies the block extends the last processed block, then emits a block event
+// and processes each transaction for managed addresses.
+// Returns ErrChainReorg if the block parent differs from the recorded hash.
 func (w *Service) processBlock(blockNum int64) (err error) {
 	if w.config.Debug {
 		log.Debug("Process block", blockNum)
@@ -17,6 +23,10 @@ func (w *Service) processBlock(blockNum int64) (err error) {
 	if err != nil {
 		return err
 	}
+	if parentId, found := w.state.GetBlockId(blockNum - 1); found && !strings.EqualFold(parentId, block.ParentHash) {
+		log.Warning("Block", blockNum, "parent", block.ParentHash, "does not match processed block", parentId)
+		return ErrChainReorg
+	}
 	w.events <- &event{
 		blockEvent: true,
 		blockNum:   blockNum,
@@ -28,5 +38,6 @@ func (w *Service) processBlock(blockNum int64) (err error) {
 			log.Error("process tx error", err)
 		}
 	}
+	w.state.PushBlock(blockNum, block.BlockID, w.config.ReorgWindow)
 	return nil
 }
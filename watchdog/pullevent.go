@@ -1,32 +1,117 @@
 package watchdog
 
+import (
+	"strings"
+
+	"github.com/ITProLabDev/ethbacknode/tools/log"
+)
+
 // PullEvent represents an external event request for transaction or block lookup.
 type PullEvent struct {
 	txEvent    bool
 	txId       string
 	blockEvent bool
 	blockId    string
+	// result of the request, set before done is closed
+	blockNum     int64
+	transactions int
+	err          error
+	done         chan struct{}
+}
+
+// PullBlockResult describes a block processed by an external pull request.
+type PullBlockResult struct {
+	BlockId      string `json:"blockId"`
+	BlockNum     int64  `json:"blockNum"`
+	Transactions int    `json:"transactions"`
 }
 
 //TODO: subscription to events for external modules
 
+// PullBlock forces re-processing of the block with the given hash: all its
+// transactions involving managed addresses are emitted again as transaction events.
+// The block must belong to the canonical chain. Blocks until the request is processed.
+func (w *Service) PullBlock(blockId string) (result *PullBlockResult, err error) {
+	if w.pullEventChannel == nil {
+		return nil, ErrServiceNotRunning
+	}
+	event := &PullEvent{
+		blockEvent: true,
+		blockId:    blockId,
+		done:       make(chan struct{}),
+	}
+	select {
+	case w.pullEventChannel <- event:
+	case <-w.quit:
+		return nil, ErrServiceNotRunning
+	}
+	<-event.done
+	if event.err != nil {
+		return nil, event.err
+	}
+	return &PullBlockResult{
+		BlockId:      blockId,
+		BlockNum:     event.blockNum,
+		Transactions: event.transactions,
+	}, nil
+}
+
 // pullEventWatcher listens for external pull event requests.
 // Processes events from the pull channel until the service is stopped.
 func (w *Service) pullEventWatcher() {
+	defer w.producers.Done()
 	for {
 		select {
 		case event := <-w.pullEventChannel:
 			w.processPullEvent(event)
-		case _, closed := <-w.quit:
-			if closed {
-				return
-			}
+		case <-w.quit:
+			return
 		}
 	}
 }
 
 // processPullEvent handles an external pull event request.
-// TODO: Implementation pending.
+// Holds the service lock, so the request does not interleave with the run loop.
 func (w *Service) processPullEvent(event *PullEvent) {
-	//todo
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	if event.blockEvent {
+		event.blockNum, event.transactions, event.err = w.processBlockByHash(event.blockId)
+	} else {
+		event.err = ErrUnsupportedPullEvent
+	}
+	if event.err != nil {
+		log.Error("Can not process pull event:", event.blockId, event.txId, event.err)
+	}
+	if event.done != nil {
+		close(event.done)
+	}
+}
+
+// processBlockByHash retrieves a block by hash and processes all its transactions
+// again. Orphaned blocks are rejected with ErrBlockNotCanonical, blocks not reached
+// by the run loop yet with ErrBlockNotProcessed. The watchdog state is not changed.
+func (w *Service) processBlockByHash(blockId string) (blockNum int64, transactions int, err error) {
+	block, err := w.client.BlockByHash(blockId, true)
+	if err != nil {
+		return 0, 0, err
+	}
+	blockNum = int64(block.Number)
+	if blockNum > w.state.GetState() {
+		return blockNum, 0, ErrBlockNotProcessed
+	}
+	canonical, err := w.client.BlockByNum(blockNum, false)
+	if err != nil {
+		return blockNum, 0, err
+	}
+	if !strings.EqualFold(canonical.BlockID, block.BlockID) {
+		return blockNum, 0, ErrBlockNotCanonical
+	}
+	log.Info("Reprocess block:", blockNum, block.BlockID)
+	for _, tx := range block.Transactions {
+		if err := w.processTx(tx); err != nil {
+			log.Error("process tx error", err)
+		}
+	}
+	return blockNum, len(block.Transactions), nil
 }
@@ -0,0 +1,43 @@
+package watchdog
+
+import (
+	"strings"
+
+	"github.com/ITProLabDev/ethbacknode/tools/log"
+)
+
+// defaultReorgWindow is the number of recent block hashes kept for reorg detection.
+const defaultReorgWindow = 64
+
+// rollback walks back from the given block number until the recorded hash
+// matches the canonical chain. Each orphaned block is reported with a block
+// reverted event (highest first) and the state is rewound to the common ancestor.
+// Blocks older than the recorded window are assumed canonical.
+func (w *Service) rollback(fromBlock int64) (err error) {
+	for blockNum := fromBlock; blockNum > 0; blockNum-- {
+		blockId, found := w.state.GetBlockId(blockNum)
+		if !found {
+			log.Warning("Reorg is deeper than recorded window, assume block", blockNum, "is canonical")
+			break
+		}
+		block, err := w.client.BlockByNum(blockNum, false)
+		if err != nil {
+			return err
+		}
+		if strings.EqualFold(block.BlockID, blockId) {
+			break
+		}
+		log.Warning("Revert block:", blockNum, blockId)
+		w.events <- &event{
+			blockRevertedEvent: true,
+			blockNum:           blockNum,
+			blockId:            blockId,
+		}
+		err = w.state.RewindTo(blockNum - 1)
+		if err != nil {
+			return err
+		}
+	}
+	log.Info("Chain reorganization resolved, common ancestor:", w.state.GetState())
+	return nil
+}
@@ -0,0 +1,109 @@
+package watchdog
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestRollback(t *testing.T) {
+	tests := []struct {
+		name string
+		// blocks of branch a processed before the fork
+		processed int64
+		window    int
+		// the chain is replaced from forkBlock with forkCount blocks of branch b
+		forkBlock int64
+		forkCount int
+		wantState int64
+		want      []string
+	}{
+		{
+			name:      "parent hash mismatch",
+			processed: 3,
+			window:    64,
+			forkBlock: 2,
+			forkCount: 3,
+			wantState: 1,
+			want:      []string{"reverted 3", "reverted 2"},
+		},
+		{
+			name:      "single block",
+			processed: 3,
+			window:    64,
+			forkBlock: 3,
+			forkCount: 2,
+			wantState: 2,
+			want:      []string{"reverted 3"},
+		},
+		{
+			name:      "deeper than window",
+			processed: 4,
+			window:    2,
+			forkBlock: 2,
+			forkCount: 4,
+			wantState: 2,
+			want:      []string{"reverted 4", "reverted 3"},
+		},
+	}
+	for _, tt := range tests {
+		chain := newTestChain()
+		chain.fork(1, int(tt.processed), "a")
+		for blockNum := int64(1); blockNum <= tt.processed; blockNum++ {
+			chain.addTransfer(blockNum, fmt.Sprintf("0x%d", blockNum), testAddress(9), testAddress(1))
+		}
+		w := newTestService(t, chain, tt.window, map[byte]int{1: 1})
+		for blockNum := int64(1); blockNum <= tt.processed; blockNum++ {
+			if err := w.processBlock(blockNum); err != nil {
+				t.Fatalf("%s: processBlock(%d): %v", tt.name, blockNum, err)
+			}
+			w.state.LastBlockNum = blockNum
+		}
+		queuedEvents(w)
+
+		chain.fork(tt.forkBlock, tt.forkCount, "b")
+		next := tt.processed + 1
+		if err := w.processBlock(next); !errors.Is(err, ErrChainReorg) {
+			t.Fatalf("%s: processBlock(%d): got %v, want ErrChainReorg", tt.name, next, err)
+		}
+		if err := w.rollback(next - 1); err != nil {
+			t.Fatalf("%s: rollback: %v", tt.name, err)
+		}
+		if got := queuedEvents(w); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: events: got %v, want %v", tt.name, got, tt.want)
+		}
+		if w.state.GetState() != tt.wantState {
+			t.Errorf("%s: state: got %d, want %d", tt.name, w.state.GetState(), tt.wantState)
+		}
+		// the new branch is processed from the common ancestor
+		for blockNum := tt.wantState + 1; blockNum < tt.forkBlock+int64(tt.forkCount); blockNum++ {
+			if err := w.processBlock(blockNum); err != nil {
+				t.Fatalf("%s: processBlock(%d) after rollback: %v", tt.name, blockNum, err)
+			}
+		}
+	}
+}
+
+func TestRollback_RevertedAfterBlockTransfers(t *testing.T) {
+	chain := newTestChain()
+	chain.fork(1, 2, "a")
+	chain.addTransfer(2, "0x2", testAddress(9), testAddress(1))
+	w := newTestService(t, chain, 64, map[byte]int{1: 1})
+	for blockNum := int64(1); blockNum <= 2; blockNum++ {
+		if err := w.processBlock(blockNum); err != nil {
+			t.Fatalf("processBlock(%d): %v", blockNum, err)
+		}
+		w.state.LastBlockNum = blockNum
+	}
+	chain.fork(2, 2, "b")
+	if err := w.processBlock(3); !errors.Is(err, ErrChainReorg) {
+		t.Fatalf("processBlock(3): got %v, want ErrChainReorg", err)
+	}
+	if err := w.rollback(2); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	want := []string{"block 1", "block 2", "tx 2 0x2", "reverted 2"}
+	if got := queuedEvents(w); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("events: got %v, want %v", got, want)
+	}
+}
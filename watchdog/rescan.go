@@ -0,0 +1,313 @@
+package watchdog
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ITProLabDev/ethbacknode/tools/log"
+	"github.com/ITProLabDev/ethbacknode/types"
+)
+
+// Rescan job kinds.
+const (
+	RescanKindBlocks      = "blocks"
+	RescanKindTransaction = "transaction"
+	RescanKindAddress     = "address"
+)
+
+// Rescan job statuses.
+const (
+	RescanStatusPending   = "pending"
+	RescanStatusRunning   = "running"
+	RescanStatusDone      = "done"
+	RescanStatusFailed    = "failed"
+	RescanStatusCancelled = "cancelled"
+)
+
+const (
+	// maxRescanJobs is the number of jobs kept in memory, oldest finished jobs are dropped.
+	maxRescanJobs = 100
+	// rescanQueueSize is the number of jobs that may wait for the worker.
+	rescanQueueSize = 16
+)
+
+// RescanJob is a background rescan of already processed blocks started by a service.
+// Transactions involving addresses of the service found by the job are emitted again
+// as transaction events.
+type RescanJob struct {
+	Id           int64  `json:"jobId"`
+	ServiceId    int    `json:"serviceId"`
+	Kind         string `json:"kind"`
+	FromBlock    int64  `json:"fromBlock"`
+	ToBlock      int64  `json:"toBlock"`
+	TxId         string `json:"txId,omitempty"`
+	Address      string `json:"address,omitempty"`
+	Status       string `json:"status"`
+	CurrentBlock int64  `json:"currentBlock"`
+	Progress     int    `json:"progress"`
+	Found        int    `json:"found"`
+	Error        string `json:"error,omitempty"`
+	CreatedAt    int64  `json:"createdAt"`
+	FinishedAt   int64  `json:"finishedAt,omitempty"`
+	cancel       atomic.Bool
+}
+
+// rescanJobs keeps the rescan jobs and runs them one by one.
+type rescanJobs struct {
+	mux    sync.Mutex
+	lastId int64
+	jobs   []*RescanJob
+	queue  chan *RescanJob
+}
+
+// RescanBlocks starts a background rescan of the processed blocks in the given range,
+// only the transfers of the service addresses are emitted. The range end is limited
+// by the last processed block.
+func (w *Service) RescanBlocks(serviceId int, fromBlock, toBlock int64) (job *RescanJob, err error) {
+	return w.rescanStart(&RescanJob{
+		ServiceId: serviceId,
+		Kind:      RescanKindBlocks,
+		FromBlock: fromBlock,
+		ToBlock:   toBlock,
+	})
+}
+
+// RescanTransaction starts a background rescan of the block containing the transaction,
+// only the transfers of this transaction involving the service addresses are emitted.
+// The block is taken from the transaction itself, so router calls, transferFrom and
+// contract calls with internal transfers, which are not transfers by their call data,
+// are rescanned as well.
+func (w *Service) RescanTransaction(serviceId int, txId string) (job *RescanJob, err error) {
+	blockNum, err := w.client.TransactionBlockNum(txId)
+	if err != nil {
+		return nil, err
+	}
+	if blockNum == 0 {
+		return nil, ErrTransactionNotMined
+	}
+	return w.rescanStart(&RescanJob{
+		ServiceId: serviceId,
+		Kind:      RescanKindTransaction,
+		FromBlock: blockNum,
+		ToBlock:   blockNum,
+		TxId:      txId,
+	})
+}
+
+// RescanAddress starts a background rescan of the processed blocks from the given
+// block up to the last processed block, only the transfers of the address are emitted.
+// Returns ErrAddressNotKnown unless the address belongs to the service.
+func (w *Service) RescanAddress(serviceId int, address string, fromBlock int64) (job *RescanJob, err error) {
+	if !w.ownedBy(address, serviceId) {
+		return nil, ErrAddressNotKnown
+	}
+	return w.rescanStart(&RescanJob{
+		ServiceId: serviceId,
+		Kind:      RescanKindAddress,
+		FromBlock: fromBlock,
+		Address:   address,
+	})
+}
+
+// RescanJobs returns the known rescan jobs of the service, the newest first.
+func (w *Service) RescanJobs(serviceId int) (jobs []*RescanJob) {
+	w.rescan.mux.Lock()
+	defer w.rescan.mux.Unlock()
+	for i := len(w.rescan.jobs) - 1; i >= 0; i-- {
+		if w.rescan.jobs[i].ServiceId == serviceId {
+			jobs = append(jobs, w.rescan.jobs[i].copy())
+		}
+	}
+	return jobs
+}
+
+// RescanJob returns the rescan job of the service by id.
+func (w *Service) RescanJob(serviceId int, jobId int64) (job *RescanJob, err error) {
+	w.rescan.mux.Lock()
+	defer w.rescan.mux.Unlock()
+	for _, job := range w.rescan.jobs {
+		if job.Id == jobId && job.ServiceId == serviceId {
+			return job.copy(), nil
+		}
+	}
+	return nil, ErrRescanJobNotFound
+}
+
+// RescanCancel requests cancellation of a pending or running rescan job of the service.
+func (w *Service) RescanCancel(serviceId int, jobId int64) (err error) {
+	w.rescan.mux.Lock()
+	defer w.rescan.mux.Unlock()
+	for _, job := range w.rescan.jobs {
+		if job.Id == jobId && job.ServiceId == serviceId {
+			job.cancel.Store(true)
+			return nil
+		}
+	}
+	return ErrRescanJobNotFound
+}
+
+// rescanStart validates the job range, registers the job and queues it.
+func (w *Service) rescanStart(job *RescanJob) (*RescanJob, error) {
+	if w.rescan.queue == nil || w.isStopping() {
+		return nil, ErrServiceNotRunning
+	}
+	w.mux.RLock()
+	lastProcessed := w.state.GetState()
+	w.mux.RUnlock()
+	if job.ToBlock == 0 || job.ToBlock > lastProcessed {
+		job.ToBlock = lastProcessed
+	}
+	if job.FromBlock < 0 || job.FromBlock > job.ToBlock {
+		return nil, ErrInvalidBlockRange
+	}
+	w.rescan.mux.Lock()
+	w.rescan.lastId++
+	job.Id = w.rescan.lastId
+	job.Status = RescanStatusPending
+	job.CurrentBlock = job.FromBlock
+	job.CreatedAt = time.Now().Unix()
+	w.rescan.jobs = append(w.rescan.jobs, job)
+	w.rescanCleanup()
+	jobCopy := job.copy()
+	w.rescan.mux.Unlock()
+	select {
+	case w.rescan.queue <- job:
+	default:
+		w.rescanFinish(job, ErrRescanQueueFull)
+		return nil, ErrRescanQueueFull
+	}
+	log.Info("Rescan job", job.Id, "queued:", job.Kind, job.FromBlock, "-", job.ToBlock)
+	return jobCopy, nil
+}
+
+// rescanCleanup drops the oldest finished jobs above the limit. Must be called under rescan lock.
+func (w *Service) rescanCleanup() {
+	for i := 0; len(w.rescan.jobs) > maxRescanJobs && i < len(w.rescan.jobs); {
+		switch w.rescan.jobs[i].Status {
+		case RescanStatusDone, RescanStatusFailed, RescanStatusCancelled:
+			w.rescan.jobs = append(w.rescan.jobs[:i], w.rescan.jobs[i+1:]...)
+		default:
+			i++
+		}
+	}
+}
+
+// rescanWorker runs queued rescan jobs one by one until the service is stopped.
+// Jobs take the service lock only to emit the transfers of a fetched block, so the
+// run loop keeps processing new blocks between them.
+func (w *Service) rescanWorker() {
+	defer w.producers.Done()
+	for {
+		select {
+		case job := <-w.rescan.queue:
+			w.rescanRun(job)
+		case <-w.quit:
+			return
+		}
+	}
+}
+
+// rescanRun processes the job blocks and emits the matching transactions.
+func (w *Service) rescanRun(job *RescanJob) {
+	w.rescan.mux.Lock()
+	job.Status = RescanStatusRunning
+	w.rescan.mux.Unlock()
+	for blockNum := job.FromBlock; blockNum <= job.ToBlock; blockNum++ {
+		if job.cancel.Load() || w.isStopping() {
+			job.cancel.Store(true)
+			w.rescanFinish(job, nil)
+			return
+		}
+		block, err := w.client.BlockByNum(blockNum, true)
+		if err != nil {
+			log.Error("Rescan job", job.Id, "can not get block:", blockNum, err)
+			w.rescanFinish(job, err)
+			return
+		}
+		found := w.rescanBlock(job, blockNum, block)
+		w.rescan.mux.Lock()
+		job.CurrentBlock = blockNum
+		job.Found += found
+		job.Progress = int((blockNum - job.FromBlock + 1) * 100 / (job.ToBlock - job.FromBlock + 1))
+		w.rescan.mux.Unlock()
+	}
+	w.rescanFinish(job, nil)
+}
+
+// rescanBlock emits the block transfers selected by the job and returns their number.
+// Transfers are emitted under the service lock, so they are serialized with the run
+// loop, and a block orphaned by a reorg since it was fetched is skipped.
+func (w *Service) rescanBlock(job *RescanJob, blockNum int64, block *types.BlockInfo) (found int) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	if blockId, known := w.state.GetBlockId(blockNum); known && !strings.EqualFold(blockId, block.BlockID) {
+		log.Warning("Rescan job", job.Id, "skips orphaned block:", blockNum, block.BlockID)
+		return 0
+	}
+	for _, tx := range block.Transactions {
+		if !job.match(tx) || !(w.ownedBy(tx.From, job.ServiceId) || w.ownedBy(tx.To, job.ServiceId)) {
+			continue
+		}
+		if err := w.processTx(tx); err != nil {
+			log.Error("process tx error", err)
+		}
+		found++
+	}
+	return found
+}
+
+// rescanFinish sets the final job status.
+func (w *Service) rescanFinish(job *RescanJob, err error) {
+	w.rescan.mux.Lock()
+	defer w.rescan.mux.Unlock()
+	job.FinishedAt = time.Now().Unix()
+	if err != nil {
+		job.Status = RescanStatusFailed
+		job.Error = err.Error()
+	} else if job.cancel.Load() {
+		job.Status = RescanStatusCancelled
+	} else {
+		job.Status = RescanStatusDone
+	}
+	log.Info("Rescan job", job.Id, job.Status, "found:", job.Found)
+}
+
+// ownedBy reports whether the address is managed and belongs to the service.
+func (w *Service) ownedBy(address string, serviceId int) bool {
+	addressInfo, err := w.addressPool.GetAddress(address)
+	return err == nil && addressInfo.ServiceId == serviceId
+}
+
+// match reports whether the transfer is selected by the job.
+// Token transfers detected from logs have the transaction hash as ID prefix.
+func (j *RescanJob) match(tx *types.TransferInfo) bool {
+	switch j.Kind {
+	case RescanKindTransaction:
+		return strings.EqualFold(tx.TxID, j.TxId) || strings.HasPrefix(strings.ToLower(tx.TxID), strings.ToLower(j.TxId)+":")
+	case RescanKindAddress:
+		return strings.EqualFold(tx.From, j.Address) || strings.EqualFold(tx.To, j.Address)
+	}
+	return true
+}
+
+// copy returns a snapshot of the job. Must be called under rescan lock.
+func (j *RescanJob) copy() *RescanJob {
+	return &RescanJob{
+		Id:           j.Id,
+		ServiceId:    j.ServiceId,
+		Kind:         j.Kind,
+		FromBlock:    j.FromBlock,
+		ToBlock:      j.ToBlock,
+		TxId:         j.TxId,
+		Address:      j.Address,
+		Status:       j.Status,
+		CurrentBlock: j.CurrentBlock,
+		Progress:     j.Progress,
+		Found:        j.Found,
+		Error:        j.Error,
+		CreatedAt:    j.CreatedAt,
+		FinishedAt:   j.FinishedAt,
+	}
+}
@@ -0,0 +1,167 @@
+package watchdog
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// newTestRescanService returns a service with the blocks processed up to lastProcessed
+// and the rescan queue set up, the rescan worker is not started.
+func newTestRescanService(t *testing.T, chain *testChain, lastProcessed int64, owners map[byte]int) *Service {
+	t.Helper()
+	w := newTestService(t, chain, 64, owners)
+	w.state.LastBlockNum = lastProcessed
+	w.rescan.queue = make(chan *RescanJob, rescanQueueSize)
+	return w
+}
+
+func TestRescanBlocks_Range(t *testing.T) {
+	tests := []struct {
+		name      string
+		fromBlock int64
+		toBlock   int64
+		wantFrom  int64
+		wantTo    int64
+		wantErr   error
+	}{
+		{"default end", 3, 0, 3, 10, nil},
+		{"end above last processed", 3, 20, 3, 10, nil},
+		{"inside processed", 3, 5, 3, 5, nil},
+		{"single block", 10, 10, 10, 10, nil},
+		{"start above last processed", 11, 0, 0, 0, ErrInvalidBlockRange},
+		{"start above end", 5, 3, 0, 0, ErrInvalidBlockRange},
+		{"negative start", -1, 5, 0, 0, ErrInvalidBlockRange},
+	}
+	w := newTestRescanService(t, newTestChain(), 10, nil)
+	for _, tt := range tests {
+		job, err := w.RescanBlocks(1, tt.fromBlock, tt.toBlock)
+		if !errors.Is(err, tt.wantErr) {
+			t.Fatalf("%s: got %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		if job.FromBlock != tt.wantFrom || job.ToBlock != tt.wantTo || job.Status != RescanStatusPending {
+			t.Errorf("%s: got %d-%d %s, want %d-%d pending", tt.name, job.FromBlock, job.ToBlock, job.Status, tt.wantFrom, tt.wantTo)
+		}
+		<-w.rescan.queue
+	}
+}
+
+func TestRescanBlocks_QueueFull(t *testing.T) {
+	w := newTestRescanService(t, newTestChain(), 10, nil)
+	for i := 0; i < rescanQueueSize; i++ {
+		if _, err := w.RescanBlocks(1, 1, 0); err != nil {
+			t.Fatalf("job %d: %v", i, err)
+		}
+	}
+	if _, err := w.RescanBlocks(1, 1, 0); !errors.Is(err, ErrRescanQueueFull) {
+		t.Fatalf("got %v, want ErrRescanQueueFull", err)
+	}
+	if jobs := w.RescanJobs(1); len(jobs) != rescanQueueSize+1 || jobs[0].Status != RescanStatusFailed {
+		t.Fatalf("jobs: got %d, newest %s, want %d, newest failed", len(jobs), jobs[0].Status, rescanQueueSize+1)
+	}
+}
+
+func TestRescanCancel(t *testing.T) {
+	chain := newTestChain()
+	chain.fork(1, 3, "a")
+	w := newTestRescanService(t, chain, 3, nil)
+	job, err := w.RescanBlocks(1, 1, 0)
+	if err != nil {
+		t.Fatalf("RescanBlocks: %v", err)
+	}
+	if err = w.RescanCancel(2, job.Id); !errors.Is(err, ErrRescanJobNotFound) {
+		t.Fatalf("cancel by another service: got %v, want ErrRescanJobNotFound", err)
+	}
+	if err = w.RescanCancel(1, job.Id); err != nil {
+		t.Fatalf("RescanCancel: %v", err)
+	}
+	w.rescanRun(<-w.rescan.queue)
+	job, err = w.RescanJob(1, job.Id)
+	if err != nil {
+		t.Fatalf("RescanJob: %v", err)
+	}
+	if job.Status != RescanStatusCancelled || job.FinishedAt == 0 {
+		t.Fatalf("status: got %s, finished at %d, want cancelled", job.Status, job.FinishedAt)
+	}
+	if chain.calls(1) != 0 {
+		t.Fatalf("cancelled job fetched %d blocks", chain.calls(1))
+	}
+	if _, err = w.RescanJob(2, job.Id); !errors.Is(err, ErrRescanJobNotFound) {
+		t.Fatalf("job of another service: got %v, want ErrRescanJobNotFound", err)
+	}
+}
+
+func TestRescanRun_ServiceTransfers(t *testing.T) {
+	chain := newTestChain()
+	chain.fork(1, 3, "a")
+	chain.addTransfer(1, "0x1", testAddress(9), testAddress(1))
+	chain.addTransfer(2, "0x2", testAddress(9), testAddress(2))
+	chain.addTransfer(2, "0x3", testAddress(3), testAddress(9))
+	chain.addTransfer(3, "0x4", testAddress(9), testAddress(8))
+	// address 1 and 3 belong to service 1, address 2 to service 2
+	owners := map[byte]int{1: 1, 2: 2, 3: 1}
+	tests := []struct {
+		name  string
+		start func(w *Service) (*RescanJob, error)
+		want  []string
+	}{
+		{"blocks", func(w *Service) (*RescanJob, error) {
+			return w.RescanBlocks(1, 1, 0)
+		}, []string{"tx 1 0x1", "tx 2 0x3"}},
+		{"transaction of the service", func(w *Service) (*RescanJob, error) {
+			return w.rescanStart(&RescanJob{ServiceId: 1, Kind: RescanKindTransaction, FromBlock: 2, ToBlock: 2, TxId: "0x3"})
+		}, []string{"tx 2 0x3"}},
+		{"transaction of another service", func(w *Service) (*RescanJob, error) {
+			return w.rescanStart(&RescanJob{ServiceId: 1, Kind: RescanKindTransaction, FromBlock: 2, ToBlock: 2, TxId: "0x2"})
+		}, nil},
+		{"address", func(w *Service) (*RescanJob, error) {
+			return w.RescanAddress(1, testAddress(3), 1)
+		}, []string{"tx 2 0x3"}},
+	}
+	for _, tt := range tests {
+		w := newTestRescanService(t, chain, 3, owners)
+		for blockNum := int64(1); blockNum <= 3; blockNum++ {
+			w.state.PushBlock(blockNum, testBlockId(blockNum, "a"), 64)
+		}
+		job, err := tt.start(w)
+		if err != nil {
+			t.Fatalf("%s: start: %v", tt.name, err)
+		}
+		w.rescanRun(<-w.rescan.queue)
+		if got := queuedEvents(w); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: events: got %v, want %v", tt.name, got, tt.want)
+		}
+		job, _ = w.RescanJob(1, job.Id)
+		if job.Status != RescanStatusDone || job.Progress != 100 || job.Found != len(tt.want) {
+			t.Errorf("%s: job: got %s, progress %d, found %d", tt.name, job.Status, job.Progress, job.Found)
+		}
+	}
+}
+
+func TestRescanAddress_NotOwned(t *testing.T) {
+	w := newTestRescanService(t, newTestChain(), 3, map[byte]int{1: 1, 2: 2})
+	for _, addressString := range []string{testAddress(2), testAddress(9)} {
+		if _, err := w.RescanAddress(1, addressString, 1); !errors.Is(err, ErrAddressNotKnown) {
+			t.Fatalf("%s: got %v, want ErrAddressNotKnown", addressString, err)
+		}
+	}
+}
+
+func TestRescanRun_SkipsOrphanedBlock(t *testing.T) {
+	chain := newTestChain()
+	chain.fork(1, 2, "a")
+	chain.addTransfer(2, "0x2", testAddress(9), testAddress(1))
+	w := newTestRescanService(t, chain, 2, map[byte]int{1: 1})
+	w.state.PushBlock(1, testBlockId(1, "a"), 64)
+	w.state.PushBlock(2, testBlockId(2, "b"), 64)
+	if _, err := w.RescanBlocks(1, 1, 0); err != nil {
+		t.Fatalf("RescanBlocks: %v", err)
+	}
+	w.rescanRun(<-w.rescan.queue)
+	if got := queuedEvents(w); len(got) != 0 {
+		t.Fatalf("events of orphaned block: %v", got)
+	}
+}
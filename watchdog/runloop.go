@@ -1,14 +1,18 @@
 package watchdog
 
 import (
+	"errors"
 	"github.com/ITProLabDev/ethbacknode/tools/log"
 	"time"
 )
 
 // runLoop is the main monitoring loop that polls the blockchain.
 // Checks mempool content and processes new blocks at configured intervals.
-// Handles block catch-up when multiple blocks have been missed.
+// Handles block catch-up when multiple blocks have been missed and
+// rolls back to the common ancestor when a chain reorganization is detected.
+// Exits after the current block when the service is stopped.
 func (w *Service) runLoop() {
+	defer w.producers.Done()
 	blockchain := w.client.GetChainName()
 	lastSeenBlock := w.state.LastBlockNum
 	if lastSeenBlock == 0 {
@@ -21,10 +25,7 @@ func (w *Service) runLoop() {
 		memPoolContent, err := w.client.MemPoolContent()
 		if err != nil {
 			log.Error("Can not get mempool content:", err)
-			time.Sleep(time.Duration(w.checkInterval) * time.Second)
-			continue
-		}
-		if len(memPoolContent) != 0 {
+		} else if len(memPoolContent) != 0 {
 			if w.config.Debug {
 				log.Debug("MemPool contain", len(memPoolContent), "transactions, process...")
 			}
@@ -37,42 +38,60 @@ func (w *Service) runLoop() {
 		currentBlock, err := w.client.BlockNum()
 		if err != nil {
 			log.Error("Can not get current block:", err)
-			time.Sleep(time.Duration(w.checkInterval) * time.Second)
 			w.mux.Unlock()
+			if !w.sleep() {
+				return
+			}
 			continue
 		}
 		if currentBlock > lastSeenBlock {
 			log.Info("Current", blockchain, "block:", currentBlock)
 			if currentBlock-lastSeenBlock > 1 {
 				log.Warning("Blocks ahead:", currentBlock-lastSeenBlock, "overtake or missed blocks")
-				for processBlock := lastSeenBlock + 1; processBlock <= currentBlock; processBlock++ {
-					if w.config.Debug {
-						log.Debug("Process block:", processBlock)
-					}
-					err = w.processBlock(processBlock)
+			}
+			for processBlock := lastSeenBlock + 1; processBlock <= currentBlock; processBlock++ {
+				if w.isStopping() {
+					break
+				}
+				err = w.processBlock(processBlock)
+				if errors.Is(err, ErrChainReorg) {
+					log.Warning("Chain reorganization detected at block:", processBlock)
+					err = w.rollback(processBlock - 1)
 					if err != nil {
-						log.Error("Can not process block:", processBlock, err)
-						w.mux.Unlock()
-						continue
+						log.Error("Can not rollback reorganized blocks:", err)
 					}
-					w.state.UpdateState(processBlock)
+					lastSeenBlock = w.state.GetState()
+					break
+				} else if err != nil {
+					log.Error("Can not process block:", processBlock, err)
+					break
 				}
-			} else {
-				err = w.processBlock(currentBlock)
+				lastSeenBlock = processBlock
+				err = w.state.UpdateState(processBlock)
 				if err != nil {
-					log.Error("Can not process block:", currentBlock, err)
-					w.mux.Unlock()
-					continue
+					log.Error("Can not save watchdog state:", err)
 				}
 			}
-			lastSeenBlock = currentBlock
 		} else {
 			if w.config.Debug {
 				log.Debug("No new blocks, skip...")
 			}
 		}
-		_ = w.state.UpdateState(currentBlock)
 		w.mux.Unlock()
-		time.Sleep(time.Duration(w.checkInterval) * time.Second)
+		if !w.sleep() {
+			return
+		}
+	}
+}
+
+// sleep waits for the check interval. Returns false if the service is stopped.
+func (w *Service) sleep() bool {
+	timer := time.NewTimer(time.Duration(w.checkInterval) * time.Second)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-w.quit:
+		return false
 	}
 }
@@ -7,13 +7,15 @@ import (
 )
 
 // lastState tracks the watchdog's progress through the blockchain.
-// Persists the last processed block number to resume after restarts.
+// Persists the last processed block number to resume after restarts,
+// along with the hashes of recently processed blocks used for reorg detection.
 type lastState struct {
 	storage       storage.BinStorage
 	setToBlock    bool
 	setToBlockNum int64
-	LastCheckTime time.Time `json:"lastCheckTime"`
-	LastBlockNum  int64     `json:"lastBlockNum"`
+	LastCheckTime time.Time    `json:"lastCheckTime"`
+	LastBlockNum  int64        `json:"lastBlockNum"`
+	RecentBlocks  []*blockHash `json:"recentBlocks,omitempty"`
 }
 
 // Load reads the state from storage.
@@ -60,3 +62,47 @@ func (c *lastState) UpdateState(currentBlockNum int64) error {
 func (c *lastState) GetState() (currentBlockNum int64) {
 	return c.LastBlockNum
 }
+
+// blockHash is a processed block number paired with its hash.
+type blockHash struct {
+	BlockNum int64  `json:"blockNum"`
+	BlockId  string `json:"blockId"`
+}
+
+// PushBlock records the hash of a processed block, replacing any hashes
+// at the same or higher numbers and keeping at most window entries.
+func (c *lastState) PushBlock(blockNum int64, blockId string, window int) {
+	c.truncate(blockNum - 1)
+	c.RecentBlocks = append(c.RecentBlocks, &blockHash{
+		BlockNum: blockNum,
+		BlockId:  blockId,
+	})
+	if window > 0 && len(c.RecentBlocks) > window {
+		c.RecentBlocks = c.RecentBlocks[len(c.RecentBlocks)-window:]
+	}
+}
+
+// GetBlockId returns the recorded hash of a processed block.
+// Returns false if the block is outside the recorded window.
+func (c *lastState) GetBlockId(blockNum int64) (blockId string, found bool) {
+	for i := len(c.RecentBlocks) - 1; i >= 0; i-- {
+		if c.RecentBlocks[i].BlockNum == blockNum {
+			return c.RecentBlocks[i].BlockId, true
+		}
+	}
+	return "", false
+}
+
+// RewindTo moves the state back to the given block number,
+// forgetting hashes of all blocks above it, and persists the result.
+func (c *lastState) RewindTo(blockNum int64) error {
+	c.truncate(blockNum)
+	return c.UpdateState(blockNum)
+}
+
+// truncate drops recorded hashes of blocks above the given number.
+func (c *lastState) truncate(blockNum int64) {
+	for len(c.RecentBlocks) != 0 && c.RecentBlocks[len(c.RecentBlocks)-1].BlockNum > blockNum {
+		c.RecentBlocks = c.RecentBlocks[:len(c.RecentBlocks)-1]
+	}
+}
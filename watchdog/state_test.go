@@ -0,0 +1,72 @@
+package watchdog
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLastState_PushBlock(t *testing.T) {
+	tests := []struct {
+		name   string
+		pushes []int64
+		window int
+		want   string
+	}{
+		{"sequence", []int64{1, 2, 3}, 0, "[1 2 3]"},
+		{"window keeps the newest", []int64{1, 2, 3, 4, 5}, 3, "[3 4 5]"},
+		{"same number replaces", []int64{1, 2, 3, 3}, 0, "[1 2 3]"},
+		{"lower number drops higher", []int64{1, 2, 3, 4, 2}, 0, "[1 2]"},
+	}
+	for _, tt := range tests {
+		state := new(lastState)
+		for i, blockNum := range tt.pushes {
+			state.PushBlock(blockNum, testBlockId(blockNum, fmt.Sprint(i)), tt.window)
+		}
+		if got := recentBlockNums(state); got != tt.want {
+			t.Errorf("%s: recent blocks: got %s, want %s", tt.name, got, tt.want)
+		}
+		last := tt.pushes[len(tt.pushes)-1]
+		if blockId, found := state.GetBlockId(last); !found || blockId != testBlockId(last, fmt.Sprint(len(tt.pushes)-1)) {
+			t.Errorf("%s: block %d id: got %q, %v", tt.name, last, blockId, found)
+		}
+	}
+}
+
+func TestLastState_RewindTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		rewindTo int64
+		want     string
+	}{
+		{"inside window", 3, "[2 3]"},
+		{"below window", 1, "[]"},
+		{"above last block", 7, "[2 3 4 5]"},
+	}
+	for _, tt := range tests {
+		state := new(lastState)
+		for blockNum := int64(1); blockNum <= 5; blockNum++ {
+			state.PushBlock(blockNum, testBlockId(blockNum, "a"), 4)
+		}
+		if err := state.RewindTo(tt.rewindTo); err != nil {
+			t.Fatalf("%s: RewindTo: %v", tt.name, err)
+		}
+		if got := recentBlockNums(state); got != tt.want {
+			t.Errorf("%s: recent blocks: got %s, want %s", tt.name, got, tt.want)
+		}
+		if state.GetState() != tt.rewindTo {
+			t.Errorf("%s: state: got %d, want %d", tt.name, state.GetState(), tt.rewindTo)
+		}
+		if _, found := state.GetBlockId(tt.rewindTo + 1); found {
+			t.Errorf("%s: block %d still recorded", tt.name, tt.rewindTo+1)
+		}
+	}
+}
+
+// recentBlockNums returns the numbers of the recorded blocks.
+func recentBlockNums(state *lastState) string {
+	blockNums := make([]int64, 0, len(state.RecentBlocks))
+	for _, block := range state.RecentBlocks {
+		blockNums = append(blockNums, block.BlockNum)
+	}
+	return fmt.Sprint(blockNums)
+}
@@ -7,6 +7,7 @@
 package watchdog
 
 import (
+	"context"
 	"sync"
 
 	"github.com/ITProLabDev/ethbacknode/address"
@@ -63,6 +64,7 @@ func NewService(options ...ServiceOption) *Service {
 		state:         new(lastState),
 		events:        make(chan *event, 250),
 		maxRetryCount: 3,
+		quit:          make(chan struct{}),
 	}
 	for _, option := range options {
 		option(service)
@@ -84,12 +86,17 @@ type Service struct {
 	addressPool             *address.Manager
 	client                  types.ChainClient
 	blockEventHandlers      []BlockEvent
+	blockRevertedHandlers   []BlockRevertedEvent
 	transactionHandlers     []TransactionEvent
 	//jsVm                    *goja.Runtime
 	events           chan *event
 	pullEventChannel chan *PullEvent
+	rescan           rescanJobs
 	maxRetryCount    int
 	quit             chan struct{}
+	stopOnce         sync.Once
+	producers        sync.WaitGroup // run loop, pull event watcher and rescan worker
+	handlers         sync.WaitGroup // event loop
 }
 
 // Run starts the watchdog service.
@@ -112,6 +119,9 @@ func (w *Service) Run() (err error) {
 	w.run = w.config.Run
 	w.checkInterval = w.config.PullInterval
 	w.externalEvent = w.config.PullByExternalEvent
+	if w.config.ReorgWindow <= 0 {
+		w.config.ReorgWindow = defaultReorgWindow
+	}
 	if !w.state.setToBlock {
 		err = w.state.Load()
 		if err != nil {
@@ -123,7 +133,53 @@ func (w *Service) Run() (err error) {
 			return err
 		}
 	}
+	w.pullEventChannel = make(chan *PullEvent)
+	w.rescan.queue = make(chan *RescanJob, rescanQueueSize)
+	w.producers.Add(3)
 	go w.runLoop()
+	go w.pullEventWatcher()
+	go w.rescanWorker()
+	w.handlers.Add(1)
 	go w.eventLoop()
 	return nil
 }
+
+// Stop stops the service. The block being processed is finished, queued events
+// are delivered to the handlers and the state is saved. Returns ctx.Err() if
+// the service does not stop before the context is done.
+func (w *Service) Stop(ctx context.Context) (err error) {
+	w.stopOnce.Do(func() {
+		close(w.quit)
+	})
+	done := make(chan struct{})
+	go func() {
+		w.producers.Wait()
+		if w.pullEventChannel != nil {
+			// all producers are stopped, the event loop exits when the queue is drained
+			close(w.events)
+		}
+		w.handlers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	if w.pullEventChannel == nil {
+		return nil
+	}
+	return w.state.Save()
+}
+
+// isStopping reports whether Stop was called.
+func (w *Service) isStopping() bool {
+	select {
+	case <-w.quit:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,285 @@
+package watchdog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ITProLabDev/ethbacknode/address"
+	"github.com/ITProLabDev/ethbacknode/clients/ethclient"
+	"github.com/ITProLabDev/ethbacknode/storage"
+	"github.com/ITProLabDev/ethbacknode/types"
+)
+
+// addressConfig disables the address generation of the test address pool.
+const addressConfig = `{"enableAddressGenerate": false, "bip44CoinType": "Ether"}`
+
+// testChain is a chain client serving the blocks set by the test.
+// Methods not overridden panic on the nil embedded interface.
+type testChain struct {
+	types.ChainClient
+	mux        sync.Mutex
+	blocks     map[int64]*types.BlockInfo
+	blockCalls map[int64]int
+}
+
+func newTestChain() *testChain {
+	return &testChain{
+		blocks:     make(map[int64]*types.BlockInfo),
+		blockCalls: make(map[int64]int),
+	}
+}
+
+func (c *testChain) GetChainName() string {
+	return "ethereum"
+}
+
+func (c *testChain) MemPoolContent() ([]*types.TransferInfo, error) {
+	return nil, nil
+}
+
+func (c *testChain) BlockNum() (int64, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return int64(len(c.blocks)), nil
+}
+
+func (c *testChain) BlockByNum(blockNum int64, fullInfo bool) (*types.BlockInfo, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.blockCalls[blockNum]++
+	block, found := c.blocks[blockNum]
+	if !found {
+		return nil, fmt.Errorf("block %d not found", blockNum)
+	}
+	return block, nil
+}
+
+// calls returns the number of requests of the block.
+func (c *testChain) calls(blockNum int64) int {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.blockCalls[blockNum]
+}
+
+// fork replaces the blocks from the given number with a branch of count blocks
+// named by the branch. The transfers of the replaced blocks are dropped.
+func (c *testChain) fork(fromBlock int64, count int, branch string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	for blockNum := range c.blocks {
+		if blockNum >= fromBlock {
+			delete(c.blocks, blockNum)
+		}
+	}
+	for blockNum := fromBlock; blockNum < fromBlock+int64(count); blockNum++ {
+		c.blocks[blockNum] = &types.BlockInfo{
+			BlockID:    testBlockId(blockNum, branch),
+			Number:     int(blockNum),
+			ParentHash: c.parentHash(blockNum),
+		}
+	}
+}
+
+// parentHash returns the hash of the previous block. Must be called under lock.
+func (c *testChain) parentHash(blockNum int64) string {
+	if parent, found := c.blocks[blockNum-1]; found {
+		return parent.BlockID
+	}
+	return testBlockId(blockNum-1, "a")
+}
+
+// addTransfer adds a transfer to the block.
+func (c *testChain) addTransfer(blockNum int64, txId, from, to string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	block := c.blocks[blockNum]
+	block.Transactions = append(block.Transactions, &types.TransferInfo{
+		TxID:     txId,
+		From:     from,
+		To:       to,
+		BlockNum: int(blockNum),
+	})
+}
+
+// testBlockId returns the hash of the block in the branch.
+func testBlockId(blockNum int64, branch string) string {
+	return fmt.Sprintf("0x%s%063x", branch, blockNum)
+}
+
+// testAddress returns the address string n.
+func testAddress(n byte) string {
+	addressString, _ := ethclient.GetAddressCodec().EncodeBytesToAddress(bytes.Repeat([]byte{n}, 20))
+	return addressString
+}
+
+// newTestService returns a service, not started, watching the addresses of the
+// given services (address n belongs to service owners[n]) with the reorg window.
+func newTestService(t *testing.T, chain *testChain, reorgWindow int, owners map[byte]int) *Service {
+	t.Helper()
+	dir := t.TempDir()
+	addressStorage, err := storage.NewBadgerStorage("Address", dir, "address", "addresses.db")
+	if err != nil {
+		t.Fatalf("address storage: %v", err)
+	}
+	t.Cleanup(func() { addressStorage.Close() })
+	addressConfigStorage, _ := storage.NewBinFileStorage("Config", dir, "address", "config.json")
+	if err = addressConfigStorage.Save([]byte(addressConfig)); err != nil {
+		t.Fatalf("address config: %v", err)
+	}
+	addressPool, err := address.NewManager(
+		address.WithAddressStorage(addressStorage),
+		address.WithConfigStorage(addressConfigStorage),
+		address.WithAddressCodec(ethclient.GetAddressCodec()),
+	)
+	if err != nil {
+		t.Fatalf("address.NewManager: %v", err)
+	}
+	for n, serviceId := range owners {
+		addressString := testAddress(n)
+		_, err = addressPool.AddAddressFill(addressString, func(a *address.Address) {
+			a.ServiceId = serviceId
+			a.Subscribed = true
+			a.WatchOnly = true
+		})
+		if err != nil {
+			t.Fatalf("AddAddressFill: %v", err)
+		}
+		// the lookup index is rebuilt in background
+		for deadline := time.Now().Add(5 * time.Second); !addressPool.IsAddressKnown(addressString); time.Sleep(time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("address %s not indexed", addressString)
+			}
+		}
+	}
+	config, _ := json.Marshal(&Config{Run: true, PullInterval: 1, ReorgWindow: reorgWindow})
+	configStorage, _ := storage.NewBinFileStorage("Config", dir, "watchdog", "config.json")
+	if err = configStorage.Save(config); err != nil {
+		t.Fatalf("watchdog config: %v", err)
+	}
+	stateStorage, _ := storage.NewBinFileStorage("State", dir, "watchdog", "state.json")
+	w := NewService(
+		WithClient(chain),
+		WithAddressManager(addressPool),
+		WithConfigStorage(configStorage),
+		WithStateStorage(stateStorage),
+	)
+	if err = w.config.Load(); err != nil {
+		t.Fatalf("config load: %v", err)
+	}
+	return w
+}
+
+// queuedEvents returns the events waiting in the queue of a service not started,
+// formatted as "<kind> <block> [<txId>]".
+func queuedEvents(w *Service) (events []string) {
+	for {
+		select {
+		case e := <-w.events:
+			switch {
+			case e.blockEvent:
+				events = append(events, fmt.Sprintf("block %d", e.blockNum))
+			case e.blockRevertedEvent:
+				events = append(events, fmt.Sprintf("reverted %d", e.blockNum))
+			case e.transactionEvent:
+				events = append(events, fmt.Sprintf("tx %d %s", e.transaction.BlockNum, e.transaction.TxID))
+			}
+		default:
+			return events
+		}
+	}
+}
+
+func TestStop_DrainsQueuedEvents(t *testing.T) {
+	chain := newTestChain()
+	chain.fork(1, 3, "a")
+	for blockNum := int64(1); blockNum <= 3; blockNum++ {
+		chain.addTransfer(blockNum, fmt.Sprintf("0x%d", blockNum), testAddress(9), testAddress(1))
+	}
+	w := newTestService(t, chain, 0, map[byte]int{1: 1})
+	gate := make(chan struct{})
+	var mux sync.Mutex
+	var delivered []string
+	w.RegisterBlockEventListen(func(blockNum int64, blockId string) {
+		if blockNum == 1 {
+			<-gate
+		}
+		mux.Lock()
+		delivered = append(delivered, fmt.Sprintf("block %d", blockNum))
+		mux.Unlock()
+	})
+	w.RegisterTransactionEventListen(func(tx *types.TransferInfo) {
+		mux.Lock()
+		delivered = append(delivered, "tx "+tx.TxID)
+		mux.Unlock()
+	})
+	if err := w.Run(); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		w.mux.RLock()
+		processed := w.state.GetState()
+		w.mux.RUnlock()
+		if processed == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("blocks not processed, state: %d", processed)
+		}
+	}
+
+	stopped := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		stopped <- w.Stop(ctx)
+	}()
+	select {
+	case err := <-stopped:
+		t.Fatalf("Stop returned before the queued events are delivered: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(gate)
+	if err := <-stopped; err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	want := []string{"block 1", "tx 0x1", "block 2", "tx 0x2", "block 3", "tx 0x3"}
+	mux.Lock()
+	defer mux.Unlock()
+	if fmt.Sprint(delivered) != fmt.Sprint(want) {
+		t.Fatalf("delivered: got %v, want %v", delivered, want)
+	}
+	saved := new(lastState)
+	saved.storage = w.state.storage
+	if err := saved.Load(); err != nil || saved.LastBlockNum != 3 {
+		t.Fatalf("saved state: got %d (%v), want 3", saved.LastBlockNum, err)
+	}
+}
+
+func TestStop_ContextDone(t *testing.T) {
+	chain := newTestChain()
+	chain.fork(1, 1, "a")
+	w := newTestService(t, chain, 0, nil)
+	gate := make(chan struct{})
+	defer close(gate)
+	w.RegisterBlockEventListen(func(blockNum int64, blockId string) {
+		<-gate
+	})
+	if err := w.Run(); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); len(w.events) != 0 || chain.calls(1) == 0; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("block not dispatched")
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := w.Stop(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Stop: got %v, want context.DeadlineExceeded", err)
+	}
+}